@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) configCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect configuration",
+		// config print tetap jalan walaupun config tidak valid, supaya masalahnya bisa dilihat
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			c.cfg = c.loader.Load()
+			return nil
+		},
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration and where each value came from (secrets redacted)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := c.cfg.Print(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if err := c.cfg.Validate(); err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr())
+				return err
+			}
+			return nil
+		},
+	})
+
+	return configCmd
+}
@@ -11,38 +11,70 @@ import (
 	"user-service/config"
 	db "user-service/db/sqlc"
 	"user-service/handler"
+	appmiddleware "user-service/middleware"
 	logger "user-service/pkg"
-	"user-service/service"
+	"user-service/pkg/helper"
+	"user-service/pkg/metrics"
+	"user-service/pkg/worker"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// backgroundWorkers membatasi pekerjaan background yang berjalan bersamaan; sisanya dibuang dan dicatat di log.
+const backgroundWorkers = 16
+
 type ServerOptions struct {
 	Config *config.AppConfig
 	DB     *pgxpool.Pool
 }
 
 func Run(opts ServerOptions) {
+	if err := checkMigrations(opts.Config); err != nil {
+		logger.Log.Fatalf("refusing to start: %v", err)
+	}
+
 	// store
 	store := db.NewStore(opts.DB)
 
+	// pekerjaan background (email reset password), ditunggu saat shutdown sebelum database ditutup
+	workers := worker.New(backgroundWorkers, logger.Named("worker"))
+
 	// service
-	service := service.NewServiceRegistry(store)
+	service, err := newServiceRegistry(opts.Config, store, workers.Go)
+	if err != nil {
+		logger.Log.Fatalf("refusing to start: %v", err)
+	}
 
 	// server
 	router := chi.NewRouter()
 	if router == nil {
 		logger.Log.Fatal("router cannot be nil")
 	}
-	router.Use(middleware.Logger)
+	router.Use(middleware.RequestID)
+	router.Use(middleware.RealIP)
+	router.Use(appmiddleware.Tracing)
+	router.Use(appmiddleware.RequestLogger)
+	router.Use(appmiddleware.Metrics)
 	router.Use(middleware.Recoverer)
 
-	validator := validator.New()
+	// statistik pool dilepas lagi saat shutdown supaya /metrics tidak membaca pool yang sudah ditutup
+	poolMetrics := metrics.NewPoolCollector(opts.DB)
+	metrics.Registry.MustRegister(poolMetrics)
+	router.Handle("/metrics", metrics.Handler())
+	// avatar di storage local disajikan langsung jika tidak ada PublicURL (CDN) lain
+	if opts.Config.Storage.Provider == "local" && opts.Config.Storage.PublicURL == "" {
+		handler.NewRegisterMediaRoutes(router, config.DefaultMediaPath, opts.Config.Storage.Dir)
+	}
+
+	validator := helper.NewValidator(helper.WithPhoneRegion(opts.Config.User.PhoneRegion))
 	// routes
 	handler.NewRegisterRoutes(service, router, validator)
+	handler.NewRegisterAdminRoutes(router, validator, opts.Config.Admin.Token)
+	handler.NewRegisterDocsRoutes(router)
+
+	watchLogLevelSignal()
 
 	port := opts.Config.AppPort
 	logger.Log.Infof("port: %s", port)
@@ -71,7 +103,11 @@ func Run(opts ServerOptions) {
 		if err := srv.Shutdown(ctx); err != nil {
 			logger.Log.Errorf("HTTP server Shutdown: %v", err)
 		}
+		if err := workers.Shutdown(ctx); err != nil {
+			logger.Log.Errorf("background jobs did not finish: %v", err)
+		}
 
+		metrics.Registry.Unregister(poolMetrics)
 		opts.DB.Close()
 		close(idleConnsClosed)
 	}()
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"user-service/config"
+	"user-service/db/migrations"
+
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) migrateCommand() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or inspect the database migrations embedded in this binary",
+	}
+
+	migrateCmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: c.withMigrator(func(m *migrations.Migrator, cmd *cobra.Command, _ []string) error {
+				if err := m.Up(); err != nil {
+					return err
+				}
+				return printVersion(m, cmd.OutOrStdout())
+			}),
+		},
+		&cobra.Command{
+			Use:   "down [N]",
+			Short: "Roll back the last N migrations (default 1)",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: c.withMigrator(func(m *migrations.Migrator, cmd *cobra.Command, args []string) error {
+				steps := 1
+				if len(args) > 0 {
+					var err error
+					if steps, err = strconv.Atoi(args[0]); err != nil {
+						return fmt.Errorf("invalid number of steps %q", args[0])
+					}
+				}
+				if err := m.Down(steps); err != nil {
+					return err
+				}
+				return printVersion(m, cmd.OutOrStdout())
+			}),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "List embedded migrations and whether they are applied",
+			Args:  cobra.NoArgs,
+			RunE: c.withMigrator(func(m *migrations.Migrator, cmd *cobra.Command, _ []string) error {
+				all, current, dirty, err := m.Status()
+				if err != nil {
+					return err
+				}
+				tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS")
+				for _, mig := range all {
+					status := "pending"
+					if mig.Applied {
+						status = "applied"
+					}
+					if dirty && mig.Version == current {
+						status = "dirty"
+					}
+					fmt.Fprintf(tw, "%06d\t%s\t%s\n", mig.Version, mig.Identifier, status)
+				}
+				return tw.Flush()
+			}),
+		},
+		&cobra.Command{
+			Use:   "version",
+			Short: "Print the current schema version",
+			Args:  cobra.NoArgs,
+			RunE: c.withMigrator(func(m *migrations.Migrator, cmd *cobra.Command, _ []string) error {
+				return printVersion(m, cmd.OutOrStdout())
+			}),
+		},
+		&cobra.Command{
+			Use:   "force V",
+			Short: "Set the schema version without running migrations (to recover from a dirty state)",
+			Args:  cobra.ExactArgs(1),
+			RunE: c.withMigrator(func(m *migrations.Migrator, cmd *cobra.Command, args []string) error {
+				version, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				if err := m.Force(version); err != nil {
+					return err
+				}
+				return printVersion(m, cmd.OutOrStdout())
+			}),
+		},
+	)
+
+	return migrateCmd
+}
+
+func (c *cli) withMigrator(fn func(*migrations.Migrator, *cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		m, err := migrations.New(c.cfg)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+		return fn(m, cmd, args)
+	}
+}
+
+func printVersion(m *migrations.Migrator, out io.Writer) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	latest, err := migrations.Latest()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "version: %d (latest: %d, dirty: %t)\n", version, latest, dirty)
+	return nil
+}
+
+// checkMigrations menolak start server jika schema tertinggal, sesuai DB_CHECK_MIGRATIONS.
+func checkMigrations(cfg *config.AppConfig) error {
+	if !cfg.DB.CheckMigrations {
+		return nil
+	}
+	m, err := migrations.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return m.CheckCurrent()
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"user-service/config"
+	db "user-service/db/sqlc"
+	logger "user-service/pkg"
+	"user-service/pkg/fetch"
+	"user-service/pkg/token"
+	"user-service/service"
+
+	"github.com/spf13/cobra"
+)
+
+// cli menyimpan state yang dipakai bersama oleh semua subcommand.
+type cli struct {
+	loader *config.Loader
+	cfg    *config.AppConfig
+}
+
+// Execute menjalankan CLI. Tanpa subcommand, binary menjalankan server (sama dengan `serve`).
+func Execute() {
+	if err := NewRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func NewRootCommand() *cobra.Command {
+	c := &cli{loader: config.NewLoader()}
+
+	root := &cobra.Command{
+		Use:               "user-service",
+		Short:             "User service HTTP server and admin tools",
+		SilenceUsage:      true,
+		PersistentPreRunE: c.loadConfig,
+		RunE:              c.runServe,
+	}
+	root.PersistentFlags().AddGoFlagSet(c.loader.FlagSet())
+
+	root.AddCommand(
+		c.serveCommand(),
+		c.migrateCommand(),
+		c.configCommand(),
+		c.userCommand(),
+		c.tokenCommand(),
+		c.seedCommand(),
+	)
+	return root
+}
+
+// loadConfig memuat dan memvalidasi config sebelum subcommand berjalan.
+func (c *cli) loadConfig(cmd *cobra.Command, _ []string) error {
+	c.cfg = c.loader.Load()
+	if err := c.cfg.Validate(); err != nil {
+		return err
+	}
+	logger.Init(c.cfg)
+	// output CLI (JSON, tabel) ada di stdout, log dipindah ke stderr
+	if cmd.Name() != "serve" && cmd.Parent() != nil {
+		logger.SetOutput(os.Stderr)
+	}
+	return nil
+}
+
+// openServices membuka koneksi database dan mengembalikan ServiceRegistry untuk subcommand admin.
+func (c *cli) openServices(ctx context.Context) (service.ServiceRegistry, func(), error) {
+	pool, err := db.Connect(ctx, c.cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	registry, err := newServiceRegistry(c.cfg, db.NewStore(pool), nil)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return registry, pool.Close, nil
+}
+
+// newServiceRegistry menyusun ServiceRegistry dari config, dipakai server dan subcommand admin.
+// background menjalankan pekerjaan di luar request (lihat service.AuthOptions), nil untuk subcommand.
+func newServiceRegistry(cfg *config.AppConfig, store db.Store, background func(fn func())) (service.ServiceRegistry, error) {
+	blobs, err := cfg.BlobStore()
+	if err != nil {
+		return nil, err
+	}
+	avatarOpts := service.AvatarOptions{
+		Sizes:    cfg.Avatar.Sizes,
+		MaxBytes: int64(cfg.Avatar.MaxBytes),
+	}
+	if cfg.Avatar.ImportURLs {
+		avatarOpts.Fetcher = fetch.New(fetch.Options{
+			Timeout:  cfg.Avatar.FetchTimeout,
+			MaxBytes: int64(cfg.Avatar.MaxBytes),
+		})
+	}
+	mailer, err := cfg.Mailer(logger.Named("mail"))
+	if err != nil {
+		return nil, err
+	}
+	verifyOpts := service.VerificationOptions{
+		Policy:   cfg.Verification.Policy,
+		URL:      cfg.Verification.URL,
+		TokenTTL: cfg.Verification.TokenTTL,
+		Mailer:   mailer,
+	}
+	changeOpts := service.EmailChangeOptions{
+		ConfirmURL:         cfg.EmailChange.ConfirmURL,
+		RevertURL:          cfg.EmailChange.RevertURL,
+		TokenTTL:           cfg.EmailChange.TokenTTL,
+		RevertTTL:          cfg.EmailChange.RevertTTL,
+		Mailer:             mailer,
+		EmailProviderRules: cfg.User.EmailProviderRules,
+	}
+	userOpts := service.UserOptions{
+		EmailProviderRules: cfg.User.EmailProviderRules,
+		PhoneRegion:        cfg.User.PhoneRegion,
+	}
+	sender, err := cfg.SMSSender(logger.Named("sms"))
+	if err != nil {
+		return nil, err
+	}
+	phoneOpts := service.PhoneVerificationOptions{
+		Sender:         sender,
+		Region:         cfg.User.PhoneRegion,
+		TTL:            cfg.PhoneOTP.TTL,
+		MaxAttempts:    cfg.PhoneOTP.MaxAttempts,
+		ResendInterval: cfg.PhoneOTP.ResendInterval,
+		MaxSends:       cfg.PhoneOTP.MaxSends,
+		SendWindow:     cfg.PhoneOTP.SendWindow,
+	}
+	// tanpa public key semua access token ditolak, jadi route yang butuh access token tidak bisa dipakai
+	verifier, err := token.NewVerifierFromFile(cfg.JWT.PublicKeyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.Log.Warnf("JWT public key %s not found, every access token will be rejected", cfg.JWT.PublicKeyPath)
+	} else if err != nil {
+		return nil, err
+	}
+	authOpts := service.AuthOptions{
+		ResetURL:           cfg.Password.ResetURL,
+		ResetTokenTTL:      cfg.Password.ResetTokenTTL,
+		HashCost:           cfg.Password.HashCost,
+		Mailer:             mailer,
+		EmailProviderRules: cfg.User.EmailProviderRules,
+		Background:         background,
+		Verifier:           verifier,
+	}
+	return service.NewServiceRegistry(store, blobs, userOpts, avatarOpts, verifyOpts, changeOpts, phoneOpts, authOpts), nil
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+
+	db "user-service/db/sqlc"
+	logger "user-service/pkg"
+	"user-service/pkg/tracing"
+
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) serveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the HTTP server",
+		Args:  cobra.NoArgs,
+		RunE:  c.runServe,
+	}
+}
+
+func (c *cli) runServe(cmd *cobra.Command, _ []string) error {
+	// ctx dibatalkan setelah server berhenti, menghentikan refresh password database di background
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shutdownTracing, err := tracing.Init(ctx, c.cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Log.Errorf("failed to shutdown tracing: %v", err)
+		}
+	}()
+
+	pool := db.PostgresDB(ctx, c.cfg)
+
+	Run(ServerOptions{Config: c.cfg, DB: pool})
+	return nil
+}
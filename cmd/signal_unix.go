@@ -0,0 +1,23 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	logger "user-service/pkg"
+)
+
+// watchLogLevelSignal: `kill -USR1 <pid>` mengganti level log antara debug dan LOG_LEVEL.
+func watchLogLevelSignal() {
+	sigusr1 := make(chan os.Signal, 1)
+	signal.Notify(sigusr1, syscall.SIGUSR1)
+	go func() {
+		for range sigusr1 {
+			level := logger.ToggleDebug()
+			logger.Log.Warnf("SIGUSR1 received, log level is now %s", level)
+		}
+	}()
+}
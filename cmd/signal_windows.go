@@ -0,0 +1,6 @@
+//go:build windows
+
+package cmd
+
+// watchLogLevelSignal tidak tersedia di windows karena tidak ada SIGUSR1.
+func watchLogLevelSignal() {}
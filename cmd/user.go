@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"user-service/constants"
+	db "user-service/db/sqlc"
+	"user-service/dto"
+	"user-service/pkg/helper"
+	"user-service/service"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) userCommand() *cobra.Command {
+	userCmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users directly against the database",
+	}
+	userCmd.AddCommand(
+		c.userCreateCommand(),
+		c.userGetCommand(),
+		c.userListCommand(),
+		c.userSetRoleCommand(),
+		c.userDeleteCommand(),
+		c.userSendVerificationCommand(),
+		c.userVerifyEmailCommand(),
+	)
+	return userCmd
+}
+
+func (c *cli) userCreateCommand() *cobra.Command {
+	var req dto.CreateUserRequest
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := c.validateFlags(&req); err != nil {
+				return err
+			}
+
+			services, closeDB, err := c.openServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			user, err := services.UserService().CreateUser(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), user)
+		},
+	}
+	cmd.Flags().StringVar(&req.Email, "email", "", "email address (required)")
+	cmd.Flags().StringVar(&req.FullName, "full-name", "", "full name")
+	cmd.Flags().StringVar(&req.PhoneNumber, "phone", "", "phone number, +<country code> or national in PHONE_DEFAULT_REGION")
+	cmd.Flags().StringVar(&req.AvatarURL, "avatar-url", "", "avatar URL")
+	cmd.Flags().StringVar(&req.Role, "role", constants.RoleUser, "role: "+strings.Join(constants.Roles, ", "))
+	return cmd
+}
+
+func (c *cli) userGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id|email>",
+		Short: "Show a user by ID or email",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			services, closeDB, err := c.openServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			user, err := findUser(cmd.Context(), services, args[0])
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), user)
+		},
+	}
+}
+
+func (c *cli) userListCommand() *cobra.Command {
+	var req dto.ListUsersRequest
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := c.validateFlags(&req); err != nil {
+				return err
+			}
+
+			services, closeDB, err := c.openServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			users, err := services.UserService().ListUsers(cmd.Context(), db.ListUsersParams{
+				Search: helper.StringToPGTextValid(req.Search),
+				Offset: req.Offset,
+				Limit:  req.Limit,
+			})
+			if err != nil {
+				return err
+			}
+			if users == nil {
+				users = []dto.UserResponse{}
+			}
+			return printJSON(cmd.OutOrStdout(), users)
+		},
+	}
+	cmd.Flags().StringVar(&req.Search, "search", "", "filter by email or full name")
+	cmd.Flags().Int32Var(&req.Offset, "offset", 0, "offset")
+	cmd.Flags().Int32Var(&req.Limit, "limit", 10, "limit (1-100)")
+	return cmd
+}
+
+func (c *cli) userSetRoleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-role <id> <role>",
+		Short: "Change a user's role (" + strings.Join(constants.Roles, ", ") + ")",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf(constants.UuidIsNotValid)
+			}
+			if !slices.Contains(constants.Roles, args[1]) {
+				return fmt.Errorf("role must be one of %s", strings.Join(constants.Roles, ", "))
+			}
+
+			services, closeDB, err := c.openServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			user, err := services.UserService().UpdateUserRole(cmd.Context(), id, args[1])
+			if err != nil {
+				return notFound(err, args[0])
+			}
+			return printJSON(cmd.OutOrStdout(), user)
+		},
+	}
+}
+
+func (c *cli) userDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Soft delete a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf(constants.UuidIsNotValid)
+			}
+
+			services, closeDB, err := c.openServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			if err := services.UserService().DeleteUser(cmd.Context(), id); err != nil {
+				return notFound(err, args[0])
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "user %s deleted\n", id)
+			return nil
+		},
+	}
+}
+
+func (c *cli) userSendVerificationCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "send-verification <id|email>",
+		Short: "Send a new email verification link, invalidating earlier ones",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.cfg.Verification.Policy == constants.VerificationOff {
+				return fmt.Errorf("email verification is disabled (EMAIL_VERIFICATION_POLICY=%s)", constants.VerificationOff)
+			}
+
+			services, closeDB, err := c.openServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			user, err := findUser(cmd.Context(), services, args[0])
+			if err != nil {
+				return err
+			}
+			if err := services.VerificationService().SendVerification(cmd.Context(), user.ID); err != nil {
+				return fmt.Errorf("user %s: %w", args[0], err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "verification email sent to %s\n", user.Email)
+			return nil
+		},
+	}
+}
+
+func (c *cli) userVerifyEmailCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-email <id|email>",
+		Short: "Mark a user's email address as verified without a token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			services, closeDB, err := c.openServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			user, err := findUser(cmd.Context(), services, args[0])
+			if err != nil {
+				return err
+			}
+			user, err = services.VerificationService().MarkVerified(cmd.Context(), user.ID)
+			if err != nil {
+				return notFound(err, args[0])
+			}
+			return printJSON(cmd.OutOrStdout(), user)
+		},
+	}
+}
+
+// findUser mencari user berdasarkan ID atau email.
+func findUser(ctx context.Context, services service.ServiceRegistry, ref string) (dto.UserResponse, error) {
+	var user dto.UserResponse
+	var err error
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		user, err = services.UserService().GetUserByID(ctx, id)
+	} else {
+		user, err = services.UserService().GetUserByEmail(ctx, ref)
+	}
+	if err != nil {
+		return dto.UserResponse{}, notFound(err, ref)
+	}
+	return user, nil
+}
+
+// validateFlags memvalidasi DTO yang diisi dari flag dengan pesan bahasa Inggris.
+func (c *cli) validateFlags(req any) error {
+	v := helper.NewValidator(helper.WithPhoneRegion(c.cfg.User.PhoneRegion))
+	if err := v.Struct(req); err != nil {
+		return v.Translate(err, "flag", v.Translator(helper.DefaultLocale))
+	}
+	return nil
+}
@@ -1,105 +1,209 @@
 package config
 
 import (
-	"os"
-	"strconv"
-	"strings"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
+// Setiap field punya tag:
+//   - key:     nama di file config dan flag (--db.host), bersarang mengikuti struct
+//   - env:     nama environment variable
+//   - default: nilai bawaan
+//   - secret:  disamarkan saat `config print`
+//   - provider: nama secret di SecretProvider (lihat SecretsConfig)
+//
+// Urutan prioritas: default < file config < env (atau <ENV>_FILE) < secret provider < flag.
 type AppConfig struct {
-	AppEnv   string
-	AppPort  string
-	GRPCPort string
-	LogLevel string
-	DB       DBConfig
-	JWT      JWTConfig
-	Redis    RedisConfig
-	Kafka    KafkaConfig
+	AppEnv       string             `key:"app_env" env:"APP_ENV" default:"development"`
+	AppPort      string             `key:"app_port" env:"APP_PORT" default:"8080"`
+	GRPCPort     string             `key:"grpc_port" env:"APP_GRPC_PORT" default:"8081"`
+	LogLevel     string             `key:"log_level" env:"LOG_LEVEL" default:"info"`
+	DB           DBConfig           `key:"db"`
+	JWT          JWTConfig          `key:"jwt"`
+	Redis        RedisConfig        `key:"redis"`
+	Kafka        KafkaConfig        `key:"kafka"`
+	Tracing      TracingConfig      `key:"tracing"`
+	Admin        AdminConfig        `key:"admin"`
+	Secrets      SecretsConfig      `key:"secrets"`
+	Storage      StorageConfig      `key:"storage"`
+	User         UserConfig         `key:"user"`
+	Avatar       AvatarConfig       `key:"avatar"`
+	Mail         MailConfig         `key:"mail"`
+	Verification VerificationConfig `key:"verification"`
+	EmailChange  EmailChangeConfig  `key:"email_change"`
+	SMS          SMSConfig          `key:"sms"`
+	PhoneOTP     PhoneOTPConfig     `key:"phone_otp"`
+	Password     PasswordConfig     `key:"password"`
+
+	// loadProblems berisi nilai yang gagal di-parse, dilaporkan oleh Validate
+	loadProblems []string
+	// sources mencatat asal setiap nilai, misalnya "env:DB_HOST"
+	sources map[string]string
 }
 
 type DBConfig struct {
-	Host            string
-	Port            string
-	User            string
-	Password        string
-	Name            string
-	MaxOpenConns    int
-	ConnMaxLifetime time.Duration
+	// URL (DATABASE_URL) menggantikan Host/Port/User/Password/Name/SSL*; pengaturan pool tetap dipakai
+	URL      string `key:"url" env:"DATABASE_URL" secret:"true" provider:"database_url"`
+	Host     string `key:"host" env:"DB_HOST" default:"localhost"`
+	Port     string `key:"port" env:"DB_PORT" default:"5432"`
+	User     string `key:"user" env:"DB_USER" default:"postgres"`
+	Password string `key:"password" env:"DB_PASSWORD" secret:"true" provider:"db_password"`
+	Name     string `key:"name" env:"DB_NAME" default:"app"`
+
+	// SSLMode: disable, allow, prefer, require, verify-ca atau verify-full
+	SSLMode     string `key:"sslmode" env:"DB_SSLMODE" default:"disable"`
+	SSLRootCert string `key:"sslrootcert" env:"DB_SSLROOTCERT"`
+	SSLCert     string `key:"sslcert" env:"DB_SSLCERT"`
+	SSLKey      string `key:"sslkey" env:"DB_SSLKEY"`
+
+	MaxOpenConns      int           `key:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+	MinConns          int           `key:"min_conns" env:"DB_MIN_CONNS" default:"2"`
+	ConnMaxLifetime   time.Duration `key:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"300s"`
+	ConnMaxIdleTime   time.Duration `key:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"30m"`
+	HealthCheckPeriod time.Duration `key:"health_check_period" env:"DB_HEALTH_CHECK_PERIOD" default:"1m"`
+	ConnectTimeout    time.Duration `key:"connect_timeout" env:"DB_CONNECT_TIMEOUT" default:"5s"`
+	// StatementTimeout: batas waktu per statement di sisi Postgres, 0 berarti tanpa batas
+	StatementTimeout time.Duration `key:"statement_timeout" env:"DB_STATEMENT_TIMEOUT" default:"0s"`
+	ApplicationName  string        `key:"application_name" env:"DB_APPLICATION_NAME" default:"user-service"`
+	// CheckMigrations: tolak start server jika schema database tertinggal dari migrasi ter-embed
+	CheckMigrations bool `key:"check_migrations" env:"DB_CHECK_MIGRATIONS" default:"true"`
 }
 
 type JWTConfig struct {
-	PrivateKeyPath string
-	PublicKeyPath  string
+	PrivateKeyPath string `key:"private_key_path" env:"JWT_PRIVATE_KEY_PATH" default:"key/private.pem"`
+	PublicKeyPath  string `key:"public_key_path" env:"JWT_PUBLIC_KEY_PATH" default:"key/public.pem"`
 }
 
 type RedisConfig struct {
-	Addr string
+	Addr string `key:"addr" env:"REDIS_ADDR" default:"localhost:6379"`
 }
 
 type KafkaConfig struct {
-	Brokers []string
-}
-
-func LoadConfig() *AppConfig {
-	_ = godotenv.Load()
-
-	cfg := &AppConfig{
-		AppEnv:   getEnv("APP_ENV", "development"),
-		AppPort:  getEnv("APP_PORT", "8080"),
-		GRPCPort: getEnv("APP_GRPC_PORT", "8081"),
-		LogLevel: getEnv("LOG_LEVEL", "info"),
-
-		DB: DBConfig{
-			Host:            getEnv("DB_HOST", "localhost"),
-			Port:            getEnv("DB_PORT", "5432"),
-			User:            getEnv("DB_USER", "postgres"),
-			Password:        getEnv("DB_PASSWORD", ""),
-			Name:            getEnv("DB_NAME", "app"),
-			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
-			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 300*time.Second),
-		},
-
-		JWT: JWTConfig{
-			PrivateKeyPath: getEnv("JWT_PRIVATE_KEY_PATH", "key/private.pem"),
-			PublicKeyPath:  getEnv("JWT_PUBLIC_KEY_PATH", "key/public.pem"),
-		},
-
-		Redis: RedisConfig{
-			Addr: getEnv("REDIS_ADDR", "localhost:6379"),
-		},
-
-		Kafka: KafkaConfig{
-			Brokers: strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ","),
-		},
-	}
+	Brokers []string `key:"brokers" env:"KAFKA_BROKERS" default:"localhost:9092"`
+}
 
-	return cfg
+type AdminConfig struct {
+	// Token untuk endpoint /admin, kosong berarti endpoint admin dimatikan
+	Token string `key:"token" env:"ADMIN_TOKEN" secret:"true" provider:"admin_token"`
 }
 
-func getEnv(key, fallback string) string {
-	if val, ok := os.LookupEnv(key); ok {
-		return val
-	}
-	return fallback
+type SecretsConfig struct {
+	// Provider: "none", "file" (satu file per secret di Dir) atau "vault" (KV v2)
+	Provider   string `key:"provider" env:"SECRETS_PROVIDER" default:"none"`
+	Dir        string `key:"dir" env:"SECRETS_DIR" default:"/run/secrets"`
+	VaultAddr  string `key:"vault_addr" env:"VAULT_ADDR" default:"http://127.0.0.1:8200"`
+	VaultToken string `key:"vault_token" env:"VAULT_TOKEN" secret:"true"`
+	VaultMount string `key:"vault_mount" env:"VAULT_MOUNT" default:"secret"`
+	VaultPath  string `key:"vault_path" env:"VAULT_PATH" default:"user-service"`
+	// RefreshInterval: seberapa sering secret (misalnya password DB) diambil ulang, 0 untuk mematikan
+	RefreshInterval time.Duration `key:"refresh_interval" env:"SECRETS_REFRESH_INTERVAL" default:"5m"`
 }
 
-func getEnvAsInt(key string, fallback int) int {
-	if valStr := os.Getenv(key); valStr != "" {
-		if val, err := strconv.Atoi(valStr); err == nil {
-			return val
-		}
-	}
-	return fallback
+type StorageConfig struct {
+	// Provider: "local" (file di Dir) atau "s3" (S3 atau server kompatibel seperti MinIO)
+	Provider string `key:"provider" env:"STORAGE_PROVIDER" default:"local"`
+	Dir      string `key:"dir" env:"STORAGE_DIR" default:"data/blobs"`
+	// PublicURL adalah alamat publik object, misalnya URL CDN. Kosong berarti /media untuk local
+	// (disajikan oleh service sendiri) dan alamat object di endpoint untuk s3
+	PublicURL   string `key:"public_url" env:"STORAGE_PUBLIC_URL"`
+	S3Endpoint  string `key:"s3_endpoint" env:"S3_ENDPOINT" default:"https://s3.amazonaws.com"`
+	S3Region    string `key:"s3_region" env:"S3_REGION" default:"us-east-1"`
+	S3Bucket    string `key:"s3_bucket" env:"S3_BUCKET"`
+	S3AccessKey string `key:"s3_access_key" env:"S3_ACCESS_KEY" secret:"true" provider:"s3_access_key"`
+	S3SecretKey string `key:"s3_secret_key" env:"S3_SECRET_KEY" secret:"true" provider:"s3_secret_key"`
+	// S3PathStyle: {endpoint}/{bucket}/{key} seperti MinIO, false untuk {bucket}.{endpoint}/{key}
+	S3PathStyle bool `key:"s3_path_style" env:"S3_PATH_STYLE" default:"false"`
+}
+
+type UserConfig struct {
+	// EmailProviderRules: alamat dari provider yang dikenal dibuat kanonik sebelum disimpan,
+	// misalnya titik dan +tag di alamat Gmail diabaikan (lihat helper.NormalizeEmail). Keunikan selalu
+	// memakai bentuk kanonik (helper.CanonicalEmail), jadi flag ini boleh dinyalakan kapan saja
+	EmailProviderRules bool `key:"email_provider_rules" env:"EMAIL_PROVIDER_RULES" default:"false"`
+	// PhoneRegion: region ISO 3166-1 untuk nomor telepon tanpa kode negara (081234567890),
+	// lihat phone.Regions. Kosong berarti nomor harus ditulis dengan +kode negara
+	PhoneRegion string `key:"phone_region" env:"PHONE_DEFAULT_REGION" default:"ID"`
+}
+
+type AvatarConfig struct {
+	// MaxBytes: ukuran maksimal upload avatar
+	MaxBytes int `key:"max_bytes" env:"AVATAR_MAX_BYTES" default:"5242880"`
+	// Sizes: sisi thumbnail persegi dalam pixel, yang terbesar dipakai untuk avatar_url
+	Sizes []int `key:"sizes" env:"AVATAR_SIZES" default:"64,128,256"`
+	// ImportURLs: avatar_url dari request diunduh lalu disimpan ulang di storage sendiri,
+	// bukan disimpan apa adanya
+	ImportURLs   bool          `key:"import_urls" env:"AVATAR_IMPORT_URLS" default:"false"`
+	FetchTimeout time.Duration `key:"fetch_timeout" env:"AVATAR_FETCH_TIMEOUT" default:"10s"`
+}
+
+type MailConfig struct {
+	// Provider: "log" (email hanya ditulis ke log), "file" (satu file .eml per email di Dir) atau "smtp"
+	Provider     string `key:"provider" env:"MAIL_PROVIDER" default:"log"`
+	From         string `key:"from" env:"MAIL_FROM" default:"User Service <no-reply@localhost>"`
+	Dir          string `key:"dir" env:"MAIL_DIR" default:"data/mail"`
+	SMTPHost     string `key:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     string `key:"smtp_port" env:"SMTP_PORT" default:"587"`
+	SMTPUsername string `key:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `key:"smtp_password" env:"SMTP_PASSWORD" secret:"true" provider:"smtp_password"`
+}
+
+type VerificationConfig struct {
+	// Policy: "off", "optional" atau "required" (lihat constants.VerificationPolicies)
+	Policy string `key:"policy" env:"EMAIL_VERIFICATION_POLICY" default:"optional"`
+	// URL halaman frontend yang menerima ?token= dan mengirimkannya ke POST /users/verify-email
+	URL      string        `key:"url" env:"EMAIL_VERIFICATION_URL" default:"http://localhost:3000/verify-email"`
+	TokenTTL time.Duration `key:"token_ttl" env:"EMAIL_VERIFICATION_TOKEN_TTL" default:"24h"`
+}
+
+type EmailChangeConfig struct {
+	// ConfirmURL: halaman frontend yang menerima ?token= dari email ke alamat baru dan mengirimkannya
+	// ke POST /users/email-change/confirm
+	ConfirmURL string `key:"confirm_url" env:"EMAIL_CHANGE_CONFIRM_URL" default:"http://localhost:3000/confirm-email-change"`
+	// RevertURL: halaman frontend untuk link pembatalan yang dikirim ke alamat lama, ke POST /users/email-change/revert
+	RevertURL string        `key:"revert_url" env:"EMAIL_CHANGE_REVERT_URL" default:"http://localhost:3000/revert-email-change"`
+	TokenTTL  time.Duration `key:"token_ttl" env:"EMAIL_CHANGE_TOKEN_TTL" default:"24h"`
+	// RevertTTL: masa tenggang link pembatalan setelah email diganti
+	RevertTTL time.Duration `key:"revert_ttl" env:"EMAIL_CHANGE_REVERT_TTL" default:"168h"`
+}
+
+type SMSConfig struct {
+	// Provider: "log" (SMS hanya ditulis ke log, termasuk kode OTP) atau "none" (verifikasi nomor telepon dimatikan)
+	Provider string `key:"provider" env:"SMS_PROVIDER" default:"log"`
+}
+
+type PhoneOTPConfig struct {
+	TTL time.Duration `key:"ttl" env:"PHONE_OTP_TTL" default:"5m"`
+	// MaxAttempts: jumlah tebakan kode sebelum kode dibatalkan dan user harus meminta kode baru
+	MaxAttempts int `key:"max_attempts" env:"PHONE_OTP_MAX_ATTEMPTS" default:"5"`
+	// ResendInterval: jeda minimal sebelum kode baru boleh diminta
+	ResendInterval time.Duration `key:"resend_interval" env:"PHONE_OTP_RESEND_INTERVAL" default:"1m"`
+	// MaxSends: jumlah kode yang boleh dikirim ke satu nomor telepon dalam SendWindow, dari user mana pun
+	MaxSends   int           `key:"max_sends" env:"PHONE_OTP_MAX_SENDS" default:"5"`
+	SendWindow time.Duration `key:"send_window" env:"PHONE_OTP_SEND_WINDOW" default:"24h"`
+}
+
+type PasswordConfig struct {
+	// ResetURL: halaman frontend yang menerima ?token= dari email reset dan mengirimkannya ke POST /auth/password/reset
+	ResetURL      string        `key:"reset_url" env:"PASSWORD_RESET_URL" default:"http://localhost:3000/reset-password"`
+	ResetTokenTTL time.Duration `key:"reset_token_ttl" env:"PASSWORD_RESET_TOKEN_TTL" default:"1h"`
+	// HashCost: cost bcrypt, setiap kenaikan 1 menggandakan waktu hash
+	HashCost int `key:"hash_cost" env:"PASSWORD_HASH_COST" default:"12"`
+}
+
+type TracingConfig struct {
+	// Exporter: "otlp", "stdout" atau "none"
+	Exporter    string  `key:"exporter" env:"OTEL_TRACES_EXPORTER" default:"none"`
+	Endpoint    string  `key:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"localhost:4318"`
+	ServiceName string  `key:"service_name" env:"OTEL_SERVICE_NAME" default:"user-service"`
+	SampleRatio float64 `key:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG" default:"1"`
 }
 
-func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
-	if valStr := os.Getenv(key); valStr != "" {
-		if valInt, err := strconv.Atoi(valStr); err == nil {
-			return time.Duration(valInt) * time.Second
-		}
+// LoadConfig memuat konfigurasi dari default, file config, env dan flag pada args.
+// Error hanya dikembalikan untuk flag yang tidak valid (termasuk flag.ErrHelp);
+// nilai yang gagal di-parse dilaporkan lewat Validate.
+func LoadConfig(args []string) (*AppConfig, error) {
+	l := NewLoader()
+	if err := l.FlagSet().Parse(args); err != nil {
+		return nil, err
 	}
-	return fallback
+	return l.Load(), nil
 }
@@ -0,0 +1,395 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"user-service/pkg/secrets"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+// Loader menggabungkan default, file config, env, secret provider dan flag menjadi AppConfig.
+type Loader struct {
+	flags      *flag.FlagSet
+	flagValues map[string]*flagValue
+	configFile flagValue
+}
+
+// flagValue mencatat apakah flag benar-benar di-set, supaya flag yang tidak diisi
+// tidak menimpa nilai dari env atau file.
+type flagValue struct {
+	value string
+	set   bool
+}
+
+func (v *flagValue) String() string { return v.value }
+
+func (v *flagValue) Set(s string) error {
+	v.value = s
+	v.set = true
+	return nil
+}
+
+// NewLoader membuat Loader beserta flag untuk setiap field config, misalnya --db.host.
+func NewLoader() *Loader {
+	l := &Loader{
+		flags:      flag.NewFlagSet("user-service", flag.ContinueOnError),
+		flagValues: map[string]*flagValue{},
+	}
+	l.flags.Var(&l.configFile, "config", "path to a YAML or TOML config file (env CONFIG_FILE, default config/<app_env>.yaml)")
+
+	for _, f := range fieldsOf(&AppConfig{}) {
+		v := &flagValue{}
+		l.flagValues[f.key] = v
+		usage := fmt.Sprintf("env %s", f.env)
+		if f.def != "" {
+			usage += fmt.Sprintf(", default %q", f.def)
+		}
+		l.flags.Var(v, f.key, usage)
+	}
+	return l
+}
+
+// FlagSet mengembalikan flag milik Loader agar bisa di-parse atau digabung ke CLI lain.
+func (l *Loader) FlagSet() *flag.FlagSet {
+	return l.flags
+}
+
+// Load membaca semua layer. Panggil setelah FlagSet di-parse.
+func (l *Loader) Load() *AppConfig {
+	_ = godotenv.Load()
+
+	cfg := &AppConfig{sources: map[string]string{}}
+	fields := fieldsOf(cfg)
+
+	path, fileValues, err := l.readFile(l.bootstrapAppEnv())
+	if err != nil {
+		cfg.loadProblems = append(cfg.loadProblems, err.Error())
+	}
+
+	for _, f := range fields {
+		raw, source := f.def, "default"
+		if v, ok := fileValues[f.key]; ok {
+			raw, source = v, "file:"+path
+			delete(fileValues, f.key)
+		}
+		if v, ok := os.LookupEnv(f.env); ok {
+			raw, source = v, "env:"+f.env
+		}
+		// <ENV>_FILE untuk Docker/Kubernetes secrets, isi file dipakai sebagai nilai
+		if secretPath, ok := os.LookupEnv(f.env + "_FILE"); ok {
+			if _, both := os.LookupEnv(f.env); both {
+				cfg.loadProblems = append(cfg.loadProblems, fmt.Sprintf("%s and %s_FILE are both set, use only one", f.env, f.env))
+			}
+			v, err := secrets.ReadFile(secretPath)
+			if err != nil {
+				cfg.loadProblems = append(cfg.loadProblems, fmt.Sprintf("%s_FILE: cannot read %s: %v", f.env, secretPath, err))
+			} else {
+				raw, source = v, "env:"+f.env+"_FILE"
+			}
+		}
+		if v := l.flagValues[f.key]; v != nil && v.set {
+			raw, source = v.value, "flag:--"+f.key
+		}
+
+		if err := f.set(raw); err != nil {
+			cfg.loadProblems = append(cfg.loadProblems, fmt.Sprintf("%s: %q is not a valid %s (from %s)", f.env, raw, err, source))
+			_ = f.set(f.def)
+			source = "default"
+		}
+		cfg.sources[f.key] = source
+	}
+
+	l.loadFromProvider(cfg, fields)
+
+	unknown := make([]string, 0, len(fileValues))
+	for key := range fileValues {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		cfg.loadProblems = append(cfg.loadProblems, fmt.Sprintf("%s: unknown key %q", path, key))
+	}
+
+	return cfg
+}
+
+// loadFromProvider mengisi field bertag `provider` dari SecretProvider, kecuali yang di-set lewat flag.
+func (l *Loader) loadFromProvider(cfg *AppConfig, fields []field) {
+	provider, err := cfg.SecretProvider()
+	if err != nil {
+		cfg.loadProblems = append(cfg.loadProblems, err.Error())
+		return
+	}
+	if provider == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, f := range fields {
+		if f.provider == "" {
+			continue
+		}
+		if v := l.flagValues[f.key]; v != nil && v.set {
+			continue
+		}
+		value, err := provider.GetSecret(ctx, f.provider)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			cfg.loadProblems = append(cfg.loadProblems, fmt.Sprintf("%s: secret %q: %v", f.env, f.provider, err))
+			continue
+		}
+		if err := f.set(value); err != nil {
+			cfg.loadProblems = append(cfg.loadProblems, fmt.Sprintf("%s: secret %q is not a valid %s", f.env, f.provider, err))
+			continue
+		}
+		cfg.sources[f.key] = "secret:" + cfg.Secrets.Provider + ":" + f.provider
+	}
+}
+
+// bootstrapAppEnv menentukan APP_ENV sebelum file dibaca, karena file yang dipilih bergantung padanya.
+func (l *Loader) bootstrapAppEnv() string {
+	if v := l.flagValues["app_env"]; v != nil && v.set {
+		return v.value
+	}
+	if v, ok := os.LookupEnv("APP_ENV"); ok {
+		return v
+	}
+	return "development"
+}
+
+// readFile membaca file dari --config / CONFIG_FILE, atau mencari <CONFIG_DIR>/<appEnv>.{yaml,yml,toml}.
+// Tidak adanya file hanya error jika path-nya diminta secara eksplisit.
+func (l *Loader) readFile(appEnv string) (string, map[string]string, error) {
+	path := l.configFile.value
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		dir := os.Getenv("CONFIG_DIR")
+		if dir == "" {
+			dir = "config"
+		}
+		for _, ext := range []string{".yaml", ".yml", ".toml"} {
+			candidate := filepath.Join(dir, appEnv+ext)
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+		if path == "" {
+			return "", nil, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, nil, fmt.Errorf("config file: %v", err)
+	}
+
+	raw := map[string]any{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return path, nil, fmt.Errorf("config file %s: %v", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+	return path, values, nil
+}
+
+// flatten mengubah map bersarang menjadi key bertitik, list digabung dengan koma.
+func flatten(prefix string, in map[string]any, out map[string]string) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]any:
+			flatten(key, val, out)
+		case []any:
+			items := make([]string, len(val))
+			for i, item := range val {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(val)
+		}
+	}
+}
+
+// Print menulis konfigurasi efektif beserta asal setiap nilai. Field secret disamarkan.
+func (c *AppConfig) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, f := range fieldsOf(c) {
+		value := f.String()
+		if f.secret && value != "" {
+			value = redacted
+		}
+		source := c.sources[f.key]
+		if source == "" {
+			source = "default"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.key, value, source)
+	}
+	return tw.Flush()
+}
+
+// Source mengembalikan asal nilai untuk key, misalnya "env:DB_HOST".
+func (c *AppConfig) Source(key string) string {
+	return c.sources[key]
+}
+
+type field struct {
+	key      string
+	env      string
+	def      string
+	secret   bool
+	provider string
+	value    reflect.Value
+}
+
+// fieldsOf mengumpulkan semua field bertag `key` secara rekursif sesuai urutan deklarasi.
+func fieldsOf(cfg *AppConfig) []field {
+	var fields []field
+	collectFields("", reflect.ValueOf(cfg).Elem(), &fields)
+	return fields
+}
+
+func collectFields(prefix string, v reflect.Value, out *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key, ok := sf.Tag.Lookup("key")
+		if !ok {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if sf.Type.Kind() == reflect.Struct {
+			collectFields(key, v.Field(i), out)
+			continue
+		}
+		*out = append(*out, field{
+			key:      key,
+			env:      sf.Tag.Get("env"),
+			def:      sf.Tag.Get("default"),
+			secret:   sf.Tag.Get("secret") == "true",
+			provider: sf.Tag.Get("provider"),
+			value:    v.Field(i),
+		})
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set mem-parse raw sesuai tipe field. Error berisi nama tipe yang diharapkan.
+func (f field) set(raw string) error {
+	switch {
+	case f.value.Type() == durationType:
+		// angka tanpa satuan dianggap detik, sesuai .env.example
+		if secs, err := strconv.Atoi(raw); err == nil {
+			f.value.SetInt(int64(time.Duration(secs) * time.Second))
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("duration (seconds or e.g. 5m)")
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(raw)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("integer")
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("number")
+		}
+		f.value.SetFloat(n)
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("boolean")
+		}
+		f.value.SetBool(b)
+	case f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.String:
+		var items []string
+		if raw != "" {
+			items = strings.Split(raw, ",")
+			for i := range items {
+				items[i] = strings.TrimSpace(items[i])
+			}
+		}
+		f.value.Set(reflect.ValueOf(items))
+	case f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.Int:
+		var items []int
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			n, err := strconv.Atoi(item)
+			if err != nil {
+				return fmt.Errorf("comma-separated list of integers")
+			}
+			items = append(items, n)
+		}
+		f.value.Set(reflect.ValueOf(items))
+	default:
+		panic(fmt.Sprintf("config: unsupported field type %s for %s", f.value.Type(), f.key))
+	}
+	return nil
+}
+
+// String mengembalikan nilai field dalam format yang sama dengan env/flag.
+func (f field) String() string {
+	switch {
+	case f.value.Type() == durationType:
+		return time.Duration(f.value.Int()).String()
+	case f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.Int:
+		items := f.value.Interface().([]int)
+		parts := make([]string, len(items))
+		for i, n := range items {
+			parts[i] = strconv.Itoa(n)
+		}
+		return strings.Join(parts, ",")
+	case f.value.Kind() == reflect.Slice:
+		return strings.Join(f.value.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(f.value.Interface())
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnvFile(t *testing.T) {
+	dir := newTestEnv(t)
+	writeFile(t, filepath.Join(dir, "test.yaml"), "db:\n  password: from-file\n")
+	secret := filepath.Join(t.TempDir(), "db_password")
+	writeFile(t, secret, "from-secret\n")
+
+	t.Run("file wins over config file", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD_FILE", secret)
+		cfg := load(t)
+		assertNoProblems(t, cfg)
+		if cfg.DB.Password != "from-secret" || cfg.Source("db.password") != "env:DB_PASSWORD_FILE" {
+			t.Errorf("db.password = %q from %s", cfg.DB.Password, cfg.Source("db.password"))
+		}
+		// password database diambil ulang dari file yang sama
+		provider, name, ok := cfg.SecretSource("db.password")
+		if !ok || name != "db_password" {
+			t.Fatalf("SecretSource = %v, %q, %v", provider, name, ok)
+		}
+		if v, err := provider.GetSecret(context.Background(), name); err != nil || v != "from-secret" {
+			t.Errorf("GetSecret = %q, %v", v, err)
+		}
+	})
+
+	t.Run("env and file both set", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD", "from-env")
+		t.Setenv("DB_PASSWORD_FILE", secret)
+		cfg := load(t)
+		assertProblem(t, cfg, "DB_PASSWORD and DB_PASSWORD_FILE are both set, use only one")
+		if cfg.DB.Password != "from-secret" {
+			t.Errorf("db.password = %q, want the value of DB_PASSWORD_FILE", cfg.DB.Password)
+		}
+	})
+
+	t.Run("flag wins over file", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD_FILE", secret)
+		cfg := load(t, "--db.password=from-flag")
+		if cfg.DB.Password != "from-flag" || cfg.Source("db.password") != "flag:--db.password" {
+			t.Errorf("db.password = %q from %s", cfg.DB.Password, cfg.Source("db.password"))
+		}
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing")
+		t.Setenv("DB_PASSWORD_FILE", missing)
+		cfg := load(t)
+		assertProblem(t, cfg, "DB_PASSWORD_FILE: cannot read "+missing+": secret not found")
+		if cfg.DB.Password != "from-file" || !strings.HasPrefix(cfg.Source("db.password"), "file:") {
+			t.Errorf("db.password = %q from %s, want the config file value", cfg.DB.Password, cfg.Source("db.password"))
+		}
+	})
+}
+
+func TestPrecedence(t *testing.T) {
+	dir := newTestEnv(t)
+	secretsDir := t.TempDir()
+
+	// setiap langkah menambah satu layer di atas layer sebelumnya
+	steps := []struct {
+		name   string
+		apply  func(t *testing.T)
+		args   []string
+		want   string
+		source string
+	}{
+		{"default", func(*testing.T) {}, nil, "", "default"},
+		{"file", func(t *testing.T) {
+			writeFile(t, filepath.Join(dir, "test.yaml"), "db:\n  password: from-file\n")
+		}, nil, "from-file", "file:" + filepath.Join(dir, "test.yaml")},
+		{"env", func(t *testing.T) { t.Setenv("DB_PASSWORD", "from-env") }, nil, "from-env", "env:DB_PASSWORD"},
+		{"provider", func(t *testing.T) {
+			t.Setenv("SECRETS_PROVIDER", "file")
+			t.Setenv("SECRETS_DIR", secretsDir)
+			writeFile(t, filepath.Join(secretsDir, "db_password"), "from-provider\n")
+		}, nil, "from-provider", "secret:file:db_password"},
+		{"flag", func(*testing.T) {}, []string{"--db.password=from-flag"}, "from-flag", "flag:--db.password"},
+	}
+	for _, step := range steps {
+		step.apply(t)
+		cfg := load(t, step.args...)
+		assertNoProblems(t, cfg)
+		if cfg.DB.Password != step.want || cfg.Source("db.password") != step.source {
+			t.Errorf("%s: db.password = %q from %q, want %q from %q",
+				step.name, cfg.DB.Password, cfg.Source("db.password"), step.want, step.source)
+		}
+		// field lain tetap dari default
+		if cfg.DB.Host != "localhost" || cfg.Source("db.host") != "default" {
+			t.Errorf("%s: db.host = %q from %q", step.name, cfg.DB.Host, cfg.Source("db.host"))
+		}
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	newTestEnv(t)
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("DB_USER", "app_user")
+	cfg := load(t)
+
+	var b strings.Builder
+	if err := cfg.Print(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("Print leaks a secret:\n%s", out)
+	}
+	for _, want := range []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^db\.password +\*{6} +env:DB_PASSWORD$`),
+		regexp.MustCompile(`(?m)^db\.user +app_user +env:DB_USER$`),
+		regexp.MustCompile(`(?m)^db\.host +localhost +default$`),
+		// secret yang kosong tidak disamarkan, supaya terlihat belum di-set
+		regexp.MustCompile(`(?m)^admin\.token +default$`),
+	} {
+		if !want.MatchString(out) {
+			t.Errorf("Print output does not match %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	dir := newTestEnv(t)
+	path := filepath.Join(dir, "test.yaml")
+	writeFile(t, path, "db:\n  hots: db.internal\n  port: 6543\nlog_levle: debug\n")
+	cfg := load(t)
+
+	want := []string{
+		path + `: unknown key "db.hots"`,
+		path + `: unknown key "log_levle"`,
+	}
+	if !slices.Equal(cfg.loadProblems, want) {
+		t.Errorf("load problems = %q, want %q", cfg.loadProblems, want)
+	}
+	if cfg.DB.Port != "6543" {
+		t.Errorf("known keys of the same file are still used: db.port = %q", cfg.DB.Port)
+	}
+}
+
+func TestFlattenYAMLAndTOML(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "test.yaml")
+	writeFile(t, yamlPath, `app_port: 9090
+db:
+  host: db.internal
+  port: 6543
+  max_open_conns: 10
+  conn_max_lifetime: 5m
+avatar:
+  sizes: [32, 64]
+  import_urls: true
+tracing:
+  sample_ratio: 0.5
+`)
+	tomlPath := filepath.Join(t.TempDir(), "test.toml")
+	writeFile(t, tomlPath, `app_port = 9090
+
+[db]
+host = "db.internal"
+port = 6543
+max_open_conns = 10
+conn_max_lifetime = "5m"
+
+[avatar]
+sizes = [32, 64]
+import_urls = true
+
+[tracing]
+sample_ratio = 0.5
+`)
+
+	var flat []map[string]string
+	for _, path := range []string{yamlPath, tomlPath} {
+		t.Setenv("CONFIG_FILE", path)
+		_, values, err := NewLoader().readFile("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		flat = append(flat, values)
+	}
+	want := map[string]string{
+		"app_port":             "9090",
+		"db.host":              "db.internal",
+		"db.port":              "6543",
+		"db.max_open_conns":    "10",
+		"db.conn_max_lifetime": "5m",
+		"avatar.sizes":         "32,64",
+		"avatar.import_urls":   "true",
+		"tracing.sample_ratio": "0.5",
+	}
+	if !maps.Equal(flat[0], want) {
+		t.Errorf("YAML flattened to %v, want %v", flat[0], want)
+	}
+	if !maps.Equal(flat[1], want) {
+		t.Errorf("TOML flattened to %v, want %v", flat[1], want)
+	}
+}
+
+// newTestEnv mengarahkan CONFIG_DIR ke direktori kosong dan APP_ENV ke "test", jadi file config yang dibaca
+// hanya <dir>/test.{yaml,yml,toml} yang ditulis test sendiri.
+func newTestEnv(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("CONFIG_DIR", dir)
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("APP_ENV", "test")
+	return dir
+}
+
+// load menjalankan Loader baru dengan args sebagai flag.
+func load(t *testing.T, args ...string) *AppConfig {
+	t.Helper()
+	l := NewLoader()
+	if err := l.FlagSet().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return l.Load()
+}
+
+func assertNoProblems(t *testing.T, cfg *AppConfig) {
+	t.Helper()
+	if len(cfg.loadProblems) > 0 {
+		t.Errorf("unexpected load problems: %q", cfg.loadProblems)
+	}
+}
+
+func assertProblem(t *testing.T, cfg *AppConfig, want string) {
+	t.Helper()
+	if !slices.Contains(cfg.loadProblems, want) {
+		t.Errorf("load problems %q do not contain %q", cfg.loadProblems, want)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
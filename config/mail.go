@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+
+	"user-service/pkg/mail"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Mailer membuat mail.Mailer sesuai Mail.Provider. log dipakai oleh mailer "log".
+func (c *AppConfig) Mailer(log logrus.FieldLogger) (mail.Mailer, error) {
+	m := c.Mail
+	switch m.Provider {
+	case "log":
+		return mail.NewLogMailer(log), nil
+	case "file":
+		return mail.NewFileMailer(m.Dir, m.From), nil
+	case "smtp":
+		return mail.NewSMTPMailer(m.SMTPHost, m.SMTPPort, m.SMTPUsername, m.SMTPPassword, m.From), nil
+	default:
+		return nil, fmt.Errorf("MAIL_PROVIDER: %q must be one of log, file, smtp", m.Provider)
+	}
+}
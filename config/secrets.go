@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"user-service/pkg/secrets"
+)
+
+// SecretProvider membuat secrets.Provider sesuai Secrets.Provider, nil jika "none".
+func (c *AppConfig) SecretProvider() (secrets.Provider, error) {
+	switch c.Secrets.Provider {
+	case "none", "":
+		return nil, nil
+	case "file":
+		return secrets.NewFileProvider(c.Secrets.Dir), nil
+	case "vault":
+		return secrets.NewVaultProvider(c.Secrets.VaultAddr, c.Secrets.VaultToken, c.Secrets.VaultMount, c.Secrets.VaultPath), nil
+	default:
+		return nil, fmt.Errorf("SECRETS_PROVIDER: %q must be one of none, file, vault", c.Secrets.Provider)
+	}
+}
+
+// SecretSource mengembalikan provider dan nama secret untuk mengambil ulang nilai key,
+// misalnya "db.password". ok bernilai false jika nilainya bukan dari file atau secret provider.
+func (c *AppConfig) SecretSource(key string) (provider secrets.Provider, name string, ok bool) {
+	source := c.sources[key]
+	switch {
+	case strings.HasPrefix(source, "secret:"):
+		provider, err := c.SecretProvider()
+		if err != nil || provider == nil {
+			return nil, "", false
+		}
+		return provider, source[strings.LastIndex(source, ":")+1:], true
+	case strings.HasPrefix(source, "env:") && strings.HasSuffix(source, "_FILE"):
+		path, ok := os.LookupEnv(source[len("env:"):])
+		if !ok {
+			return nil, "", false
+		}
+		return secrets.NewFileProvider(filepath.Dir(path)), filepath.Base(path), true
+	}
+	return nil, "", false
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+
+	"user-service/pkg/sms"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SMSSender membuat sms.Sender sesuai SMS.Provider. Provider "none" menghasilkan nil:
+// verifikasi nomor telepon dimatikan. log dipakai oleh sender "log".
+func (c *AppConfig) SMSSender(log logrus.FieldLogger) (sms.Sender, error) {
+	switch c.SMS.Provider {
+	case "log":
+		return sms.NewLogSender(log), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("SMS_PROVIDER: %q must be one of log, none", c.SMS.Provider)
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+
+	"user-service/pkg/blob"
+)
+
+// DefaultMediaPath adalah prefix tempat service menyajikan Storage.Dir jika provider local
+// dan PublicURL kosong.
+const DefaultMediaPath = "/media"
+
+// BlobStore membuat blob.Store sesuai Storage.Provider.
+func (c *AppConfig) BlobStore() (blob.Store, error) {
+	switch c.Storage.Provider {
+	case "local":
+		publicURL := c.Storage.PublicURL
+		if publicURL == "" {
+			publicURL = DefaultMediaPath
+		}
+		return blob.NewFileStore(c.Storage.Dir, publicURL), nil
+	case "s3":
+		s := c.Storage
+		return blob.NewS3Store(s.S3Endpoint, s.S3Region, s.S3Bucket, s.S3AccessKey, s.S3SecretKey, s.S3PathStyle, s.PublicURL), nil
+	default:
+		return nil, fmt.Errorf("STORAGE_PROVIDER: %q must be one of local, s3", c.Storage.Provider)
+	}
+}
@@ -0,0 +1,362 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"user-service/constants"
+	"user-service/pkg/phone"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ValidationError berisi semua masalah konfigurasi sekaligus, supaya operator
+// tidak perlu restart berkali-kali untuk menemukan masalah satu per satu.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration (%d problems):", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// IsProduction bernilai true untuk APP_ENV "prod" atau "production".
+func (c *AppConfig) IsProduction() bool {
+	return c.AppEnv == "prod" || c.AppEnv == "production"
+}
+
+// Validate memeriksa seluruh konfigurasi dan mengembalikan *ValidationError jika ada masalah.
+func (c *AppConfig) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	problems = append(problems, c.loadProblems...)
+
+	switch c.AppEnv {
+	case "development", "dev", "test", "staging", "prod", "production":
+	default:
+		add("APP_ENV: %q must be one of development, dev, test, staging, prod, production", c.AppEnv)
+	}
+	if err := validatePort(c.AppPort); err != nil {
+		add("APP_PORT: %v", err)
+	}
+	if err := validatePort(c.GRPCPort); err != nil {
+		add("APP_GRPC_PORT: %v", err)
+	}
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		add("LOG_LEVEL: %q is not a valid level (trace, debug, info, warn, error)", c.LogLevel)
+	}
+
+	// database
+	if c.DB.URL != "" {
+		if _, err := pgconn.ParseConfig(c.DB.URL); err != nil {
+			add("DATABASE_URL: cannot be parsed (check the scheme, host and escaping of the password)")
+		}
+	} else {
+		if c.DB.Host == "" {
+			add("DB_HOST: must not be empty")
+		}
+		if err := validatePort(c.DB.Port); err != nil {
+			add("DB_PORT: %v", err)
+		}
+		if c.DB.User == "" {
+			add("DB_USER: must not be empty")
+		}
+		if c.DB.Name == "" {
+			add("DB_NAME: must not be empty")
+		}
+
+		switch c.DB.SSLMode {
+		case "disable", "allow", "prefer", "require":
+		case "verify-ca", "verify-full":
+			if c.DB.SSLRootCert == "" {
+				add("DB_SSLROOTCERT: required when DB_SSLMODE=%s", c.DB.SSLMode)
+			}
+		default:
+			add("DB_SSLMODE: %q must be one of disable, allow, prefer, require, verify-ca, verify-full", c.DB.SSLMode)
+		}
+		for _, kv := range [][2]string{
+			{"DB_SSLROOTCERT", c.DB.SSLRootCert},
+			{"DB_SSLCERT", c.DB.SSLCert},
+			{"DB_SSLKEY", c.DB.SSLKey},
+		} {
+			if kv[1] == "" {
+				continue
+			}
+			if err := validateReadableFile(kv[1]); err != nil {
+				add("%s: %v", kv[0], err)
+			}
+		}
+		if (c.DB.SSLCert == "") != (c.DB.SSLKey == "") {
+			add("DB_SSLCERT and DB_SSLKEY must be set together")
+		}
+	}
+	if c.DB.MaxOpenConns < 1 {
+		add("DB_MAX_OPEN_CONNS: must be at least 1, got %d", c.DB.MaxOpenConns)
+	}
+	if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxOpenConns {
+		add("DB_MIN_CONNS: must be between 0 and DB_MAX_OPEN_CONNS (%d), got %d", c.DB.MaxOpenConns, c.DB.MinConns)
+	}
+	if c.DB.ConnMaxLifetime <= 0 {
+		add("DB_CONN_MAX_LIFETIME: must be positive, got %s", c.DB.ConnMaxLifetime)
+	}
+	if c.DB.ConnMaxIdleTime <= 0 {
+		add("DB_CONN_MAX_IDLE_TIME: must be positive, got %s", c.DB.ConnMaxIdleTime)
+	}
+	if c.DB.HealthCheckPeriod <= 0 {
+		add("DB_HEALTH_CHECK_PERIOD: must be positive, got %s", c.DB.HealthCheckPeriod)
+	}
+	if c.DB.ConnectTimeout < 0 {
+		add("DB_CONNECT_TIMEOUT: must not be negative, got %s", c.DB.ConnectTimeout)
+	}
+	if c.DB.StatementTimeout < 0 {
+		add("DB_STATEMENT_TIMEOUT: must not be negative, got %s", c.DB.StatementTimeout)
+	}
+
+	// jwt: di production key wajib ada, di luar production hanya dicek kalau file-nya ada
+	for _, kv := range [][2]string{
+		{"JWT_PRIVATE_KEY_PATH", c.JWT.PrivateKeyPath},
+		{"JWT_PUBLIC_KEY_PATH", c.JWT.PublicKeyPath},
+	} {
+		key, path := kv[0], kv[1]
+		if err := validateReadableFile(path); err != nil {
+			if c.IsProduction() || !os.IsNotExist(err) {
+				add("%s: %v", key, err)
+			}
+		}
+	}
+
+	// redis & kafka
+	if err := validateHostPort(c.Redis.Addr); err != nil {
+		add("REDIS_ADDR: %v", err)
+	}
+	if len(c.Kafka.Brokers) == 0 {
+		add("KAFKA_BROKERS: must contain at least one broker")
+	}
+	for i, broker := range c.Kafka.Brokers {
+		if err := validateHostPort(strings.TrimSpace(broker)); err != nil {
+			add("KAFKA_BROKERS[%d]: %v", i, err)
+		}
+	}
+
+	// tracing
+	switch c.Tracing.Exporter {
+	case "otlp", "stdout", "none", "":
+	default:
+		add("OTEL_TRACES_EXPORTER: %q must be one of otlp, stdout, none", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("OTEL_TRACES_SAMPLER_ARG: must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	// secrets
+	if c.Secrets.Provider == "vault" {
+		if c.Secrets.VaultAddr == "" {
+			add("VAULT_ADDR: must not be empty when SECRETS_PROVIDER=vault")
+		}
+		if c.Secrets.VaultToken == "" {
+			add("VAULT_TOKEN: must not be empty when SECRETS_PROVIDER=vault")
+		}
+	}
+	if c.Secrets.RefreshInterval < 0 {
+		add("SECRETS_REFRESH_INTERVAL: must not be negative, got %s", c.Secrets.RefreshInterval)
+	}
+
+	// storage & avatar
+	switch c.Storage.Provider {
+	case "local":
+		if c.Storage.Dir == "" {
+			add("STORAGE_DIR: must not be empty when STORAGE_PROVIDER=local")
+		}
+	case "s3":
+		if c.Storage.S3Bucket == "" {
+			add("S3_BUCKET: must not be empty when STORAGE_PROVIDER=s3")
+		}
+		if c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			add("S3_ACCESS_KEY and S3_SECRET_KEY: must not be empty when STORAGE_PROVIDER=s3")
+		}
+		if u, err := url.Parse(c.Storage.S3Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("S3_ENDPOINT: %q must be an http(s) URL", c.Storage.S3Endpoint)
+		}
+	default:
+		add("STORAGE_PROVIDER: %q must be one of local, s3", c.Storage.Provider)
+	}
+	if c.Avatar.MaxBytes < 1 {
+		add("AVATAR_MAX_BYTES: must be at least 1, got %d", c.Avatar.MaxBytes)
+	}
+	if len(c.Avatar.Sizes) == 0 {
+		add("AVATAR_SIZES: must contain at least one size")
+	}
+	if c.Avatar.FetchTimeout <= 0 {
+		add("AVATAR_FETCH_TIMEOUT: must be positive, got %s", c.Avatar.FetchTimeout)
+	}
+	for _, size := range c.Avatar.Sizes {
+		if size < 16 || size > 1024 {
+			add("AVATAR_SIZES: %d must be between 16 and 1024", size)
+		}
+	}
+
+	// mail & verifikasi email
+	switch c.Mail.Provider {
+	case "log":
+	case "file":
+		if c.Mail.Dir == "" {
+			add("MAIL_DIR: must not be empty when MAIL_PROVIDER=file")
+		}
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			add("SMTP_HOST: must not be empty when MAIL_PROVIDER=smtp")
+		}
+		if err := validatePort(c.Mail.SMTPPort); err != nil {
+			add("SMTP_PORT: %v", err)
+		}
+	default:
+		add("MAIL_PROVIDER: %q must be one of log, file, smtp", c.Mail.Provider)
+	}
+	if _, err := netmail.ParseAddress(c.Mail.From); err != nil {
+		add("MAIL_FROM: %q is not a valid address", c.Mail.From)
+	}
+	if !slices.Contains(constants.VerificationPolicies, c.Verification.Policy) {
+		add("EMAIL_VERIFICATION_POLICY: %q must be one of %s", c.Verification.Policy, strings.Join(constants.VerificationPolicies, ", "))
+	}
+	if u, err := url.Parse(c.Verification.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("EMAIL_VERIFICATION_URL: %q must be an http(s) URL", c.Verification.URL)
+	}
+	if c.Verification.TokenTTL <= 0 {
+		add("EMAIL_VERIFICATION_TOKEN_TTL: must be positive, got %s", c.Verification.TokenTTL)
+	}
+	for _, v := range []struct{ env, url string }{
+		{"EMAIL_CHANGE_CONFIRM_URL", c.EmailChange.ConfirmURL},
+		{"EMAIL_CHANGE_REVERT_URL", c.EmailChange.RevertURL},
+	} {
+		if u, err := url.Parse(v.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("%s: %q must be an http(s) URL", v.env, v.url)
+		}
+	}
+	if c.EmailChange.TokenTTL <= 0 {
+		add("EMAIL_CHANGE_TOKEN_TTL: must be positive, got %s", c.EmailChange.TokenTTL)
+	}
+	if c.EmailChange.RevertTTL <= 0 {
+		add("EMAIL_CHANGE_REVERT_TTL: must be positive, got %s", c.EmailChange.RevertTTL)
+	}
+
+	// nomor telepon & sms
+	if c.User.PhoneRegion != "" && !phone.IsRegion(c.User.PhoneRegion) {
+		add("PHONE_DEFAULT_REGION: %q must be empty or one of %s", c.User.PhoneRegion, strings.Join(phone.Regions(), ", "))
+	}
+	switch c.SMS.Provider {
+	case "log", "none":
+	default:
+		add("SMS_PROVIDER: %q must be one of log, none", c.SMS.Provider)
+	}
+	if c.PhoneOTP.TTL <= 0 {
+		add("PHONE_OTP_TTL: must be positive, got %s", c.PhoneOTP.TTL)
+	}
+	if c.PhoneOTP.MaxAttempts < 1 {
+		add("PHONE_OTP_MAX_ATTEMPTS: must be at least 1, got %d", c.PhoneOTP.MaxAttempts)
+	}
+	if c.PhoneOTP.ResendInterval < 0 || c.PhoneOTP.ResendInterval > c.PhoneOTP.TTL {
+		add("PHONE_OTP_RESEND_INTERVAL: must be between 0 and PHONE_OTP_TTL (%s), got %s", c.PhoneOTP.TTL, c.PhoneOTP.ResendInterval)
+	}
+	if c.PhoneOTP.MaxSends < 1 {
+		add("PHONE_OTP_MAX_SENDS: must be at least 1, got %d", c.PhoneOTP.MaxSends)
+	}
+	if c.PhoneOTP.SendWindow <= 0 {
+		add("PHONE_OTP_SEND_WINDOW: must be positive, got %s", c.PhoneOTP.SendWindow)
+	}
+
+	// password
+	if u, err := url.Parse(c.Password.ResetURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("PASSWORD_RESET_URL: %q must be an http(s) URL", c.Password.ResetURL)
+	}
+	if c.Password.ResetTokenTTL <= 0 {
+		add("PASSWORD_RESET_TOKEN_TTL: must be positive, got %s", c.Password.ResetTokenTTL)
+	}
+	if c.Password.HashCost < bcrypt.MinCost || c.Password.HashCost > bcrypt.MaxCost {
+		add("PASSWORD_HASH_COST: must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.Password.HashCost)
+	}
+
+	// khusus production
+	if c.IsProduction() {
+		if c.DB.Password == "" && c.DB.URL == "" {
+			add("DB_PASSWORD: must not be empty in production")
+		}
+		if c.Admin.Token != "" && len(c.Admin.Token) < 32 {
+			add("ADMIN_TOKEN: must be at least 32 characters in production")
+		}
+		if c.Tracing.Exporter == "stdout" {
+			add("OTEL_TRACES_EXPORTER: stdout exporter is not allowed in production")
+		}
+		// ganti email dan reset password selalu butuh email terkirim, apa pun policy verifikasinya
+		if c.Mail.Provider != "smtp" {
+			add("MAIL_PROVIDER: %s mailer does not deliver email, use smtp in production", c.Mail.Provider)
+		}
+		// log sender menulis kode OTP ke log
+		if c.SMS.Provider == "log" {
+			add("SMS_PROVIDER: log sender does not deliver sms and logs the codes, use none in production until a provider is configured")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("%q is not a valid port (1-65535)", port)
+	}
+	return nil
+}
+
+func validateHostPort(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid host:port", addr)
+	}
+	if host == "" {
+		return fmt.Errorf("%q is missing a host", addr)
+	}
+	if err := validatePort(port); err != nil {
+		return fmt.Errorf("%q: %v", addr, err)
+	}
+	return nil
+}
+
+func validateReadableFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	newTestEnv(t)
+	tests := []struct {
+		env, value string
+		problem    string
+	}{
+		{"APP_PORT", "0", `APP_PORT: "0" is not a valid port (1-65535)`},
+		{"LOG_LEVEL", "loud", `LOG_LEVEL: "loud" is not a valid level (trace, debug, info, warn, error)`},
+		{"DB_SSLMODE", "bogus", `DB_SSLMODE: "bogus" must be one of disable, allow, prefer, require, verify-ca, verify-full`},
+		{"DB_MIN_CONNS", "-1", "DB_MIN_CONNS: must be between 0 and DB_MAX_OPEN_CONNS (25), got -1"},
+		// nilai yang tidak bisa di-parse dilaporkan bersama masalah lain, lalu default yang dipakai
+		{"DB_CONN_MAX_LIFETIME", "soon", `DB_CONN_MAX_LIFETIME: "soon" is not a valid duration (seconds or e.g. 5m) (from env:DB_CONN_MAX_LIFETIME)`},
+		{"REDIS_ADDR", "redis", `REDIS_ADDR: "redis" is not a valid host:port`},
+		{"OTEL_TRACES_SAMPLER_ARG", "2", "OTEL_TRACES_SAMPLER_ARG: must be between 0 and 1, got 2"},
+		{"AVATAR_SIZES", "8", "AVATAR_SIZES: 8 must be between 16 and 1024"},
+		{"PASSWORD_HASH_COST", "99", "PASSWORD_HASH_COST: must be between 4 and 31, got 99"},
+	}
+	for _, tt := range tests {
+		t.Setenv(tt.env, tt.value)
+	}
+
+	err := load(t).Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate = %v, want *ValidationError", err)
+	}
+	for _, tt := range tests {
+		if !slices.Contains(verr.Problems, tt.problem) {
+			t.Errorf("%s=%s: problem %q is missing", tt.env, tt.value, tt.problem)
+		}
+	}
+	if len(verr.Problems) != len(tests) {
+		t.Errorf("got %d problems, want %d:\n%s", len(verr.Problems), len(tests), verr)
+	}
+	if !strings.HasPrefix(verr.Error(), "invalid configuration (9 problems):\n  - ") {
+		t.Errorf("Error() = %q", verr.Error())
+	}
+}
+
+func TestValidateProductionRules(t *testing.T) {
+	dir := newTestEnv(t)
+	privateKey := filepath.Join(dir, "private.pem")
+	publicKey := filepath.Join(dir, "public.pem")
+	writeFile(t, privateKey, "private")
+	writeFile(t, publicKey, "public")
+
+	// konfigurasi yang lolos semua aturan production
+	ready := map[string]string{
+		"DB_PASSWORD":          "password",
+		"ADMIN_TOKEN":          strings.Repeat("a", 32),
+		"JWT_PRIVATE_KEY_PATH": privateKey,
+		"JWT_PUBLIC_KEY_PATH":  publicKey,
+		"MAIL_PROVIDER":        "smtp",
+		"SMTP_HOST":            "smtp.example.com",
+		"SMS_PROVIDER":         "none",
+		"OTEL_TRACES_EXPORTER": "none",
+	}
+	tests := []struct {
+		name     string
+		env, val string
+		problem  string
+	}{
+		{"ready", "", "", ""},
+		{"empty database password", "DB_PASSWORD", "", "DB_PASSWORD: must not be empty in production"},
+		{"short admin token", "ADMIN_TOKEN", "short", "ADMIN_TOKEN: must be at least 32 characters in production"},
+		{"stdout tracing", "OTEL_TRACES_EXPORTER", "stdout", "OTEL_TRACES_EXPORTER: stdout exporter is not allowed in production"},
+		{"log mailer", "MAIL_PROVIDER", "log", "MAIL_PROVIDER: log mailer does not deliver email, use smtp in production"},
+		{"log sms sender", "SMS_PROVIDER", "log", "SMS_PROVIDER: log sender does not deliver sms and logs the codes, use none in production until a provider is configured"},
+		{"missing jwt key", "JWT_PUBLIC_KEY_PATH", filepath.Join(dir, "missing.pem"),
+			"JWT_PUBLIC_KEY_PATH: open " + filepath.Join(dir, "missing.pem") + ": no such file or directory"},
+	}
+	for _, tt := range tests {
+		for _, appEnv := range []string{"staging", "production"} {
+			t.Run(tt.name+"/"+appEnv, func(t *testing.T) {
+				for k, v := range ready {
+					t.Setenv(k, v)
+				}
+				if tt.env != "" {
+					t.Setenv(tt.env, tt.val)
+				}
+				t.Setenv("APP_ENV", appEnv)
+
+				err := load(t).Validate()
+				if tt.problem == "" || appEnv != "production" {
+					if err != nil {
+						t.Errorf("Validate = %v, want nil", err)
+					}
+					return
+				}
+				var verr *ValidationError
+				if !errors.As(err, &verr) || !slices.Equal(verr.Problems, []string{tt.problem}) {
+					t.Errorf("Validate = %v, want only %q", err, tt.problem)
+				}
+			})
+		}
+	}
+}
@@ -18,3 +18,25 @@ const (
 	SqlNoRows        = "Record not found"
 	SqlAlreadyExists = "already exists"
 )
+
+// Role, harus sama dengan constraint valid_role di tabel users
+const (
+	RoleUser        = "user"
+	RoleSuperadmin  = "superadmin"
+	RoleTenantAdmin = "tenant_admin"
+	RoleTenantStaff = "tenant_staff"
+)
+
+var Roles = []string{RoleUser, RoleSuperadmin, RoleTenantAdmin, RoleTenantStaff}
+
+// Kebijakan verifikasi email (EMAIL_VERIFICATION_POLICY)
+const (
+	// VerificationOff: email verifikasi tidak dikirim
+	VerificationOff = "off"
+	// VerificationOptional: email verifikasi dikirim, user yang belum verifikasi tidak dibatasi
+	VerificationOptional = "optional"
+	// VerificationRequired: user yang belum verifikasi tidak bisa upload avatar atau mendapat access token
+	VerificationRequired = "required"
+)
+
+var VerificationPolicies = []string{VerificationOff, VerificationOptional, VerificationRequired}
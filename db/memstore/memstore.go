@@ -0,0 +1,1052 @@
+// Package memstore adalah implementasi db.Store di memori untuk test service dan handler tanpa Postgres.
+//
+// Semantiknya mengikuti query di db/queries dan constraint di db/migrations:
+// email kanonik (helper.CanonicalEmail) unik di antara user yang belum dihapus, role dibatasi constraint valid_role,
+// query baca mengabaikan user yang sudah dihapus, dan ListUsers memakai LIKE case-insensitive.
+// Token di user_tokens hanya bisa dipakai sekali dan tidak setelah expires_at, begitu juga token di email_changes
+// dan kode di phone_otps.
+// Error dikembalikan dalam bentuk yang sama dengan pgx (pgx.ErrNoRows dan *pgconn.PgError),
+// jadi kode yang memeriksa error tidak perlu tahu store mana yang dipakai.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"user-service/constants"
+	db "user-service/db/sqlc"
+	"user-service/pkg/helper"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SQLSTATE selain unique_violation yang bisa dihasilkan oleh schema.
+const (
+	notNullViolation          = "23502"
+	foreignKeyViolation       = "23503"
+	checkViolation            = "23514"
+	stringDataRightTruncated  = "22001"
+	invalidTextRepresentation = "22P02"
+	invalidRowCountInLimit    = "2201W"
+	invalidRowCountInOffset   = "2201X"
+)
+
+// Store menyimpan tabel users, user_metadata, user_tokens, email_changes, phone_otps dan phone_otp_sends di memori.
+// Aman dipakai dari banyak goroutine.
+type Store struct {
+	mu           sync.Mutex
+	users        []db.User
+	metadata     []db.UserMetadatum
+	tokens       []db.UserToken
+	emailChanges []db.EmailChange
+	phoneOTPs    []db.PhoneOtp
+	otpSends     []db.PhoneOtpSend
+	faults       map[string]error
+	now          func() time.Time
+	newID        func() uuid.UUID
+}
+
+var _ db.Store = (*Store)(nil)
+
+// New membuat Store kosong.
+func New() *Store {
+	return &Store{
+		faults: map[string]error{},
+		now:    time.Now,
+		newID:  uuid.New,
+	}
+}
+
+// Fail membuat setiap panggilan query bernama (nama sqlc, misalnya "CreateUserMetadata")
+// mengembalikan err, untuk mensimulasikan kegagalan database. err nil menghapus kegagalan tersebut.
+func (s *Store) Fail(query string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		delete(s.faults, query)
+		return
+	}
+	s.faults[query] = err
+}
+
+// SetClock mengganti sumber waktu untuk created_at, updated_at dan deleted_at.
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// SetIDGenerator mengganti pembuat ID user (default uuid.New), misalnya untuk golden test.
+func (s *Store) SetIDGenerator(newID func() uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.newID = newID
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createUser(ctx, arg)
+}
+
+func (s *Store) CreateUserMetadata(ctx context.Context, arg db.CreateUserMetadataParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createUserMetadata(ctx, arg)
+}
+
+func (s *Store) ClaimPhoneOTPAttempt(ctx context.Context, arg db.ClaimPhoneOTPAttemptParams) (db.PhoneOtp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "ClaimPhoneOTPAttempt"); err != nil {
+		return db.PhoneOtp{}, err
+	}
+	now := s.timestamp()
+	i := slices.IndexFunc(s.phoneOTPs, func(o db.PhoneOtp) bool {
+		return o.UserID == arg.UserID && !o.UsedAt.Valid && o.ExpiresAt.Time.After(now.Time) && o.Attempts < arg.MaxAttempts
+	})
+	if i < 0 {
+		return db.PhoneOtp{}, pgx.ErrNoRows
+	}
+	s.phoneOTPs[i].Attempts++
+	return s.phoneOTPs[i], nil
+}
+
+func (s *Store) ConfirmEmailChange(ctx context.Context, arg db.ConfirmEmailChangeParams) (db.EmailChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.confirmEmailChange(ctx, arg)
+}
+
+func (s *Store) ConsumePhoneOTP(ctx context.Context, id uuid.UUID) (db.PhoneOtp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.consumePhoneOTP(ctx, id)
+}
+
+func (s *Store) ConsumeUserToken(ctx context.Context, arg db.ConsumeUserTokenParams) (db.UserToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.consumeUserToken(ctx, arg)
+}
+
+func (s *Store) CreateEmailChange(ctx context.Context, arg db.CreateEmailChangeParams) (db.EmailChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "CreateEmailChange"); err != nil {
+		return db.EmailChange{}, err
+	}
+	for _, v := range []string{arg.OldEmail, arg.NewEmail} {
+		if len([]rune(v)) > 255 {
+			return db.EmailChange{}, pgError(stringDataRightTruncated, "value too long for type character varying(255)", "email_changes", "")
+		}
+	}
+	if !arg.ExpiresAt.Valid {
+		return db.EmailChange{}, pgError(notNullViolation,
+			`null value in column "expires_at" of relation "email_changes" violates not-null constraint`, "email_changes", "")
+	}
+	if !slices.ContainsFunc(s.users, func(u db.User) bool { return u.ID == arg.UserID }) {
+		return db.EmailChange{}, pgError(foreignKeyViolation,
+			`insert or update on table "email_changes" violates foreign key constraint "email_changes_user_id_fkey"`,
+			"email_changes", "email_changes_user_id_fkey")
+	}
+	for _, c := range s.emailChanges {
+		if bytes.Equal(c.ConfirmTokenHash, arg.ConfirmTokenHash) {
+			return db.EmailChange{}, pgError(db.UniqueViolation,
+				`duplicate key value violates unique constraint "email_changes_confirm_token_hash_key"`,
+				"email_changes", "email_changes_confirm_token_hash_key")
+		}
+		// unique index parsial idx_email_changes_pending
+		if c.UserID == arg.UserID && !c.ConfirmedAt.Valid {
+			return db.EmailChange{}, pgError(db.UniqueViolation,
+				`duplicate key value violates unique constraint "idx_email_changes_pending"`,
+				"email_changes", "idx_email_changes_pending")
+		}
+	}
+
+	change := db.EmailChange{
+		ID:               uuid.New(),
+		UserID:           arg.UserID,
+		OldEmail:         arg.OldEmail,
+		NewEmail:         arg.NewEmail,
+		ConfirmTokenHash: slices.Clone(arg.ConfirmTokenHash),
+		ExpiresAt:        arg.ExpiresAt,
+		CreatedAt:        s.timestamp(),
+	}
+	s.emailChanges = append(s.emailChanges, change)
+	return change, nil
+}
+
+func (s *Store) CreatePhoneOTP(ctx context.Context, arg db.CreatePhoneOTPParams) (db.PhoneOtp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "CreatePhoneOTP"); err != nil {
+		return db.PhoneOtp{}, err
+	}
+	if len([]rune(arg.PhoneNumber)) > 20 {
+		return db.PhoneOtp{}, pgError(stringDataRightTruncated, "value too long for type character varying(20)", "phone_otps", "")
+	}
+	if arg.CodeHash == nil || !arg.ExpiresAt.Valid {
+		return db.PhoneOtp{}, pgError(notNullViolation,
+			`null value in column of relation "phone_otps" violates not-null constraint`, "phone_otps", "")
+	}
+	if !slices.ContainsFunc(s.users, func(u db.User) bool { return u.ID == arg.UserID }) {
+		return db.PhoneOtp{}, pgError(foreignKeyViolation,
+			`insert or update on table "phone_otps" violates foreign key constraint "phone_otps_user_id_fkey"`,
+			"phone_otps", "phone_otps_user_id_fkey")
+	}
+	// unique index parsial idx_phone_otps_pending
+	if slices.ContainsFunc(s.phoneOTPs, func(o db.PhoneOtp) bool { return o.UserID == arg.UserID && !o.UsedAt.Valid }) {
+		return db.PhoneOtp{}, pgError(db.UniqueViolation,
+			`duplicate key value violates unique constraint "idx_phone_otps_pending"`, "phone_otps", "idx_phone_otps_pending")
+	}
+
+	otp := db.PhoneOtp{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		PhoneNumber: arg.PhoneNumber,
+		CodeHash:    slices.Clone(arg.CodeHash),
+		ExpiresAt:   arg.ExpiresAt,
+		CreatedAt:   s.timestamp(),
+	}
+	s.phoneOTPs = append(s.phoneOTPs, otp)
+	return otp, nil
+}
+
+func (s *Store) CreatePhoneOTPSend(ctx context.Context, arg db.CreatePhoneOTPSendParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "CreatePhoneOTPSend"); err != nil {
+		return err
+	}
+	if len([]rune(arg.PhoneNumber)) > 20 {
+		return pgError(stringDataRightTruncated, "value too long for type character varying(20)", "phone_otp_sends", "")
+	}
+	if !arg.SentAt.Valid {
+		return pgError(notNullViolation,
+			`null value in column "sent_at" of relation "phone_otp_sends" violates not-null constraint`, "phone_otp_sends", "")
+	}
+	if !slices.ContainsFunc(s.users, func(u db.User) bool { return u.ID == arg.UserID }) {
+		return pgError(foreignKeyViolation,
+			`insert or update on table "phone_otp_sends" violates foreign key constraint "phone_otp_sends_user_id_fkey"`,
+			"phone_otp_sends", "phone_otp_sends_user_id_fkey")
+	}
+	s.otpSends = append(s.otpSends, db.PhoneOtpSend{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		PhoneNumber: arg.PhoneNumber,
+		SentAt:      arg.SentAt,
+	})
+	return nil
+}
+
+func (s *Store) CreateUserToken(ctx context.Context, arg db.CreateUserTokenParams) (db.UserToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "CreateUserToken"); err != nil {
+		return db.UserToken{}, err
+	}
+	if len([]rune(arg.Purpose)) > 32 {
+		return db.UserToken{}, pgError(stringDataRightTruncated, "value too long for type character varying(32)", "user_tokens", "")
+	}
+	if !slices.Contains(tokenPurposes, arg.Purpose) {
+		return db.UserToken{}, pgError(checkViolation,
+			`new row for relation "user_tokens" violates check constraint "valid_purpose"`, "user_tokens", "valid_purpose")
+	}
+	if !arg.ExpiresAt.Valid {
+		return db.UserToken{}, pgError(notNullViolation,
+			`null value in column "expires_at" of relation "user_tokens" violates not-null constraint`, "user_tokens", "")
+	}
+	if !slices.ContainsFunc(s.users, func(u db.User) bool { return u.ID == arg.UserID }) {
+		return db.UserToken{}, pgError(foreignKeyViolation,
+			`insert or update on table "user_tokens" violates foreign key constraint "user_tokens_user_id_fkey"`,
+			"user_tokens", "user_tokens_user_id_fkey")
+	}
+	for _, t := range s.tokens {
+		if bytes.Equal(t.TokenHash, arg.TokenHash) {
+			return db.UserToken{}, pgError(db.UniqueViolation,
+				`duplicate key value violates unique constraint "user_tokens_token_hash_key"`, "user_tokens", "user_tokens_token_hash_key")
+		}
+	}
+
+	token := db.UserToken{
+		// bukan s.newID, supaya urutan ID user di golden test tidak bergeser
+		ID:        uuid.New(),
+		UserID:    arg.UserID,
+		Purpose:   arg.Purpose,
+		TokenHash: slices.Clone(arg.TokenHash),
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: s.timestamp(),
+	}
+	s.tokens = append(s.tokens, token)
+	return token, nil
+}
+
+func (s *Store) DeletePendingEmailChanges(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "DeletePendingEmailChanges"); err != nil {
+		return 0, err
+	}
+	before := len(s.emailChanges)
+	s.emailChanges = slices.DeleteFunc(s.emailChanges, func(c db.EmailChange) bool {
+		return c.UserID == userID && !c.ConfirmedAt.Valid
+	})
+	return int64(before - len(s.emailChanges)), nil
+}
+
+func (s *Store) DeletePhoneOTPs(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "DeletePhoneOTPs"); err != nil {
+		return 0, err
+	}
+	before := len(s.phoneOTPs)
+	s.phoneOTPs = slices.DeleteFunc(s.phoneOTPs, func(o db.PhoneOtp) bool {
+		return o.UserID == userID && !o.UsedAt.Valid
+	})
+	return int64(before - len(s.phoneOTPs)), nil
+}
+
+func (s *Store) DeleteUserTokens(ctx context.Context, arg db.DeleteUserTokensParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deleteUserTokens(ctx, arg)
+}
+
+func (s *Store) GetPendingPhoneOTP(ctx context.Context, userID uuid.UUID) (db.PhoneOtp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "GetPendingPhoneOTP"); err != nil {
+		return db.PhoneOtp{}, err
+	}
+	for _, o := range s.phoneOTPs {
+		if o.UserID == userID && !o.UsedAt.Valid {
+			return o, nil
+		}
+	}
+	return db.PhoneOtp{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "GetUserByEmail"); err != nil {
+		return db.User{}, err
+	}
+	for _, u := range s.users {
+		if u.EmailCanonical == helper.CanonicalEmail(email) && !u.DeletedAt.Valid {
+			return u, nil
+		}
+	}
+	return db.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "GetUserByID"); err != nil {
+		return db.User{}, err
+	}
+	if i := s.activeUser(id); i >= 0 {
+		return s.users[i], nil
+	}
+	return db.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserMetadata(ctx context.Context, userID uuid.UUID) (db.UserMetadatum, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "GetUserMetadata"); err != nil {
+		return db.UserMetadatum{}, err
+	}
+	// tidak ada filter deleted_at, sama seperti query aslinya
+	for _, m := range s.metadata {
+		if m.UserID == userID {
+			m.Metadata = slices.Clone(m.Metadata)
+			return m, nil
+		}
+	}
+	return db.UserMetadatum{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserToken(ctx context.Context, arg db.GetUserTokenParams) (db.UserToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "GetUserToken"); err != nil {
+		return db.UserToken{}, err
+	}
+	now := s.timestamp()
+	for _, t := range s.tokens {
+		if bytes.Equal(t.TokenHash, arg.TokenHash) && t.Purpose == arg.Purpose &&
+			!t.UsedAt.Valid && t.ExpiresAt.Time.After(now.Time) {
+			return t, nil
+		}
+	}
+	return db.UserToken{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserWithMetadata(ctx context.Context, id uuid.UUID) (db.GetUserWithMetadataRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "GetUserWithMetadata"); err != nil {
+		return db.GetUserWithMetadataRow{}, err
+	}
+	i := s.activeUser(id)
+	if i < 0 {
+		return db.GetUserWithMetadataRow{}, pgx.ErrNoRows
+	}
+	u := s.users[i]
+	row := db.GetUserWithMetadataRow{
+		Email:         u.Email,
+		FullName:      u.FullName,
+		PhoneNumber:   u.PhoneNumber,
+		Role:          u.Role,
+		AvatarUrl:     u.AvatarUrl,
+		UserCreatedAt: u.CreatedAt,
+		UserUpdatedAt: u.UpdatedAt,
+		DeletedAt:     u.DeletedAt,
+	}
+	// LEFT JOIN: user tanpa metadata tetap dikembalikan dengan kolom metadata NULL
+	for _, m := range s.metadata {
+		if m.UserID == id {
+			row.Metadata = slices.Clone(m.Metadata)
+			row.MetadataCreatedAt = m.CreatedAt
+			break
+		}
+	}
+	return row, nil
+}
+
+func (s *Store) ListPhoneOTPSends(ctx context.Context, arg db.ListPhoneOTPSendsParams) ([]pgtype.Timestamptz, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "ListPhoneOTPSends"); err != nil {
+		return nil, err
+	}
+	var sent []pgtype.Timestamptz
+	for _, o := range s.otpSends {
+		// sent_at > NULL tidak pernah benar
+		if o.PhoneNumber == arg.PhoneNumber && arg.Since.Valid && o.SentAt.Time.After(arg.Since.Time) {
+			sent = append(sent, o.SentAt)
+		}
+	}
+	slices.SortFunc(sent, func(a, b pgtype.Timestamptz) int { return a.Time.Compare(b.Time) })
+	return sent, nil
+}
+
+func (s *Store) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "ListUsers"); err != nil {
+		return nil, err
+	}
+	if arg.Limit < 0 {
+		return nil, pgError(invalidRowCountInLimit, "LIMIT must not be negative", "", "")
+	}
+	if arg.Offset < 0 {
+		return nil, pgError(invalidRowCountInOffset, "OFFSET must not be negative", "", "")
+	}
+	// '%' || NULL || '%' bernilai NULL, jadi search NULL tidak mencocokkan apa pun
+	if !arg.Search.Valid {
+		return nil, nil
+	}
+
+	pattern := "%" + strings.ToLower(arg.Search.String) + "%"
+	var matched []db.User
+	for _, u := range s.users {
+		if u.DeletedAt.Valid {
+			continue
+		}
+		if like(strings.ToLower(u.Email), pattern) ||
+			(u.FullName.Valid && like(strings.ToLower(u.FullName.String), pattern)) {
+			matched = append(matched, u)
+		}
+	}
+
+	// ORDER BY created_at DESC; untuk created_at yang sama, yang dibuat belakangan lebih dulu
+	slices.Reverse(matched)
+	slices.SortStableFunc(matched, func(a, b db.User) int {
+		return b.CreatedAt.Time.Compare(a.CreatedAt.Time)
+	})
+
+	if int(arg.Offset) >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[arg.Offset:]
+	if int(arg.Limit) < len(matched) {
+		matched = matched[:arg.Limit]
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+	return matched, nil
+}
+
+func (s *Store) MarkEmailVerified(ctx context.Context, id uuid.UUID) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.markEmailVerified(ctx, id)
+}
+
+func (s *Store) MarkPhoneVerified(ctx context.Context, arg db.MarkPhoneVerifiedParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.markPhoneVerified(ctx, arg)
+}
+
+func (s *Store) ResetUserPassword(ctx context.Context, arg db.ResetUserPasswordParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resetUserPassword(ctx, arg)
+}
+
+func (s *Store) RevertEmailChange(ctx context.Context, revertTokenHash []byte) (db.EmailChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revertEmailChange(ctx, revertTokenHash)
+}
+
+func (s *Store) SoftDeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "SoftDeleteUser"); err != nil {
+		return 0, err
+	}
+	i := s.activeUser(id)
+	if i < 0 {
+		return 0, nil
+	}
+	now := s.timestamp()
+	s.users[i].DeletedAt = now
+	s.users[i].UpdatedAt = now
+	return 1, nil
+}
+
+func (s *Store) UpdateUserAvatar(ctx context.Context, arg db.UpdateUserAvatarParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "UpdateUserAvatar"); err != nil {
+		return db.User{}, err
+	}
+	i := s.activeUser(arg.ID)
+	if i < 0 {
+		return db.User{}, pgx.ErrNoRows
+	}
+	s.users[i].AvatarUrl = arg.AvatarUrl
+	s.users[i].UpdatedAt = s.timestamp()
+	return s.users[i], nil
+}
+
+func (s *Store) UpdateUserEmail(ctx context.Context, arg db.UpdateUserEmailParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUserEmail(ctx, arg)
+}
+
+func (s *Store) UpdateUserRole(ctx context.Context, arg db.UpdateUserRoleParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.begin(ctx, "UpdateUserRole"); err != nil {
+		return db.User{}, err
+	}
+	i := s.activeUser(arg.ID)
+	if i < 0 {
+		return db.User{}, pgx.ErrNoRows
+	}
+	if err := checkRole(arg.Role); err != nil {
+		return db.User{}, err
+	}
+	s.users[i].Role = arg.Role
+	s.users[i].UpdatedAt = s.timestamp()
+	return s.users[i], nil
+}
+
+// CreateUserWithMetadata berjalan atomik seperti transaksi: jika insert metadata gagal, user ikut batal dibuat.
+func (s *Store) CreateUserWithMetadata(ctx context.Context, arg db.CreateuserWithMetadataParams) (db.CreateUserTxResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result db.CreateUserTxResult
+	user, err := s.createUser(ctx, arg.CreateUserParams)
+	if err != nil {
+		return result, err
+	}
+
+	jsonMeta, err := json.Marshal(arg.UserMetadata)
+	if err == nil {
+		err = s.createUserMetadata(ctx, db.CreateUserMetadataParams{
+			UserID:   user.ID,
+			Metadata: jsonMeta,
+		})
+	}
+	if err != nil {
+		s.users = s.users[:len(s.users)-1]
+		return result, err
+	}
+
+	result.User = user
+	return result, nil
+}
+
+// VerifyEmail berjalan atomik seperti transaksi: jika user sudah dihapus, token tidak ikut terpakai.
+func (s *Store) VerifyEmail(ctx context.Context, tokenHash []byte) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.consumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: tokenHash, Purpose: db.TokenPurposeVerifyEmail})
+	if err != nil {
+		return db.User{}, err
+	}
+	user, err := s.markEmailVerified(ctx, token.UserID)
+	if err != nil {
+		i := slices.IndexFunc(s.tokens, func(t db.UserToken) bool { return t.ID == token.ID })
+		s.tokens[i].UsedAt = pgtype.Timestamptz{}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+// ApplyEmailChange berjalan atomik seperti transaksi: jika email baru sudah dipakai atau email user
+// sudah berubah, token konfirmasi tidak ikut terpakai.
+func (s *Store) ApplyEmailChange(ctx context.Context, arg db.ConfirmEmailChangeParams) (db.EmailChangeTxResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result db.EmailChangeTxResult
+	err := s.tx(func() error {
+		var err error
+		result.Change, err = s.confirmEmailChange(ctx, arg)
+		if err != nil {
+			return err
+		}
+		result.User, err = s.swapEmail(ctx, result.Change.UserID, result.Change.OldEmail, result.Change.NewEmail)
+		return err
+	})
+	if err != nil {
+		return db.EmailChangeTxResult{}, err
+	}
+	return result, nil
+}
+
+// UndoEmailChange berjalan atomik seperti ApplyEmailChange.
+func (s *Store) UndoEmailChange(ctx context.Context, revertTokenHash []byte) (db.EmailChangeTxResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result db.EmailChangeTxResult
+	err := s.tx(func() error {
+		var err error
+		result.Change, err = s.revertEmailChange(ctx, revertTokenHash)
+		if err != nil {
+			return err
+		}
+		result.User, err = s.swapEmail(ctx, result.Change.UserID, result.Change.NewEmail, result.Change.OldEmail)
+		return err
+	})
+	if err != nil {
+		return db.EmailChangeTxResult{}, err
+	}
+	return result, nil
+}
+
+// VerifyPhone berjalan atomik seperti transaksi: jika nomor telepon user sudah berubah atau user sudah dihapus,
+// kode tidak ikut terpakai.
+func (s *Store) VerifyPhone(ctx context.Context, otpID uuid.UUID) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var user db.User
+	err := s.tx(func() error {
+		otp, err := s.consumePhoneOTP(ctx, otpID)
+		if err != nil {
+			return err
+		}
+		user, err = s.markPhoneVerified(ctx, db.MarkPhoneVerifiedParams{
+			ID:          otp.UserID,
+			PhoneNumber: pgtype.Text{String: otp.PhoneNumber, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+// ResetPassword berjalan atomik seperti transaksi: jika user sudah dihapus, token tidak ikut terpakai.
+func (s *Store) ResetPassword(ctx context.Context, tokenHash []byte, passwordHash string) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var user db.User
+	err := s.tx(func() error {
+		token, err := s.consumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: tokenHash, Purpose: db.TokenPurposeResetPassword})
+		if err != nil {
+			return err
+		}
+		user, err = s.resetUserPassword(ctx, db.ResetUserPasswordParams{
+			ID:           token.UserID,
+			PasswordHash: pgtype.Text{String: passwordHash, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = s.deleteUserTokens(ctx, db.DeleteUserTokensParams{UserID: user.ID, Purpose: db.TokenPurposeResetPassword})
+		return err
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (s *Store) swapEmail(ctx context.Context, id uuid.UUID, from, to string) (db.User, error) {
+	user, err := s.updateUserEmail(ctx, db.UpdateUserEmailParams{NewEmail: to, ID: id, OldEmail: from})
+	if err != nil {
+		return db.User{}, err
+	}
+	for _, purpose := range []string{db.TokenPurposeVerifyEmail, db.TokenPurposeResetPassword} {
+		if _, err := s.deleteUserTokens(ctx, db.DeleteUserTokensParams{UserID: id, Purpose: purpose}); err != nil {
+			return db.User{}, err
+		}
+	}
+	return user, nil
+}
+
+func (s *Store) confirmEmailChange(ctx context.Context, arg db.ConfirmEmailChangeParams) (db.EmailChange, error) {
+	if err := s.begin(ctx, "ConfirmEmailChange"); err != nil {
+		return db.EmailChange{}, err
+	}
+	now := s.timestamp()
+	i := slices.IndexFunc(s.emailChanges, func(c db.EmailChange) bool {
+		return bytes.Equal(c.ConfirmTokenHash, arg.ConfirmTokenHash) && !c.ConfirmedAt.Valid && c.ExpiresAt.Time.After(now.Time)
+	})
+	if i < 0 {
+		return db.EmailChange{}, pgx.ErrNoRows
+	}
+	if arg.RevertTokenHash != nil && slices.ContainsFunc(s.emailChanges, func(c db.EmailChange) bool {
+		return bytes.Equal(c.RevertTokenHash, arg.RevertTokenHash)
+	}) {
+		return db.EmailChange{}, pgError(db.UniqueViolation,
+			`duplicate key value violates unique constraint "email_changes_revert_token_hash_key"`,
+			"email_changes", "email_changes_revert_token_hash_key")
+	}
+	s.emailChanges[i].ConfirmedAt = now
+	s.emailChanges[i].RevertTokenHash = slices.Clone(arg.RevertTokenHash)
+	s.emailChanges[i].RevertExpiresAt = arg.RevertExpiresAt
+	return s.emailChanges[i], nil
+}
+
+func (s *Store) revertEmailChange(ctx context.Context, revertTokenHash []byte) (db.EmailChange, error) {
+	if err := s.begin(ctx, "RevertEmailChange"); err != nil {
+		return db.EmailChange{}, err
+	}
+	now := s.timestamp()
+	// revert_token_hash NULL tidak pernah cocok, sama seperti NULL = NULL di SQL
+	i := slices.IndexFunc(s.emailChanges, func(c db.EmailChange) bool {
+		return c.RevertTokenHash != nil && bytes.Equal(c.RevertTokenHash, revertTokenHash) &&
+			!c.RevertedAt.Valid && c.RevertExpiresAt.Valid && c.RevertExpiresAt.Time.After(now.Time)
+	})
+	if i < 0 {
+		return db.EmailChange{}, pgx.ErrNoRows
+	}
+	s.emailChanges[i].RevertedAt = now
+	return s.emailChanges[i], nil
+}
+
+func (s *Store) updateUserEmail(ctx context.Context, arg db.UpdateUserEmailParams) (db.User, error) {
+	if err := s.begin(ctx, "UpdateUserEmail"); err != nil {
+		return db.User{}, err
+	}
+	i := s.activeUser(arg.ID)
+	if i < 0 || s.users[i].Email != arg.OldEmail {
+		return db.User{}, pgx.ErrNoRows
+	}
+	if len([]rune(arg.NewEmail)) > 255 {
+		return db.User{}, pgError(stringDataRightTruncated, "value too long for type character varying(255)", "users", "")
+	}
+	if err := s.checkEmail(arg.NewEmail, arg.ID); err != nil {
+		return db.User{}, err
+	}
+	now := s.timestamp()
+	s.users[i].Email = arg.NewEmail
+	s.users[i].EmailCanonical = helper.CanonicalEmail(arg.NewEmail)
+	s.users[i].EmailVerifiedAt = now
+	s.users[i].UpdatedAt = now
+	return s.users[i], nil
+}
+
+func (s *Store) deleteUserTokens(ctx context.Context, arg db.DeleteUserTokensParams) (int64, error) {
+	if err := s.begin(ctx, "DeleteUserTokens"); err != nil {
+		return 0, err
+	}
+	before := len(s.tokens)
+	s.tokens = slices.DeleteFunc(s.tokens, func(t db.UserToken) bool {
+		return t.UserID == arg.UserID && t.Purpose == arg.Purpose && !t.UsedAt.Valid
+	})
+	return int64(before - len(s.tokens)), nil
+}
+
+func (s *Store) consumeUserToken(ctx context.Context, arg db.ConsumeUserTokenParams) (db.UserToken, error) {
+	if err := s.begin(ctx, "ConsumeUserToken"); err != nil {
+		return db.UserToken{}, err
+	}
+	now := s.timestamp()
+	for i, t := range s.tokens {
+		if bytes.Equal(t.TokenHash, arg.TokenHash) && t.Purpose == arg.Purpose &&
+			!t.UsedAt.Valid && t.ExpiresAt.Time.After(now.Time) {
+			s.tokens[i].UsedAt = now
+			return s.tokens[i], nil
+		}
+	}
+	return db.UserToken{}, pgx.ErrNoRows
+}
+
+func (s *Store) consumePhoneOTP(ctx context.Context, id uuid.UUID) (db.PhoneOtp, error) {
+	if err := s.begin(ctx, "ConsumePhoneOTP"); err != nil {
+		return db.PhoneOtp{}, err
+	}
+	i := slices.IndexFunc(s.phoneOTPs, func(o db.PhoneOtp) bool { return o.ID == id && !o.UsedAt.Valid })
+	if i < 0 {
+		return db.PhoneOtp{}, pgx.ErrNoRows
+	}
+	s.phoneOTPs[i].UsedAt = s.timestamp()
+	return s.phoneOTPs[i], nil
+}
+
+func (s *Store) markPhoneVerified(ctx context.Context, arg db.MarkPhoneVerifiedParams) (db.User, error) {
+	if err := s.begin(ctx, "MarkPhoneVerified"); err != nil {
+		return db.User{}, err
+	}
+	i := s.activeUser(arg.ID)
+	// phone_number = NULL tidak pernah cocok
+	if i < 0 || !arg.PhoneNumber.Valid || s.users[i].PhoneNumber != arg.PhoneNumber {
+		return db.User{}, pgx.ErrNoRows
+	}
+	now := s.timestamp()
+	s.users[i].PhoneVerifiedAt = now
+	s.users[i].UpdatedAt = now
+	return s.users[i], nil
+}
+
+func (s *Store) resetUserPassword(ctx context.Context, arg db.ResetUserPasswordParams) (db.User, error) {
+	if err := s.begin(ctx, "ResetUserPassword"); err != nil {
+		return db.User{}, err
+	}
+	i := s.activeUser(arg.ID)
+	if i < 0 {
+		return db.User{}, pgx.ErrNoRows
+	}
+	now := s.timestamp()
+	s.users[i].PasswordHash = arg.PasswordHash
+	s.users[i].PasswordChangedAt = now
+	s.users[i].SessionVersion++
+	s.users[i].UpdatedAt = now
+	return s.users[i], nil
+}
+
+func (s *Store) markEmailVerified(ctx context.Context, id uuid.UUID) (db.User, error) {
+	if err := s.begin(ctx, "MarkEmailVerified"); err != nil {
+		return db.User{}, err
+	}
+	i := s.activeUser(id)
+	if i < 0 {
+		return db.User{}, pgx.ErrNoRows
+	}
+	now := s.timestamp()
+	// COALESCE: waktu verifikasi pertama tidak ditimpa
+	if !s.users[i].EmailVerifiedAt.Valid {
+		s.users[i].EmailVerifiedAt = now
+	}
+	s.users[i].UpdatedAt = now
+	return s.users[i], nil
+}
+
+func (s *Store) createUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	if err := s.begin(ctx, "CreateUser"); err != nil {
+		return db.User{}, err
+	}
+	for _, c := range []struct {
+		column string
+		value  string
+		max    int
+	}{
+		{"email", arg.Email, 255},
+		{"full_name", arg.FullName.String, 100},
+		{"phone_number", arg.PhoneNumber.String, 20},
+		{"role", arg.Role, 20},
+	} {
+		if len([]rune(c.value)) > c.max {
+			return db.User{}, pgError(stringDataRightTruncated,
+				fmt.Sprintf("value too long for type character varying(%d)", c.max), "users", "")
+		}
+	}
+	if err := checkRole(arg.Role); err != nil {
+		return db.User{}, err
+	}
+	if err := s.checkEmail(arg.Email, uuid.Nil); err != nil {
+		return db.User{}, err
+	}
+
+	now := s.timestamp()
+	user := db.User{
+		ID:             s.newID(),
+		Email:          arg.Email,
+		EmailCanonical: helper.CanonicalEmail(arg.Email),
+		FullName:       arg.FullName,
+		PhoneNumber:    arg.PhoneNumber,
+		Role:           arg.Role,
+		AvatarUrl:      arg.AvatarUrl,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+func (s *Store) createUserMetadata(ctx context.Context, arg db.CreateUserMetadataParams) error {
+	if err := s.begin(ctx, "CreateUserMetadata"); err != nil {
+		return err
+	}
+	if arg.Metadata != nil {
+		var v any
+		if err := json.Unmarshal(arg.Metadata, &v); err != nil {
+			return pgError(invalidTextRepresentation, "invalid input syntax for type json", "user_metadata", "")
+		}
+		if _, ok := v.(map[string]any); !ok && v != nil {
+			return pgError(checkViolation,
+				`new row for relation "user_metadata" violates check constraint "valid_metadata"`, "user_metadata", "valid_metadata")
+		}
+	}
+	if !slices.ContainsFunc(s.users, func(u db.User) bool { return u.ID == arg.UserID }) {
+		return pgError(foreignKeyViolation,
+			`insert or update on table "user_metadata" violates foreign key constraint "user_metadata_user_id_fkey"`,
+			"user_metadata", "user_metadata_user_id_fkey")
+	}
+
+	s.metadata = append(s.metadata, db.UserMetadatum{
+		UserID:    arg.UserID,
+		Metadata:  slices.Clone(arg.Metadata),
+		CreatedAt: s.timestamp(),
+	})
+	return nil
+}
+
+// checkEmail meniru unique index users_email_canonical_key: email_canonical unik di antara user yang belum dihapus.
+// Baris dengan ID self (user yang sedang diubah) dilewati.
+func (s *Store) checkEmail(email string, self uuid.UUID) error {
+	for _, u := range s.users {
+		if u.ID != self && !u.DeletedAt.Valid && u.EmailCanonical == helper.CanonicalEmail(email) {
+			return pgError(db.UniqueViolation,
+				`duplicate key value violates unique constraint "users_email_canonical_key"`, "users", "users_email_canonical_key")
+		}
+	}
+	return nil
+}
+
+// begin memeriksa ctx dan kegagalan yang di-set lewat Fail sebelum query dijalankan.
+func (s *Store) begin(ctx context.Context, query string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.faults[query]
+}
+
+// tx menjalankan fn seperti transaksi: jika fn gagal, users, user_tokens, email_changes dan phone_otps
+// dikembalikan ke keadaan sebelum fn. Baris disalin per nilai, jadi fn tidak boleh mengubah isi slice di dalam baris.
+func (s *Store) tx(fn func() error) error {
+	users, tokens, changes, otps := slices.Clone(s.users), slices.Clone(s.tokens), slices.Clone(s.emailChanges), slices.Clone(s.phoneOTPs)
+	if err := fn(); err != nil {
+		s.users, s.tokens, s.emailChanges, s.phoneOTPs = users, tokens, changes, otps
+		return err
+	}
+	return nil
+}
+
+// activeUser mengembalikan index user yang belum dihapus, atau -1.
+func (s *Store) activeUser(id uuid.UUID) int {
+	return slices.IndexFunc(s.users, func(u db.User) bool {
+		return u.ID == id && !u.DeletedAt.Valid
+	})
+}
+
+// timestamp membulatkan ke mikrodetik, presisi timestamptz di Postgres.
+func (s *Store) timestamp() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: s.now().Truncate(time.Microsecond), Valid: true}
+}
+
+// tokenPurposes harus sama dengan constraint valid_purpose di tabel user_tokens.
+var tokenPurposes = []string{db.TokenPurposeVerifyEmail, db.TokenPurposeResetPassword}
+
+func checkRole(role string) error {
+	if !slices.Contains(constants.Roles, role) {
+		return pgError(checkViolation,
+			`new row for relation "users" violates check constraint "valid_role"`, "users", "valid_role")
+	}
+	return nil
+}
+
+func pgError(code, message, table, constraint string) *pgconn.PgError {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           code,
+		Message:        message,
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+// like mencocokkan s dengan pola LIKE Postgres: % untuk nol atau lebih karakter,
+// _ untuk tepat satu karakter, dan \ untuk escape.
+func like(s, pattern string) bool {
+	str, pat := []rune(s), []rune(pattern)
+	var match func(i, j int) bool
+	match = func(i, j int) bool {
+		for j < len(pat) {
+			switch pat[j] {
+			case '%':
+				for j < len(pat) && pat[j] == '%' {
+					j++
+				}
+				if j == len(pat) {
+					return true
+				}
+				for k := i; k <= len(str); k++ {
+					if match(k, j) {
+						return true
+					}
+				}
+				return false
+			case '_':
+				if i == len(str) {
+					return false
+				}
+				i++
+				j++
+			case '\\':
+				if j+1 < len(pat) {
+					j++
+				}
+				fallthrough
+			default:
+				if i == len(str) || str[i] != pat[j] {
+					return false
+				}
+				i++
+				j++
+			}
+		}
+		return i == len(str)
+	}
+	return match(0, 0)
+}
@@ -0,0 +1,57 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	db "user-service/db/sqlc"
+	"user-service/db/storetest"
+	"user-service/pkg/helper"
+)
+
+func TestLike(t *testing.T) {
+	tests := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"budi@example.com", "%budi%", true},
+		{"budi@example.com", "%BUDI%", false},
+		{"budi@example.com", "budi%", true},
+		{"budi@example.com", "%.com", true},
+		{"budi@example.com", "%.org", false},
+		{"budi", "b_di", true},
+		{"budi", "b_i", false},
+		{"budi", "%", true},
+		{"", "%%", true},
+		{"50%", `%0\%`, true},
+		{"500", `%0\%`, false},
+		{"a_b", `%\_%`, true},
+		{"ab", `%\_%`, false},
+	}
+	for _, tt := range tests {
+		if got := like(tt.s, tt.pattern); got != tt.want {
+			t.Errorf("like(%q, %q) = %v, want %v", tt.s, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, storetest.Harness{
+		New: func(t *testing.T) db.Store {
+			return New()
+		},
+		FailMetadata: func(t *testing.T, s db.Store) {
+			s.(*Store).Fail("CreateUserMetadata", errors.New("metadata insert failed"))
+		},
+	})
+}
+
+func TestCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := New().ListUsers(ctx, db.ListUsersParams{Search: helper.StringToPGTextValid(""), Limit: 10}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ListUsers error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- Table: user_tokens (token sekali pakai yang dikirim ke user, hanya hash-nya yang disimpan)
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT valid_purpose CHECK (purpose IN ('verify_email'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens(user_id, purpose);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_email_changes_pending;
+
+DROP TABLE IF EXISTS email_changes;
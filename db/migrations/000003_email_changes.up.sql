@@ -0,0 +1,18 @@
+-- Table: email_changes (permintaan ganti email, users.email baru diganti setelah alamat baru dikonfirmasi)
+CREATE TABLE IF NOT EXISTS email_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    confirm_token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    -- token pembatalan dikirim ke alamat lama saat perubahan dikonfirmasi
+    revert_token_hash BYTEA UNIQUE,
+    revert_expires_at TIMESTAMPTZ,
+    reverted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- satu user hanya punya satu permintaan yang belum dikonfirmasi
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_changes_pending ON email_changes(user_id) WHERE confirmed_at IS NULL;
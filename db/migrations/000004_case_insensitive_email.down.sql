@@ -0,0 +1,22 @@
+-- constraint lama berlaku juga untuk user yang sudah dihapus; email yang dipakai ulang setelah soft delete
+-- harus diselesaikan manual dulu. Normalisasi email tidak dikembalikan.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('%s: %s', d.email, d.ids), '; ' ORDER BY d.email) INTO conflicts
+    FROM (
+        SELECT email, string_agg(id::text, ', ' ORDER BY created_at) AS ids
+        FROM users
+        GROUP BY email
+        HAVING count(*) > 1
+    ) d;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'users share an email address, including soft-deleted ones: %', conflicts;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS users_email_lower_key;
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE deleted_at IS NULL;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,38 @@
+-- Email yang hanya berbeda huruf besar/kecil (atau spasi) di antara user aktif harus diselesaikan manual dulu,
+-- misalnya dengan soft delete salah satunya. Migrasi berhenti sebelum mengubah apa pun dan menyebutkan semuanya.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('%s: %s', d.email, d.ids), '; ' ORDER BY d.email) INTO conflicts
+    FROM (
+        SELECT lower(btrim(email)) AS email, string_agg(id::text, ', ' ORDER BY created_at) AS ids
+        FROM users
+        WHERE deleted_at IS NULL
+        GROUP BY lower(btrim(email))
+        HAVING count(*) > 1
+    ) d;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'active users share an email address ignoring case: %', conflicts
+            USING HINT = 'soft delete or change all but one user of each email, then run `user-service migrate force 3` and migrate up again';
+    END IF;
+END $$;
+
+-- constraint lama harus dihapus sebelum normalisasi: UPDATE di bawah juga mengenai user yang sudah dihapus,
+-- yang tidak ikut dicek di atas, dan bisa saja sama dengan user lain setelah domain-nya dijadikan huruf kecil
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+
+-- normalisasi yang sama dengan helper.NormalizeEmail tanpa aturan provider: trim dan domain huruf kecil
+UPDATE users u
+SET email = n.email
+FROM (
+    SELECT id, left(e, length(e) - strpos(reverse(e), '@')) || lower(right(e, strpos(reverse(e), '@'))) AS email
+    FROM (SELECT id, btrim(email) AS e FROM users) t
+    WHERE strpos(e, '@') > 0
+) n
+WHERE u.id = n.id AND u.email <> n.email;
+
+-- email unik tanpa membedakan huruf besar/kecil, hanya di antara user yang belum dihapus
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_phone_otps_pending;
+
+DROP TABLE IF EXISTS phone_otps;
+
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
@@ -0,0 +1,17 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMPTZ;
+
+-- Table: phone_otps (kode OTP verifikasi nomor telepon, hanya hash-nya yang disimpan)
+CREATE TABLE IF NOT EXISTS phone_otps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- nomor yang diverifikasi, kode tidak berlaku lagi jika users.phone_number berubah
+    phone_number VARCHAR(20) NOT NULL,
+    code_hash BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- satu user hanya punya satu kode yang belum dipakai
+CREATE UNIQUE INDEX IF NOT EXISTS idx_phone_otps_pending ON phone_otps(user_id) WHERE used_at IS NULL;
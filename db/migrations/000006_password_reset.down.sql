@@ -0,0 +1,8 @@
+DELETE FROM user_tokens WHERE purpose = 'reset_password';
+
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS valid_purpose;
+ALTER TABLE user_tokens ADD CONSTRAINT valid_purpose CHECK (purpose IN ('verify_email'));
+
+ALTER TABLE users DROP COLUMN IF EXISTS session_version;
+ALTER TABLE users DROP COLUMN IF EXISTS password_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
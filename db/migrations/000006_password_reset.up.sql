@@ -0,0 +1,8 @@
+-- hash bcrypt, NULL untuk user yang belum pernah membuat password
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
+-- disalin ke access token (claim sv); menaikkannya mencabut semua token user yang sudah diterbitkan
+ALTER TABLE users ADD COLUMN IF NOT EXISTS session_version INT NOT NULL DEFAULT 0;
+
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS valid_purpose;
+ALTER TABLE user_tokens ADD CONSTRAINT valid_purpose CHECK (purpose IN ('verify_email', 'reset_password'));
@@ -0,0 +1,5 @@
+-- email kanonik yang unik juga unik tanpa membedakan huruf besar/kecil, jadi index lama selalu bisa dibuat lagi
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email)) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS users_email_canonical_key;
+ALTER TABLE users DROP COLUMN IF EXISTS email_canonical;
+DROP FUNCTION IF EXISTS canonical_email(TEXT);
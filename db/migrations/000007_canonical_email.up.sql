@@ -0,0 +1,47 @@
+-- canonical_email harus sama dengan helper.CanonicalEmail: trim, alamat Gmail/Googlemail tanpa titik dan +tag
+-- di domain gmail.com (kecuali local part-nya habis), lalu huruf kecil semua. Kunci ini tidak bergantung pada
+-- EMAIL_PROVIDER_RULES, jadi flag itu boleh dinyalakan kapan saja tanpa membuat duplikat.
+CREATE OR REPLACE FUNCTION canonical_email(email TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE
+AS $$
+    SELECT CASE
+        WHEN strpos(p.e, '@') = 0 THEN lower(p.e)
+        WHEN p.domain IN ('gmail.com', 'googlemail.com') AND p.gmail <> '' THEN p.gmail || '@gmail.com'
+        ELSE lower(p.e)
+    END
+    FROM (
+        SELECT e,
+            lower(right(e, strpos(reverse(e), '@') - 1)) AS domain,
+            replace(split_part(lower(left(e, length(e) - strpos(reverse(e), '@'))), '+', 1), '.', '') AS gmail
+        FROM (SELECT btrim(email, E' \t\n\r\f\x0b') AS e) t
+    ) p
+$$;
+
+-- alamat Gmail yang sama di antara user aktif (misalnya b.udi@gmail.com dan budi@gmail.com) harus diselesaikan
+-- manual dulu. Migrasi berhenti sebelum mengubah apa pun dan menyebutkan semuanya.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('%s: %s', d.email, d.ids), '; ' ORDER BY d.email) INTO conflicts
+    FROM (
+        SELECT canonical_email(email) AS email, string_agg(id::text, ', ' ORDER BY created_at) AS ids
+        FROM users
+        WHERE deleted_at IS NULL
+        GROUP BY canonical_email(email)
+        HAVING count(*) > 1
+    ) d;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'active users share a canonical email address: %', conflicts
+            USING HINT = 'soft delete or change all but one user of each email, then run `user-service migrate force 6` and migrate up again';
+    END IF;
+END $$;
+
+-- kolom generated ikut terisi untuk baris lama dan selalu mengikuti email
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_canonical TEXT NOT NULL
+    GENERATED ALWAYS AS (canonical_email(email)) STORED;
+
+-- menggantikan users_email_lower_key: email kanonik unik di antara user yang belum dihapus
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_canonical_key ON users (email_canonical) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS users_email_lower_key;
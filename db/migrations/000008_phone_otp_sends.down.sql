@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_phone_otp_sends_phone;
+
+DROP TABLE IF EXISTS phone_otp_sends;
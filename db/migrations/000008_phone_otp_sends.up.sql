@@ -0,0 +1,10 @@
+-- Table: phone_otp_sends (riwayat kode OTP yang dikirim, untuk membatasi jumlah SMS per nomor telepon)
+CREATE TABLE IF NOT EXISTS phone_otp_sends (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- nomor E.164, jadi penulisan berbeda untuk nomor yang sama tetap dihitung bersama
+    phone_number VARCHAR(20) NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_phone_otp_sends_phone ON phone_otp_sends(phone_number, sent_at);
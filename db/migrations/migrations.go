@@ -0,0 +1,190 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"user-service/config"
+	db "user-service/db/sqlc"
+	logger "user-service/pkg"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// FS berisi semua file migrasi, ikut ter-embed di binary.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// ErrSchemaBehind dikembalikan CheckCurrent jika masih ada migrasi yang belum dijalankan.
+var ErrSchemaBehind = errors.New("database schema is behind, run `user-service migrate up`")
+
+// Migrator menjalankan migrasi ter-embed terhadap database dari config.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// Migration adalah satu file migrasi beserta status penerapannya.
+type Migration struct {
+	Version    uint
+	Identifier string
+	Applied    bool
+}
+
+func New(cfg *config.AppConfig) (*Migrator, error) {
+	src, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("open database for migration: %w", err)
+	}
+	m.Log = migrateLogger{}
+	return &Migrator{m: m}, nil
+}
+
+// databaseURL mengganti scheme postgres:// menjadi pgx5:// yang dipakai driver golang-migrate.
+func databaseURL(cfg *config.AppConfig) string {
+	conn := db.ConnString(cfg.DB)
+	for _, scheme := range []string{"postgresql://", "postgres://"} {
+		if strings.HasPrefix(conn, scheme) {
+			return "pgx5://" + strings.TrimPrefix(conn, scheme)
+		}
+	}
+	return conn
+}
+
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// Up menjalankan semua migrasi yang belum diterapkan.
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down me-rollback sejumlah steps migrasi terakhir.
+func (mg *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+	return ignoreNoChange(mg.m.Steps(-steps))
+}
+
+// Migrate menjalankan migrasi naik atau turun sampai versi schema sama dengan version.
+func (mg *Migrator) Migrate(version uint) error {
+	return ignoreNoChange(mg.m.Migrate(version))
+}
+
+// Force menandai versi schema tanpa menjalankan migrasi, untuk memulihkan state dirty.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// Version mengembalikan versi schema saat ini. Versi 0 berarti belum ada migrasi yang diterapkan.
+func (mg *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Latest mengembalikan versi migrasi terbaru yang ter-embed.
+func Latest() (uint, error) {
+	all, err := List()
+	if err != nil {
+		return 0, err
+	}
+	if len(all) == 0 {
+		return 0, nil
+	}
+	return all[len(all)-1].Version, nil
+}
+
+// List mengembalikan semua migrasi ter-embed, terurut dari versi terlama.
+func List() ([]Migration, error) {
+	src, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	var all []Migration
+	version, err := src.First()
+	for err == nil {
+		r, identifier, readErr := src.ReadUp(version)
+		if readErr != nil {
+			return nil, readErr
+		}
+		r.Close()
+		all = append(all, Migration{Version: version, Identifier: identifier})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return all, nil
+}
+
+// Status mengembalikan semua migrasi ter-embed dengan penanda sudah/belum diterapkan.
+func (mg *Migrator) Status() ([]Migration, uint, bool, error) {
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	all, err := List()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	for i := range all {
+		all[i].Applied = all[i].Version <= current
+	}
+	return all, current, dirty, nil
+}
+
+// CheckCurrent memastikan schema database tidak tertinggal dari migrasi ter-embed dan tidak dirty.
+func (mg *Migrator) CheckCurrent() error {
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database schema version %d is dirty, fix it manually and run `user-service migrate force`", current)
+	}
+	latest, err := Latest()
+	if err != nil {
+		return err
+	}
+	if current < latest {
+		return fmt.Errorf("%w (current %d, latest %d)", ErrSchemaBehind, current, latest)
+	}
+	if current > latest {
+		logger.Log.Warnf("database schema version %d is newer than this binary (%d)", current, latest)
+	}
+	return nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// migrateLogger meneruskan log golang-migrate ke logrus.
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	logger.Log.Infof(strings.TrimSuffix(format, "\n"), v...)
+}
+
+func (migrateLogger) Verbose() bool { return false }
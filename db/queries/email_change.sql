@@ -0,0 +1,23 @@
+-- name: CreateEmailChange :one
+INSERT INTO email_changes (
+    user_id, old_email, new_email, confirm_token_hash, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: DeletePendingEmailChanges :execrows
+DELETE FROM email_changes
+WHERE user_id = $1 AND confirmed_at IS NULL;
+
+-- name: ConfirmEmailChange :one
+UPDATE email_changes
+SET confirmed_at = now(), revert_token_hash = $2, revert_expires_at = $3
+WHERE confirm_token_hash = $1 AND confirmed_at IS NULL AND expires_at > now()
+RETURNING *;
+
+-- name: RevertEmailChange :one
+UPDATE email_changes
+SET reverted_at = now()
+WHERE revert_token_hash = $1 AND reverted_at IS NULL AND revert_expires_at > now()
+RETURNING *;
@@ -0,0 +1,38 @@
+-- name: CreatePhoneOTP :one
+INSERT INTO phone_otps (
+    user_id, phone_number, code_hash, expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetPendingPhoneOTP :one
+SELECT * FROM phone_otps WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: ClaimPhoneOTPAttempt :one
+UPDATE phone_otps
+SET attempts = attempts + 1
+WHERE user_id = sqlc.arg(user_id) AND used_at IS NULL AND expires_at > now() AND attempts < sqlc.arg(max_attempts)::int
+RETURNING *;
+
+-- name: ConsumePhoneOTP :one
+UPDATE phone_otps
+SET used_at = now()
+WHERE id = $1 AND used_at IS NULL
+RETURNING *;
+
+-- name: DeletePhoneOTPs :execrows
+DELETE FROM phone_otps
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: CreatePhoneOTPSend :exec
+INSERT INTO phone_otp_sends (
+    user_id, phone_number, sent_at
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: ListPhoneOTPSends :many
+SELECT sent_at FROM phone_otp_sends
+WHERE phone_number = sqlc.arg(phone_number) AND sent_at > sqlc.arg(since)
+ORDER BY sent_at;
@@ -10,7 +10,7 @@ RETURNING *;
 SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL;
+SELECT * FROM users WHERE email_canonical = canonical_email($1) AND deleted_at IS NULL;
 
 -- name: GetUserWithMetadata :one
 SELECT 
@@ -41,3 +41,43 @@ WHERE deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
+-- name: UpdateUserAvatar :one
+UPDATE users
+SET avatar_url = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteUser :execrows
+UPDATE users
+SET deleted_at = now(), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: MarkEmailVerified :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, now()), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+UPDATE users
+SET email = sqlc.arg(new_email), email_verified_at = now(), updated_at = now()
+WHERE id = sqlc.arg(id) AND email = sqlc.arg(old_email) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: MarkPhoneVerified :one
+UPDATE users
+SET phone_verified_at = now(), updated_at = now()
+WHERE id = $1 AND phone_number = $2 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ResetUserPassword :one
+UPDATE users
+SET password_hash = $2, password_changed_at = now(), session_version = session_version + 1, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
@@ -0,0 +1,155 @@
+// Package seed mengisi database dengan user contoh untuk development, demo dan load test.
+// Semua data dibuat dari generator acak ber-seed, jadi seed yang sama selalu menghasilkan data yang sama.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+
+	"user-service/constants"
+	db "user-service/db/sqlc"
+	"user-service/pkg/helper"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultSeed dipakai jika seed tidak ditentukan.
+const DefaultSeed uint64 = 42
+
+var (
+	firstNames = []string{
+		"Budi", "Siti", "Agus", "Dewi", "Rizky", "Putri", "Andi", "Ayu", "Fajar", "Indah",
+		"Hendra", "Lestari", "Yoga", "Rina", "Bayu", "Maya", "Dimas", "Nur", "Arif", "Wulan",
+	}
+	lastNames = []string{
+		"Santoso", "Wijaya", "Saputra", "Pratama", "Hidayat", "Kusuma", "Nugroho", "Lubis",
+		"Siregar", "Rahman", "Setiawan", "Halim", "Gunawan", "Susanto", "Hakim", "Purba",
+	}
+	devices   = []string{"web", "android", "ios"}
+	locales   = []string{"id-ID", "en-US"}
+	timezones = []string{"Asia/Jakarta", "Asia/Makassar", "Asia/Jayapura"}
+)
+
+// fixture adalah user tetap yang selalu ada setelah seeding, supaya mudah dipakai untuk login saat demo.
+type fixture struct {
+	email    string
+	fullName string
+	role     string
+}
+
+var fixtures = []fixture{
+	{"superadmin@example.com", "Super Admin", constants.RoleSuperadmin},
+	{"admin.acme@example.com", "Acme Admin", constants.RoleTenantAdmin},
+	{"admin.globex@example.com", "Globex Admin", constants.RoleTenantAdmin},
+	{"staff1.acme@example.com", "Acme Staff Satu", constants.RoleTenantStaff},
+	{"staff2.acme@example.com", "Acme Staff Dua", constants.RoleTenantStaff},
+	{"staff1.globex@example.com", "Globex Staff Satu", constants.RoleTenantStaff},
+	{"budi@example.com", "Budi Santoso", constants.RoleUser},
+	{"siti@example.com", "Siti Rahayu", constants.RoleUser},
+	{"agus@example.com", "Agus Pratama", constants.RoleUser},
+	{"dewi@example.com", "Dewi Lestari", constants.RoleUser},
+}
+
+// Fixtures mengembalikan set user tetap untuk semua role. Email, nama dan role tidak bergantung
+// pada seed; nomor telepon dan metadata dibuat dari seed.
+func Fixtures(seed uint64) []db.CreateuserWithMetadataParams {
+	r := newRand(seed)
+	users := make([]db.CreateuserWithMetadataParams, len(fixtures))
+	for i, f := range fixtures {
+		users[i] = newParams(r, f.email, f.fullName, f.role)
+	}
+	return users
+}
+
+// Generate membuat n user sintetis untuk load test. Email diberi nomor urut dan seed,
+// jadi dua batch dengan seed berbeda tidak saling bentrok.
+func Generate(seed uint64, n int) []db.CreateuserWithMetadataParams {
+	r := newRand(seed)
+	users := make([]db.CreateuserWithMetadataParams, n)
+	for i := range users {
+		first := pick(r, firstNames)
+		last := pick(r, lastNames)
+		email := fmt.Sprintf("%s.%s.%d.%d@loadtest.example.com", strings.ToLower(first), strings.ToLower(last), seed, i)
+		users[i] = newParams(r, email, first+" "+last, randomRole(r))
+	}
+	return users
+}
+
+// Result merangkum hasil Insert.
+type Result struct {
+	Created int `json:"created"`
+	// Skipped: user yang email-nya sudah ada, sehingga seeding aman dijalankan berulang kali
+	Skipped int `json:"skipped"`
+}
+
+// Insert menyimpan users lewat CreateUserWithMetadata dengan beberapa worker paralel.
+// Email yang sudah terdaftar dilewati, error lain menghentikan seeding.
+func Insert(ctx context.Context, store db.Store, users []db.CreateuserWithMetadataParams, workers int) (Result, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var created, skipped atomic.Int64
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+	for _, u := range users {
+		g.Go(func() error {
+			_, err := store.CreateUserWithMetadata(ctx, u)
+			switch {
+			case err == nil:
+				created.Add(1)
+			case db.IsUniqueViolation(err):
+				skipped.Add(1)
+			default:
+				return fmt.Errorf("seed %s: %w", u.Email, err)
+			}
+			return nil
+		})
+	}
+	err := g.Wait()
+
+	return Result{Created: int(created.Load()), Skipped: int(skipped.Load())}, err
+}
+
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+func newParams(r *rand.Rand, email, fullName, role string) db.CreateuserWithMetadataParams {
+	return db.CreateuserWithMetadataParams{
+		CreateUserParams: db.CreateUserParams{
+			Email:       email,
+			FullName:    helper.StringToPGTextValid(fullName),
+			PhoneNumber: helper.StringToPGText(fmt.Sprintf("+628%02d%08d", 11+r.IntN(12), r.IntN(100_000_000))),
+			Role:        role,
+			AvatarUrl:   helper.StringToPGText(fmt.Sprintf("https://avatars.example.com/%d.png", r.IntN(1000))),
+		},
+		UserMetadata: db.UserMetadata{
+			Device:   pick(r, devices),
+			Locale:   pick(r, locales),
+			Timezone: pick(r, timezones),
+			Source:   "seed",
+		},
+	}
+}
+
+// randomRole: mayoritas user biasa, sisanya tersebar di role lain.
+func randomRole(r *rand.Rand) string {
+	switch n := r.IntN(100); {
+	case n < 1:
+		return constants.RoleSuperadmin
+	case n < 6:
+		return constants.RoleTenantAdmin
+	case n < 20:
+		return constants.RoleTenantStaff
+	default:
+		return constants.RoleUser
+	}
+}
+
+func pick(r *rand.Rand, items []string) string {
+	return items[r.IntN(len(items))]
+}
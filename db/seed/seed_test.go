@@ -0,0 +1,112 @@
+package seed_test
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"user-service/constants"
+	"user-service/db/memstore"
+	"user-service/db/seed"
+	db "user-service/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestFixtures(t *testing.T) {
+	a, b := seed.Fixtures(seed.DefaultSeed), seed.Fixtures(seed.DefaultSeed)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("Fixtures with the same seed returned different users")
+	}
+
+	// email, nama dan role tetap untuk seed mana pun, nomor telepon mengikuti seed
+	other := seed.Fixtures(7)
+	samePhones := true
+	for i := range a {
+		if a[i].Email != other[i].Email || a[i].FullName != other[i].FullName || a[i].Role != other[i].Role {
+			t.Errorf("fixture %d: %s/%s with seed 7, want %s/%s", i, other[i].Email, other[i].Role, a[i].Email, a[i].Role)
+		}
+		samePhones = samePhones && a[i].PhoneNumber == other[i].PhoneNumber
+	}
+	if samePhones {
+		t.Error("fixtures with seeds 42 and 7 have the same phone numbers")
+	}
+
+	for _, role := range constants.Roles {
+		if !slices.ContainsFunc(a, func(u db.CreateuserWithMetadataParams) bool { return u.Role == role }) {
+			t.Errorf("no fixture with role %s", role)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	a := seed.Generate(seed.DefaultSeed, 200)
+	if !reflect.DeepEqual(a, seed.Generate(seed.DefaultSeed, 200)) {
+		t.Fatal("Generate with the same seed returned different users")
+	}
+	// batch lebih pendek adalah awal dari batch yang lebih panjang
+	if !reflect.DeepEqual(a[:50], seed.Generate(seed.DefaultSeed, 50)) {
+		t.Error("Generate(seed, 50) is not a prefix of Generate(seed, 200)")
+	}
+
+	emails := map[string]bool{}
+	for _, u := range slices.Concat(a, seed.Generate(7, 200)) {
+		if emails[u.Email] {
+			t.Errorf("duplicate email %s", u.Email)
+		}
+		emails[u.Email] = true
+		if !slices.Contains(constants.Roles, u.Role) || u.Source != "seed" {
+			t.Errorf("%s: role %q, source %q", u.Email, u.Role, u.Source)
+		}
+	}
+}
+
+// TestInsertDeterministic memastikan dua database yang di-seed dengan seed yang sama berisi user yang sama,
+// dan seeding ulang hanya melewati user yang sudah ada.
+func TestInsertDeterministic(t *testing.T) {
+	ctx := context.Background()
+	users := slices.Concat(seed.Fixtures(seed.DefaultSeed), seed.Generate(seed.DefaultSeed, 100))
+
+	var contents [2][]db.User
+	for i := range contents {
+		store := memstore.New()
+		res, err := seed.Insert(ctx, store, users, 4)
+		if err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+		if res != (seed.Result{Created: len(users)}) {
+			t.Errorf("first Insert = %+v, want %d created", res, len(users))
+		}
+		res, err = seed.Insert(ctx, store, users, 4)
+		if err != nil {
+			t.Fatalf("second Insert: %v", err)
+		}
+		if res != (seed.Result{Skipped: len(users)}) {
+			t.Errorf("second Insert = %+v, want %d skipped", res, len(users))
+		}
+
+		list, err := store.ListUsers(ctx, db.ListUsersParams{Search: pgtype.Text{Valid: true}, Limit: int32(len(users) + 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[i] = list
+	}
+
+	if len(contents[0]) != len(users) || len(contents[1]) != len(users) {
+		t.Fatalf("stores have %d and %d users, want %d", len(contents[0]), len(contents[1]), len(users))
+	}
+	// ID dan timestamp dibuat store, yang dibandingkan hanya data dari seed
+	key := func(u db.User) string {
+		return u.Email + "|" + u.FullName.String + "|" + u.PhoneNumber.String + "|" + u.Role + "|" + u.AvatarUrl.String
+	}
+	a, b := make([]string, len(users)), make([]string, len(users))
+	for i := range users {
+		a[i], b[i] = key(contents[0][i]), key(contents[1][i])
+	}
+	slices.Sort(a)
+	slices.Sort(b)
+	if !slices.Equal(a, b) {
+		t.Error("two stores seeded with the same seed have different users")
+	}
+}
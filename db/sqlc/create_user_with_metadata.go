@@ -3,12 +3,14 @@ package db
 import (
 	"context"
 	"encoding/json"
-
-	logger "user-service/pkg"
 )
 
 type UserMetadata struct {
-	Device string `json:"device"`
+	Device   string `json:"device"`
+	Locale   string `json:"locale,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	// Source: asal pendaftaran, misalnya "signup", "invite" atau "seed"
+	Source string `json:"source,omitempty"`
 }
 
 type CreateuserWithMetadataParams struct {
@@ -26,13 +28,13 @@ func (s *store) CreateUserWithMetadata(ctx context.Context, arg CreateuserWithMe
 
 		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
 		if err != nil {
-			logger.Log.Errorf("failed to create user: %v", err)
+			log.FromContext(ctx).Errorf("failed to create user: %v", err)
 			return err
 		}
 
 		jsonMeta, err := json.Marshal(arg.UserMetadata)
 		if err != nil {
-			logger.Log.Errorf("failed to marshal metadata: %v", err)
+			log.FromContext(ctx).Errorf("failed to marshal metadata: %v", err)
 			return err
 		}
 
@@ -41,7 +43,7 @@ func (s *store) CreateUserWithMetadata(ctx context.Context, arg CreateuserWithMe
 			Metadata: jsonMeta,
 		})
 		if err != nil {
-			logger.Log.Errorf("failed to create user_metadata: %v", err)
+			log.FromContext(ctx).Errorf("failed to create user_metadata: %v", err)
 			return err
 		}
 
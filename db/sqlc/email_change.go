@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+)
+
+type EmailChangeTxResult struct {
+	User   User
+	Change EmailChange
+}
+
+// ApplyEmailChange memakai token konfirmasi dan mengganti users.email ke alamat baru dalam satu transaksi.
+// Email user ikut ditandai terverifikasi dan token verifikasi serta reset password yang belum dipakai dihapus,
+// karena dikirim ke alamat lama.
+// Mengembalikan pgx.ErrNoRows jika token tidak ada, sudah dipakai atau kedaluwarsa, atau email user sudah berubah
+// sejak permintaan dibuat; unique violation jika alamat baru sudah dipakai user lain.
+func (s *store) ApplyEmailChange(ctx context.Context, arg ConfirmEmailChangeParams) (EmailChangeTxResult, error) {
+	var result EmailChangeTxResult
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result.Change, err = q.ConfirmEmailChange(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return swapEmail(ctx, q, &result, result.Change.OldEmail, result.Change.NewEmail)
+	})
+	return result, err
+}
+
+// UndoEmailChange memakai token pembatalan dan mengembalikan users.email ke alamat lama dalam satu transaksi.
+// Error-nya sama dengan ApplyEmailChange; unique violation berarti alamat lama sudah dipakai user lain.
+func (s *store) UndoEmailChange(ctx context.Context, revertTokenHash []byte) (EmailChangeTxResult, error) {
+	var result EmailChangeTxResult
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result.Change, err = q.RevertEmailChange(ctx, revertTokenHash)
+		if err != nil {
+			return err
+		}
+		return swapEmail(ctx, q, &result, result.Change.NewEmail, result.Change.OldEmail)
+	})
+	return result, err
+}
+
+func swapEmail(ctx context.Context, q *Queries, result *EmailChangeTxResult, from, to string) error {
+	var err error
+	result.User, err = q.UpdateUserEmail(ctx, UpdateUserEmailParams{
+		NewEmail: to,
+		ID:       result.Change.UserID,
+		OldEmail: from,
+	})
+	if err != nil {
+		return err
+	}
+	for _, purpose := range []string{TokenPurposeVerifyEmail, TokenPurposeResetPassword} {
+		if _, err := q.DeleteUserTokens(ctx, DeleteUserTokensParams{UserID: result.User.ID, Purpose: purpose}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// UniqueViolation adalah SQLSTATE Postgres untuk pelanggaran constraint UNIQUE.
+const UniqueViolation = "23505"
+
+// IsUniqueViolation bernilai true jika err berasal dari constraint UNIQUE, misalnya email yang sudah dipakai.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == UniqueViolation
+}
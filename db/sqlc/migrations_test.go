@@ -0,0 +1,119 @@
+package db_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"user-service/pkg/helper"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Test migrasi ada di package ini, bukan di db/migrations, karena memakai database yang sama dengan
+// TestConformance: test dalam satu package berjalan berurutan, antar package bisa paralel.
+
+// TestCaseInsensitiveEmailMigration memastikan migrasi 000004 tidak gagal karena user yang sudah dihapus:
+// yang dicek konflik hanya user aktif, tetapi normalisasi mengenai semua baris.
+func TestCaseInsensitiveEmailMigration(t *testing.T) {
+	cfg := testConfig(t)
+	pool := connect(t, cfg)
+	exec(t, pool, "DROP SCHEMA public CASCADE")
+	exec(t, pool, "CREATE SCHEMA public")
+
+	m := newMigrator(t, cfg)
+	if err := m.Migrate(3); err != nil {
+		t.Fatal(err)
+	}
+	// sebelum 000004 email unik case-sensitive, termasuk untuk user yang sudah dihapus
+	exec(t, pool, `INSERT INTO users (email) VALUES ('budi@example.com')`)
+	exec(t, pool, `INSERT INTO users (email, deleted_at) VALUES
+		('budi@Example.com', now()),
+		('siti@Example.com', now()),
+		(' siti@example.com', now())`)
+
+	if err := m.Migrate(4); err != nil {
+		t.Fatalf("migrate to 4: %v", err)
+	}
+	emails := queryStrings(t, pool, "SELECT email FROM users ORDER BY email")
+	want := []string{"budi@example.com", "budi@example.com", "siti@example.com", "siti@example.com"}
+	if !slices.Equal(emails, want) {
+		t.Errorf("emails after migration = %q, want %q", emails, want)
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+}
+
+// TestCanonicalEmailMigration memastikan migrasi 000007 berhenti jika user aktif bentrok setelah dibuat kanonik,
+// mengisi email_canonical untuk baris lama, dan fungsi canonical_email sama dengan helper.CanonicalEmail.
+func TestCanonicalEmailMigration(t *testing.T) {
+	cfg := testConfig(t)
+	pool := connect(t, cfg)
+	exec(t, pool, "DROP SCHEMA public CASCADE")
+	exec(t, pool, "CREATE SCHEMA public")
+
+	m := newMigrator(t, cfg)
+	if err := m.Migrate(6); err != nil {
+		t.Fatal(err)
+	}
+	exec(t, pool, `INSERT INTO users (email) VALUES ('B.udi+Promo@gmail.com'), ('Siti@example.com'), ('budi@googlemail.com')`)
+	exec(t, pool, `INSERT INTO users (email, deleted_at) VALUES ('budi@gmail.com', now())`)
+
+	err := m.Migrate(7)
+	if err == nil || !strings.Contains(err.Error(), "budi@gmail.com") {
+		t.Fatalf("migrate to 7 with conflicting active users: got %v, want an error naming budi@gmail.com", err)
+	}
+	if err := m.Force(6); err != nil {
+		t.Fatal(err)
+	}
+	exec(t, pool, `UPDATE users SET deleted_at = now() WHERE email = 'budi@googlemail.com'`)
+	if err := m.Migrate(7); err != nil {
+		t.Fatalf("migrate to 7: %v", err)
+	}
+	got := queryStrings(t, pool, "SELECT email_canonical FROM users ORDER BY email_canonical")
+	want := []string{"budi@gmail.com", "budi@gmail.com", "budi@gmail.com", "siti@example.com"}
+	if !slices.Equal(got, want) {
+		t.Errorf("email_canonical after migration = %q, want %q", got, want)
+	}
+
+	for _, email := range []string{
+		"  Budi@Example.COM \n", "B.udi+Promo@GMail.com", "budi@googlemail.com", "Budi+Promo@example.com",
+		"+Promo@GoogleMail.com", ".@gmail.com", "No-At-Sign", `"A@b"@Example.com`, "a@b@GMAIL.com",
+		"\t Budi@Example.com\v\f\r", "\u00a0Budi@Example.com\u00a0", "\u2003B.udi@GMail.com",
+	} {
+		got := queryStrings(t, pool, "SELECT canonical_email($1)", email)
+		if want := helper.CanonicalEmail(email); len(got) != 1 || got[0] != want {
+			t.Errorf("canonical_email(%q) = %q, helper.CanonicalEmail = %q", email, got, want)
+		}
+	}
+
+	if err := m.Down(1); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+}
+
+func queryStrings(t *testing.T, pool *pgxpool.Pool, sql string, args ...any) []string {
+	t.Helper()
+	rows, err := pool.Query(context.Background(), sql, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
@@ -9,16 +9,54 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type User struct {
+type EmailChange struct {
+	ID               uuid.UUID          `json:"id"`
+	UserID           uuid.UUID          `json:"user_id"`
+	OldEmail         string             `json:"old_email"`
+	NewEmail         string             `json:"new_email"`
+	ConfirmTokenHash []byte             `json:"confirm_token_hash"`
+	ExpiresAt        pgtype.Timestamptz `json:"expires_at"`
+	ConfirmedAt      pgtype.Timestamptz `json:"confirmed_at"`
+	RevertTokenHash  []byte             `json:"revert_token_hash"`
+	RevertExpiresAt  pgtype.Timestamptz `json:"revert_expires_at"`
+	RevertedAt       pgtype.Timestamptz `json:"reverted_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type PhoneOtp struct {
 	ID          uuid.UUID          `json:"id"`
-	Email       string             `json:"email"`
-	FullName    pgtype.Text        `json:"full_name"`
-	PhoneNumber pgtype.Text        `json:"phone_number"`
-	Role        string             `json:"role"`
-	AvatarUrl   pgtype.Text        `json:"avatar_url"`
+	UserID      uuid.UUID          `json:"user_id"`
+	PhoneNumber string             `json:"phone_number"`
+	CodeHash    []byte             `json:"code_hash"`
+	Attempts    int32              `json:"attempts"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	UsedAt      pgtype.Timestamptz `json:"used_at"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt   pgtype.Timestamptz `json:"deleted_at"`
+}
+
+type PhoneOtpSend struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	PhoneNumber string             `json:"phone_number"`
+	SentAt      pgtype.Timestamptz `json:"sent_at"`
+}
+
+type User struct {
+	ID                uuid.UUID          `json:"id"`
+	Email             string             `json:"email"`
+	FullName          pgtype.Text        `json:"full_name"`
+	PhoneNumber       pgtype.Text        `json:"phone_number"`
+	Role              string             `json:"role"`
+	AvatarUrl         pgtype.Text        `json:"avatar_url"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt         pgtype.Timestamptz `json:"deleted_at"`
+	EmailVerifiedAt   pgtype.Timestamptz `json:"email_verified_at"`
+	PhoneVerifiedAt   pgtype.Timestamptz `json:"phone_verified_at"`
+	PasswordHash      pgtype.Text        `json:"password_hash"`
+	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
+	SessionVersion    int32              `json:"session_version"`
+	EmailCanonical    string             `json:"email_canonical"`
 }
 
 type UserMetadatum struct {
@@ -26,3 +64,13 @@ type UserMetadatum struct {
 	Metadata  []byte             `json:"metadata"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
+
+type UserToken struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	Purpose   string             `json:"purpose"`
+	TokenHash []byte             `json:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
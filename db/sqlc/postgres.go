@@ -3,28 +3,121 @@ package db
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 
 	"user-service/config"
-	logger "user-service/pkg"
+	"user-service/pkg/secrets"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // konek db
 func PostgresDB(ctx context.Context, config *config.AppConfig) *pgxpool.Pool {
-	dbUrl := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", config.DB.User, config.DB.Password, config.DB.Host, config.DB.Port, config.DB.Name)
-	pool, err := pgxpool.New(ctx, dbUrl)
+	pool, err := Connect(ctx, config)
 	if err != nil {
+		log.Errorf("failed to connect to database: %v", err)
 		panic(err)
 	}
+	return pool
+}
+
+// Connect sama seperti PostgresDB tapi mengembalikan error alih-alih panic, dipakai oleh CLI.
+// Password dari file/secret provider diambil ulang di background sampai ctx selesai, jadi ctx harus
+// dibatalkan saat pool tidak dipakai lagi.
+func Connect(ctx context.Context, config *config.AppConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := NewPoolConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+
+	// password dari file/secret provider diambil ulang berkala; koneksi baru memakai nilai terbaru
+	if provider, name, ok := config.SecretSource("db.password"); ok && config.DB.URL == "" {
+		password := secrets.NewRefreshing(provider, name, config.DB.Password)
+		password.OnError = func(err error) {
+			log.Warnf("failed to refresh database password: %v", err)
+		}
+		password.OnChange = func() {
+			log.Info("database password changed, new connections will use it")
+		}
+		password.Start(ctx, config.Secrets.RefreshInterval)
+
+		poolConfig.BeforeConnect = func(_ context.Context, cc *pgx.ConnConfig) error {
+			cc.Password = password.Get()
+			return nil
+		}
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	pool.Config().MaxConns = int32(config.DB.MaxOpenConns)
-	pool.Config().MaxConnLifetime = config.DB.ConnMaxLifetime
-	pool.Config().MinConns = 2
 	if err := pool.Ping(ctx); err != nil {
-		logger.Log.Errorf("failed to ping database: %v", err)
-		panic(err)
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
-	logger.Log.Info("connected to database")
-	return pool
+	log.Infof("connected to database %s@%s/%s", poolConfig.ConnConfig.User, net.JoinHostPort(poolConfig.ConnConfig.Host, strconv.Itoa(int(poolConfig.ConnConfig.Port))), poolConfig.ConnConfig.Database)
+	return pool, nil
+}
+
+// NewPoolConfig membangun pgxpool.Config dari config. Semua pengaturan pool harus di-set
+// di sini sebelum pool dibuat; mengubah pool.Config() setelah pgxpool.New tidak berpengaruh.
+func NewPoolConfig(config *config.AppConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(ConnString(config.DB))
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = int32(config.DB.MaxOpenConns)
+	poolConfig.MinConns = int32(config.DB.MinConns)
+	poolConfig.MaxConnLifetime = config.DB.ConnMaxLifetime
+	poolConfig.MaxConnIdleTime = config.DB.ConnMaxIdleTime
+	poolConfig.HealthCheckPeriod = config.DB.HealthCheckPeriod
+
+	connConfig := poolConfig.ConnConfig
+	if config.DB.ConnectTimeout > 0 && connConfig.ConnectTimeout == 0 {
+		connConfig.ConnectTimeout = config.DB.ConnectTimeout
+	}
+	// parameter yang sudah ada di DATABASE_URL tidak ditimpa
+	if _, ok := connConfig.RuntimeParams["application_name"]; !ok && config.DB.ApplicationName != "" {
+		connConfig.RuntimeParams["application_name"] = config.DB.ApplicationName
+	}
+	if _, ok := connConfig.RuntimeParams["statement_timeout"]; !ok && config.DB.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(config.DB.StatementTimeout.Milliseconds(), 10)
+	}
+	connConfig.Tracer = NewQueryTracer()
+
+	return poolConfig, nil
+}
+
+// ConnString mengembalikan DATABASE_URL jika di-set, selain itu membangun URL dari field DB
+// dengan escaping yang benar untuk user/password yang mengandung karakter khusus.
+func ConnString(db config.DBConfig) string {
+	if db.URL != "" {
+		return db.URL
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", db.SSLMode)
+	if db.SSLRootCert != "" {
+		query.Set("sslrootcert", db.SSLRootCert)
+	}
+	if db.SSLCert != "" {
+		query.Set("sslcert", db.SSLCert)
+	}
+	if db.SSLKey != "" {
+		query.Set("sslkey", db.SSLKey)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(db.User, db.Password),
+		Host:     net.JoinHostPort(db.Host, db.Port),
+		Path:     "/" + db.Name,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
 }
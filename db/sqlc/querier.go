@@ -8,16 +8,39 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	ClaimPhoneOTPAttempt(ctx context.Context, arg ClaimPhoneOTPAttemptParams) (PhoneOtp, error)
+	ConfirmEmailChange(ctx context.Context, arg ConfirmEmailChangeParams) (EmailChange, error)
+	ConsumePhoneOTP(ctx context.Context, id uuid.UUID) (PhoneOtp, error)
+	ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error)
+	CreateEmailChange(ctx context.Context, arg CreateEmailChangeParams) (EmailChange, error)
+	CreatePhoneOTP(ctx context.Context, arg CreatePhoneOTPParams) (PhoneOtp, error)
+	CreatePhoneOTPSend(ctx context.Context, arg CreatePhoneOTPSendParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateUserMetadata(ctx context.Context, arg CreateUserMetadataParams) error
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
+	DeletePendingEmailChanges(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeletePhoneOTPs(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) (int64, error)
+	GetPendingPhoneOTP(ctx context.Context, userID uuid.UUID) (PhoneOtp, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserMetadata(ctx context.Context, userID uuid.UUID) (UserMetadatum, error)
+	GetUserToken(ctx context.Context, arg GetUserTokenParams) (UserToken, error)
 	GetUserWithMetadata(ctx context.Context, id uuid.UUID) (GetUserWithMetadataRow, error)
+	ListPhoneOTPSends(ctx context.Context, arg ListPhoneOTPSendsParams) ([]pgtype.Timestamptz, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) (User, error)
+	MarkPhoneVerified(ctx context.Context, arg MarkPhoneVerifiedParams) (User, error)
+	ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error)
+	RevertEmailChange(ctx context.Context, revertTokenHash []byte) (EmailChange, error)
+	SoftDeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
+	UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) (User, error)
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
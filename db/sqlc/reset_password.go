@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ResetPassword memakai token reset password, mengganti password user dan menaikkan session_version dalam satu
+// transaksi, jadi semua access token user yang sudah diterbitkan tidak berlaku lagi. Token reset lain yang belum
+// dipakai ikut dihapus. Mengembalikan pgx.ErrNoRows jika token tidak ada, sudah dipakai, kedaluwarsa, atau
+// user-nya sudah dihapus; dalam kasus terakhir token tidak ikut terpakai.
+func (s *store) ResetPassword(ctx context.Context, tokenHash []byte, passwordHash string) (User, error) {
+	var user User
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		token, err := q.ConsumeUserToken(ctx, ConsumeUserTokenParams{
+			TokenHash: tokenHash,
+			Purpose:   TokenPurposeResetPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = q.ResetUserPassword(ctx, ResetUserPasswordParams{
+			ID:           token.UserID,
+			PasswordHash: pgtype.Text{String: passwordHash, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.DeleteUserTokens(ctx, DeleteUserTokensParams{UserID: user.ID, Purpose: TokenPurposeResetPassword})
+		return err
+	})
+	return user, err
+}
@@ -5,15 +5,24 @@ import (
 	"fmt"
 
 	logger "user-service/pkg"
+	"user-service/pkg/tracing"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var log = logger.Named("db")
+
 type Store interface {
 	Querier
 	// tambahkan method lain kalo di butuhin
 	CreateUserWithMetadata(ctx context.Context, arg CreateuserWithMetadataParams) (CreateUserTxResult, error)
+	VerifyEmail(ctx context.Context, tokenHash []byte) (User, error)
+	ApplyEmailChange(ctx context.Context, arg ConfirmEmailChangeParams) (EmailChangeTxResult, error)
+	UndoEmailChange(ctx context.Context, revertTokenHash []byte) (EmailChangeTxResult, error)
+	VerifyPhone(ctx context.Context, otpID uuid.UUID) (User, error)
+	ResetPassword(ctx context.Context, tokenHash []byte, passwordHash string) (User, error)
 }
 
 type store struct {
@@ -28,7 +37,10 @@ func NewStore(db *pgxpool.Pool) Store {
 	}
 }
 
-func (s *store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+func (s *store) ExecTx(ctx context.Context, fn func(*Queries) error) (err error) {
+	ctx, span := tracing.Start(ctx, "db.ExecTx")
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
@@ -39,7 +51,7 @@ func (s *store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
 	err = fn(q)
 	if err != nil {
 		if rollbackError := tx.Rollback(ctx); rollbackError != nil {
-			logger.Log.Errorf("tx Error %v \n Rollback Error %v", err, rollbackError)
+			log.FromContext(ctx).Errorf("tx Error %v \n Rollback Error %v", err, rollbackError)
 			return fmt.Errorf("tx Error %v \n Rollback Error %v", err, rollbackError)
 		}
 		return err
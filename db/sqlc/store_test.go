@@ -0,0 +1,80 @@
+package db_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"user-service/config"
+	"user-service/db/migrations"
+	db "user-service/db/sqlc"
+	"user-service/db/storetest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TestConformance menjalankan storetest terhadap Postgres sungguhan. Di-skip jika TEST_DATABASE_URL
+// tidak di-set. Semua data di database tersebut dihapus, jangan arahkan ke database development.
+func TestConformance(t *testing.T) {
+	cfg := testConfig(t)
+	m := newMigrator(t, cfg)
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	pool := connect(t, cfg)
+
+	storetest.Run(t, storetest.Harness{
+		New: func(t *testing.T) db.Store {
+			exec(t, pool, "TRUNCATE users, user_metadata, user_tokens, email_changes, phone_otps, phone_otp_sends")
+			return db.NewStore(pool)
+		},
+		FailMetadata: func(t *testing.T, _ db.Store) {
+			// NOT VALID: hanya baris baru yang diperiksa, jadi setiap insert metadata berikutnya gagal
+			exec(t, pool, "ALTER TABLE user_metadata ADD CONSTRAINT storetest_fail CHECK (false) NOT VALID")
+			t.Cleanup(func() {
+				exec(t, pool, "ALTER TABLE user_metadata DROP CONSTRAINT storetest_fail")
+			})
+		},
+	})
+}
+
+// testConfig mengembalikan config untuk TEST_DATABASE_URL, atau men-skip test jika tidak di-set.
+func testConfig(t *testing.T) *config.AppConfig {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	cfg, err := config.LoadConfig([]string{"--db.url=" + url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func newMigrator(t *testing.T, cfg *config.AppConfig) *migrations.Migrator {
+	t.Helper()
+	m, err := migrations.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func connect(t *testing.T, cfg *config.AppConfig) *pgxpool.Pool {
+	t.Helper()
+	pool, err := db.Connect(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func exec(t *testing.T, pool *pgxpool.Pool, sql string) {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), sql); err != nil {
+		t.Fatalf("%s: %v", sql, err)
+	}
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"user-service/pkg/metrics"
+	"user-service/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	name  string
+	start time.Time
+	span  trace.Span
+}
+
+// queryTracer mencatat durasi dan span setiap query sqlc berdasarkan nama query-nya.
+type queryTracer struct{}
+
+// NewQueryTracer mengembalikan pgx.QueryTracer untuk dipasang di pgxpool.Config.ConnConfig.Tracer.
+func NewQueryTracer() pgx.QueryTracer {
+	return &queryTracer{}
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := QueryName(data.SQL)
+	spanName := name
+	if name == "other" {
+		spanName = statementKeyword(data.SQL)
+	}
+
+	ctx, span := tracing.Start(ctx, "db."+spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", spanName),
+			attribute.String("db.query.text", data.SQL),
+		),
+	)
+
+	return context.WithValue(ctx, queryTraceKey{}, queryTrace{
+		name:  name,
+		start: time.Now(),
+		span:  span,
+	})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	qt, ok := ctx.Value(queryTraceKey{}).(queryTrace)
+	if !ok {
+		return
+	}
+
+	status := "ok"
+	var err error
+	if data.Err != nil && data.Err != pgx.ErrNoRows {
+		status = "error"
+		err = data.Err
+	}
+	metrics.DBQueryDuration.WithLabelValues(qt.name, status).Observe(time.Since(qt.start).Seconds())
+
+	qt.span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	tracing.End(qt.span, err)
+}
+
+// QueryName mengambil nama query dari komentar sqlc "-- name: CreateUser :one".
+// Query di luar sqlc (BEGIN, COMMIT, dll) dikembalikan sebagai "other".
+func QueryName(sql string) string {
+	const prefix = "-- name: "
+	if !strings.HasPrefix(sql, prefix) {
+		return "other"
+	}
+	rest := sql[len(prefix):]
+	if end := strings.IndexAny(rest, " \n"); end > 0 {
+		return rest[:end]
+	}
+	return "other"
+}
+
+// statementKeyword mengambil kata pertama dari statement, misalnya "begin" atau "commit".
+func statementKeyword(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "other"
+	}
+	return strings.ToLower(fields[0])
+}
@@ -0,0 +1,30 @@
+package db_test
+
+import (
+	"testing"
+
+	db "user-service/db/sqlc"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"-- name: CreateUser :one\nINSERT INTO users (email) VALUES ($1)", "CreateUser"},
+		{"-- name: DeleteUser :exec\nDELETE FROM users WHERE id = $1", "DeleteUser"},
+		// sqlc selalu menulis nama diikuti spasi dan jenis query, tapi baris nama saja tetap terbaca
+		{"-- name: ListUsers\nSELECT 1", "ListUsers"},
+		{"begin", "other"},
+		{"SELECT 1 -- name: Hidden :one", "other"},
+		{"  -- name: Indented :one\nSELECT 1", "other"},
+		{"-- name: ", "other"},
+		{"-- name: Unterminated", "other"},
+		{"", "other"},
+	}
+	for _, tt := range tests {
+		if got := db.QueryName(tt.sql); got != tt.want {
+			t.Errorf("QueryName(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
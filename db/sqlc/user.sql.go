@@ -18,7 +18,7 @@ INSERT INTO users (
 ) VALUES (
     $1, $2, $3, $4, $5
 )
-RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at
+RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
 `
 
 type CreateUserParams struct {
@@ -48,12 +48,18 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL
+SELECT id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical FROM users WHERE email_canonical = canonical_email($1) AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -69,12 +75,18 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL
+SELECT id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical FROM users WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -90,6 +102,12 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
 	)
 	return i, err
 }
@@ -144,7 +162,7 @@ func (q *Queries) GetUserWithMetadata(ctx context.Context, id uuid.UUID) (GetUse
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at
+SELECT id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
 FROM users
 WHERE deleted_at IS NULL
   AND (
@@ -180,6 +198,12 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.EmailVerifiedAt,
+			&i.PhoneVerifiedAt,
+			&i.PasswordHash,
+			&i.PasswordChangedAt,
+			&i.SessionVersion,
+			&i.EmailCanonical,
 		); err != nil {
 			return nil, err
 		}
@@ -190,3 +214,223 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	}
 	return items, nil
 }
+
+const markEmailVerified = `-- name: MarkEmailVerified :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, now()), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
+`
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, markEmailVerified, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
+	)
+	return i, err
+}
+
+const markPhoneVerified = `-- name: MarkPhoneVerified :one
+UPDATE users
+SET phone_verified_at = now(), updated_at = now()
+WHERE id = $1 AND phone_number = $2 AND deleted_at IS NULL
+RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
+`
+
+type MarkPhoneVerifiedParams struct {
+	ID          uuid.UUID   `json:"id"`
+	PhoneNumber pgtype.Text `json:"phone_number"`
+}
+
+func (q *Queries) MarkPhoneVerified(ctx context.Context, arg MarkPhoneVerifiedParams) (User, error) {
+	row := q.db.QueryRow(ctx, markPhoneVerified, arg.ID, arg.PhoneNumber)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
+	)
+	return i, err
+}
+
+const resetUserPassword = `-- name: ResetUserPassword :one
+UPDATE users
+SET password_hash = $2, password_changed_at = now(), session_version = session_version + 1, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
+`
+
+type ResetUserPasswordParams struct {
+	ID           uuid.UUID   `json:"id"`
+	PasswordHash pgtype.Text `json:"password_hash"`
+}
+
+func (q *Queries) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, resetUserPassword, arg.ID, arg.PasswordHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
+	)
+	return i, err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :execrows
+UPDATE users
+SET deleted_at = now(), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserAvatar = `-- name: UpdateUserAvatar :one
+UPDATE users
+SET avatar_url = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
+`
+
+type UpdateUserAvatarParams struct {
+	ID        uuid.UUID   `json:"id"`
+	AvatarUrl pgtype.Text `json:"avatar_url"`
+}
+
+func (q *Queries) UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserAvatar, arg.ID, arg.AvatarUrl)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1, email_verified_at = now(), updated_at = now()
+WHERE id = $2 AND email = $3 AND deleted_at IS NULL
+RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
+`
+
+type UpdateUserEmailParams struct {
+	NewEmail string    `json:"new_email"`
+	ID       uuid.UUID `json:"id"`
+	OldEmail string    `json:"old_email"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserEmail, arg.NewEmail, arg.ID, arg.OldEmail)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, email, full_name, phone_number, role, avatar_url, created_at, updated_at, deleted_at, email_verified_at, phone_verified_at, password_hash, password_changed_at, session_version, email_canonical
+`
+
+type UpdateUserRoleParams struct {
+	ID   uuid.UUID `json:"id"`
+	Role string    `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+		&i.PhoneVerifiedAt,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.SessionVersion,
+		&i.EmailCanonical,
+	)
+	return i, err
+}
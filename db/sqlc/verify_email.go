@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+)
+
+// Purpose token di tabel user_tokens, harus sama dengan constraint valid_purpose.
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// VerifyEmail memakai token verifikasi dan menandai email user terverifikasi dalam satu transaksi.
+// Mengembalikan pgx.ErrNoRows jika token tidak ada, sudah dipakai, kedaluwarsa, atau user-nya sudah dihapus;
+// dalam kasus terakhir token tidak ikut terpakai.
+func (s *store) VerifyEmail(ctx context.Context, tokenHash []byte) (User, error) {
+	var user User
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		token, err := q.ConsumeUserToken(ctx, ConsumeUserTokenParams{
+			TokenHash: tokenHash,
+			Purpose:   TokenPurposeVerifyEmail,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = q.MarkEmailVerified(ctx, token.UserID)
+		return err
+	})
+	return user, err
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// VerifyPhone memakai kode OTP otpID dan menandai nomor telepon user terverifikasi dalam satu transaksi.
+// Mengembalikan pgx.ErrNoRows jika kode sudah dipakai, user-nya sudah dihapus, atau nomor telepon user
+// sudah bukan nomor yang dikirimi kode; dalam dua kasus terakhir kode tidak ikut terpakai.
+func (s *store) VerifyPhone(ctx context.Context, otpID uuid.UUID) (User, error) {
+	var user User
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		otp, err := q.ConsumePhoneOTP(ctx, otpID)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.MarkPhoneVerified(ctx, MarkPhoneVerifiedParams{
+			ID:          otp.UserID,
+			PhoneNumber: pgtype.Text{String: otp.PhoneNumber, Valid: true},
+		})
+		return err
+	})
+	return user, err
+}
@@ -0,0 +1,1080 @@
+// Package storetest berisi conformance suite yang harus lulus untuk setiap implementasi db.Store
+// (Postgres, memstore, dan implementasi lain nantinya), supaya semantiknya tidak saling menyimpang.
+//
+// Pemakaian dari file _test.go milik implementasi:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, storetest.Harness{
+//			New:          func(t *testing.T) db.Store { return memstore.New() },
+//			FailMetadata: func(t *testing.T, s db.Store) { s.(*memstore.Store).Fail("CreateUserMetadata", errors.New("fail")) },
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"user-service/constants"
+	db "user-service/db/sqlc"
+	"user-service/pkg/helper"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Harness menghubungkan suite dengan satu implementasi db.Store.
+type Harness struct {
+	// New mengembalikan store kosong. Dipanggil sekali per subtest; subtest tidak dijalankan paralel.
+	New func(t *testing.T) db.Store
+	// FailMetadata membuat insert user_metadata berikutnya di store gagal, untuk menguji rollback
+	// CreateUserWithMetadata. Jika nil, test rollback dilewati.
+	FailMetadata func(t *testing.T, s db.Store)
+}
+
+// Run menjalankan seluruh suite terhadap h.
+func Run(t *testing.T, h Harness) {
+	tests := []struct {
+		name string
+		fn   func(*testing.T, Harness)
+	}{
+		{"CreateUser", testCreateUser},
+		{"UniqueEmail", testUniqueEmail},
+		{"RoleConstraint", testRoleConstraint},
+		{"SoftDeleteVisibility", testSoftDeleteVisibility},
+		{"UpdateUserRole", testUpdateUserRole},
+		{"UpdateUserAvatar", testUpdateUserAvatar},
+		{"UserTokens", testUserTokens},
+		{"VerifyEmail", testVerifyEmail},
+		{"EmailChanges", testEmailChanges},
+		{"ApplyAndUndoEmailChange", testApplyAndUndoEmailChange},
+		{"PhoneOTPs", testPhoneOTPs},
+		{"PhoneOTPSends", testPhoneOTPSends},
+		{"VerifyPhone", testVerifyPhone},
+		{"ResetPassword", testResetPassword},
+		{"Metadata", testMetadata},
+		{"Search", testSearch},
+		{"OrderingAndPaging", testOrderingAndPaging},
+		{"Rollback", testRollback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, h)
+		})
+	}
+}
+
+func testCreateUser(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	arg := userParams("budi@example.com", "Budi Santoso")
+	arg.PhoneNumber = helper.StringToPGText("+6281234567890")
+	arg.AvatarUrl = helper.StringToPGText("https://example.com/budi.png")
+
+	user, err := s.CreateUser(ctx, arg)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if user.ID == uuid.Nil {
+		t.Error("ID is not generated")
+	}
+	if !user.CreatedAt.Valid || !user.UpdatedAt.Valid {
+		t.Error("created_at and updated_at must be set")
+	}
+	if user.DeletedAt.Valid {
+		t.Error("deleted_at must be NULL for a new user")
+	}
+	if user.Email != arg.Email || user.FullName != arg.FullName || user.PhoneNumber != arg.PhoneNumber ||
+		user.Role != arg.Role || user.AvatarUrl != arg.AvatarUrl {
+		t.Errorf("CreateUser returned %+v, want the values of %+v", user, arg)
+	}
+
+	byID, err := s.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	assertSameUser(t, byID, user)
+
+	byEmail, err := s.GetUserByEmail(ctx, user.Email)
+	if err != nil {
+		t.Fatalf("GetUserByEmail: %v", err)
+	}
+	assertSameUser(t, byEmail, user)
+
+	if _, err := s.GetUserByID(ctx, uuid.New()); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByID of an unknown id: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.GetUserByEmail(ctx, "nobody@example.com"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByEmail of an unknown email: got %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func testUniqueEmail(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+
+	_, err := s.CreateUser(ctx, userParams("budi@example.com", "Budi Lain"))
+	assertCode(t, "duplicate email", err, db.UniqueViolation)
+
+	_, err = s.CreateUserWithMetadata(ctx, db.CreateuserWithMetadataParams{
+		CreateUserParams: userParams("budi@example.com", "Budi Lain"),
+		UserMetadata:     db.UserMetadata{Device: "web"},
+	})
+	assertCode(t, "duplicate email through CreateUserWithMetadata", err, db.UniqueViolation)
+
+	// huruf besar/kecil tidak membedakan email
+	_, err = s.CreateUser(ctx, userParams("Budi@Example.com", "Budi Kapital"))
+	assertCode(t, "email that differs only in case", err, db.UniqueViolation)
+	byEmail, err := s.GetUserByEmail(ctx, "BUDI@example.COM")
+	if err != nil {
+		t.Fatalf("GetUserByEmail ignoring case: %v", err)
+	}
+	assertSameUser(t, byEmail, user)
+	other := mustCreate(t, s, userParams("siti@example.com", "Siti"))
+	_, err = s.UpdateUserEmail(ctx, db.UpdateUserEmailParams{NewEmail: "BUDI@example.com", ID: other.ID, OldEmail: other.Email})
+	assertCode(t, "UpdateUserEmail to a taken email", err, db.UniqueViolation)
+	// alamat Gmail dibandingkan dalam bentuk kanonik walaupun disimpan apa adanya
+	gmail := mustCreate(t, s, userParams("B.udi+Promo@gmail.com", "Budi Gmail"))
+	if gmail.EmailCanonical != "budi@gmail.com" {
+		t.Errorf("EmailCanonical = %q, want budi@gmail.com", gmail.EmailCanonical)
+	}
+	_, err = s.CreateUser(ctx, userParams("budi@googlemail.com", "Budi Googlemail"))
+	assertCode(t, "Gmail address with the same canonical form", err, db.UniqueViolation)
+	byEmail, err = s.GetUserByEmail(ctx, "bu.di@GoogleMail.com")
+	if err != nil {
+		t.Fatalf("GetUserByEmail of a Gmail variant: %v", err)
+	}
+	assertSameUser(t, byEmail, gmail)
+	// mengganti huruf besar/kecil email sendiri tidak bentrok dengan dirinya
+	if _, err := s.UpdateUserEmail(ctx, db.UpdateUserEmailParams{NewEmail: "Siti@example.com", ID: other.ID, OldEmail: other.Email}); err != nil {
+		t.Errorf("UpdateUserEmail changing only case: %v", err)
+	}
+
+	// index unik parsial: email user yang sudah dihapus boleh dipakai lagi
+	if _, err := s.SoftDeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("SoftDeleteUser: %v", err)
+	}
+	if _, err := s.GetUserByEmail(ctx, "budi@example.com"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByEmail of a soft-deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+	reused := mustCreate(t, s, userParams("budi@example.com", "Budi Baru"))
+	if reused.ID == user.ID {
+		t.Error("reused email returned the soft-deleted user")
+	}
+	_, err = s.CreateUser(ctx, userParams("BUDI@example.com", "Budi Lain"))
+	assertCode(t, "duplicate of a reused email", err, db.UniqueViolation)
+}
+
+func testRoleConstraint(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	for _, role := range constants.Roles {
+		arg := userParams(role+"@example.com", role)
+		arg.Role = role
+		mustCreate(t, s, arg)
+	}
+
+	arg := userParams("owner@example.com", "Owner")
+	arg.Role = "owner"
+	_, err := s.CreateUser(ctx, arg)
+	assertCode(t, "unknown role", err, "23514")
+}
+
+func testSoftDeleteVisibility(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	deleted := mustCreate(t, s, userParams("deleted@example.com", "Deleted"))
+	kept := mustCreate(t, s, userParams("kept@example.com", "Kept"))
+	if err := s.CreateUserMetadata(ctx, db.CreateUserMetadataParams{UserID: deleted.ID, Metadata: []byte(`{"device":"web"}`)}); err != nil {
+		t.Fatalf("CreateUserMetadata: %v", err)
+	}
+
+	rows, err := s.SoftDeleteUser(ctx, deleted.ID)
+	if err != nil || rows != 1 {
+		t.Fatalf("SoftDeleteUser = %d, %v; want 1, nil", rows, err)
+	}
+	rows, err = s.SoftDeleteUser(ctx, deleted.ID)
+	if err != nil || rows != 0 {
+		t.Errorf("second SoftDeleteUser = %d, %v; want 0, nil", rows, err)
+	}
+	rows, err = s.SoftDeleteUser(ctx, uuid.New())
+	if err != nil || rows != 0 {
+		t.Errorf("SoftDeleteUser of an unknown id = %d, %v; want 0, nil", rows, err)
+	}
+
+	if _, err := s.GetUserByID(ctx, deleted.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByID of a deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.GetUserByEmail(ctx, deleted.Email); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByEmail of a deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.GetUserWithMetadata(ctx, deleted.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserWithMetadata of a deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.UpdateUserRole(ctx, db.UpdateUserRoleParams{ID: deleted.ID, Role: constants.RoleSuperadmin}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("UpdateUserRole of a deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+	// GetUserMetadata tidak memfilter deleted_at
+	if _, err := s.GetUserMetadata(ctx, deleted.ID); err != nil {
+		t.Errorf("GetUserMetadata of a deleted user: %v", err)
+	}
+
+	assertEmails(t, listAll(t, s), kept.Email)
+
+	if _, err := s.GetUserByID(ctx, kept.ID); err != nil {
+		t.Errorf("GetUserByID of a kept user: %v", err)
+	}
+}
+
+func testUpdateUserRole(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+
+	updated, err := s.UpdateUserRole(ctx, db.UpdateUserRoleParams{ID: user.ID, Role: constants.RoleTenantAdmin})
+	if err != nil {
+		t.Fatalf("UpdateUserRole: %v", err)
+	}
+	if updated.Role != constants.RoleTenantAdmin {
+		t.Errorf("role = %q, want %q", updated.Role, constants.RoleTenantAdmin)
+	}
+	if updated.UpdatedAt.Time.Before(user.UpdatedAt.Time) {
+		t.Errorf("updated_at went backwards: %v -> %v", user.UpdatedAt.Time, updated.UpdatedAt.Time)
+	}
+	if !updated.CreatedAt.Time.Equal(user.CreatedAt.Time) {
+		t.Errorf("created_at changed: %v -> %v", user.CreatedAt.Time, updated.CreatedAt.Time)
+	}
+
+	_, err = s.UpdateUserRole(ctx, db.UpdateUserRoleParams{ID: user.ID, Role: "owner"})
+	assertCode(t, "UpdateUserRole to an unknown role", err, "23514")
+
+	if _, err := s.UpdateUserRole(ctx, db.UpdateUserRoleParams{ID: uuid.New(), Role: constants.RoleUser}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("UpdateUserRole of an unknown id: got %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func testUpdateUserAvatar(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+
+	url := "https://cdn.example.com/avatars/budi/256.jpg?v=1"
+	updated, err := s.UpdateUserAvatar(ctx, db.UpdateUserAvatarParams{ID: user.ID, AvatarUrl: helper.StringToPGText(url)})
+	if err != nil {
+		t.Fatalf("UpdateUserAvatar: %v", err)
+	}
+	if updated.AvatarUrl.String != url {
+		t.Errorf("avatar_url = %q, want %q", updated.AvatarUrl.String, url)
+	}
+	if updated.UpdatedAt.Time.Before(user.UpdatedAt.Time) {
+		t.Errorf("updated_at went backwards: %v -> %v", user.UpdatedAt.Time, updated.UpdatedAt.Time)
+	}
+
+	// string kosong menghapus avatar (NULL)
+	cleared, err := s.UpdateUserAvatar(ctx, db.UpdateUserAvatarParams{ID: user.ID, AvatarUrl: helper.StringToPGText("")})
+	if err != nil {
+		t.Fatalf("UpdateUserAvatar to NULL: %v", err)
+	}
+	if cleared.AvatarUrl.Valid {
+		t.Errorf("avatar_url = %q, want NULL", cleared.AvatarUrl.String)
+	}
+
+	if _, err := s.UpdateUserAvatar(ctx, db.UpdateUserAvatarParams{ID: uuid.New(), AvatarUrl: helper.StringToPGText(url)}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("UpdateUserAvatar of an unknown id: got %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func testUserTokens(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+	hash := []byte("hash-1")
+	token := mustCreateToken(t, s, user.ID, hash, time.Hour)
+	if token.UserID != user.ID || token.Purpose != db.TokenPurposeVerifyEmail || token.UsedAt.Valid {
+		t.Errorf("created token = %+v", token)
+	}
+
+	_, err := s.CreateUserToken(ctx, tokenParams(user.ID, hash, time.Hour))
+	assertCode(t, "duplicate token hash", err, db.UniqueViolation)
+	arg := tokenParams(uuid.New(), []byte("hash-2"), time.Hour)
+	_, err = s.CreateUserToken(ctx, arg)
+	assertCode(t, "token of an unknown user", err, "23503")
+	arg = tokenParams(user.ID, []byte("hash-3"), time.Hour)
+	arg.Purpose = "login"
+	_, err = s.CreateUserToken(ctx, arg)
+	assertCode(t, "unknown purpose", err, "23514")
+
+	// GetUserToken tidak memakai token
+	lookup := db.GetUserTokenParams{TokenHash: hash, Purpose: db.TokenPurposeVerifyEmail}
+	for range 2 {
+		if got, err := s.GetUserToken(ctx, lookup); err != nil || got.ID != token.ID || got.UsedAt.Valid {
+			t.Errorf("GetUserToken = %+v, %v", got, err)
+		}
+	}
+	if _, err := s.GetUserToken(ctx, db.GetUserTokenParams{TokenHash: hash, Purpose: db.TokenPurposeResetPassword}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserToken with another purpose: got %v, want pgx.ErrNoRows", err)
+	}
+
+	// purpose harus cocok
+	if _, err := s.ConsumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: hash, Purpose: "login"}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ConsumeUserToken with another purpose: got %v, want pgx.ErrNoRows", err)
+	}
+	used, err := s.ConsumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: hash, Purpose: db.TokenPurposeVerifyEmail})
+	if err != nil {
+		t.Fatalf("ConsumeUserToken: %v", err)
+	}
+	if used.ID != token.ID || !used.UsedAt.Valid {
+		t.Errorf("consumed token = %+v", used)
+	}
+	if _, err := s.ConsumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: hash, Purpose: db.TokenPurposeVerifyEmail}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second ConsumeUserToken: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.GetUserToken(ctx, lookup); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserToken of a used token: got %v, want pgx.ErrNoRows", err)
+	}
+
+	expired := mustCreateToken(t, s, user.ID, []byte("hash-expired"), -time.Minute)
+	if _, err := s.ConsumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: expired.TokenHash, Purpose: db.TokenPurposeVerifyEmail}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ConsumeUserToken of an expired token: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.GetUserToken(ctx, db.GetUserTokenParams{TokenHash: expired.TokenHash, Purpose: db.TokenPurposeVerifyEmail}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserToken of an expired token: got %v, want pgx.ErrNoRows", err)
+	}
+
+	// DeleteUserTokens hanya menghapus token yang belum dipakai, termasuk yang kedaluwarsa
+	pending := mustCreateToken(t, s, user.ID, []byte("hash-pending"), time.Hour)
+	rows, err := s.DeleteUserTokens(ctx, db.DeleteUserTokensParams{UserID: user.ID, Purpose: db.TokenPurposeVerifyEmail})
+	if err != nil || rows != 2 {
+		t.Errorf("DeleteUserTokens = %d, %v; want 2, nil", rows, err)
+	}
+	if _, err := s.ConsumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: pending.TokenHash, Purpose: db.TokenPurposeVerifyEmail}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ConsumeUserToken of a deleted token: got %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func testVerifyEmail(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+	if user.EmailVerifiedAt.Valid {
+		t.Fatal("email_verified_at must be NULL for a new user")
+	}
+	mustCreateToken(t, s, user.ID, []byte("hash-1"), time.Hour)
+	mustCreateToken(t, s, user.ID, []byte("hash-2"), time.Hour)
+
+	verified, err := s.VerifyEmail(ctx, []byte("hash-1"))
+	if err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if verified.ID != user.ID || !verified.EmailVerifiedAt.Valid {
+		t.Errorf("verified user = %+v", verified)
+	}
+	if _, err := s.VerifyEmail(ctx, []byte("hash-1")); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("VerifyEmail with a used token: got %v, want pgx.ErrNoRows", err)
+	}
+
+	// verifikasi berikutnya tidak mengubah waktu verifikasi pertama
+	again, err := s.VerifyEmail(ctx, []byte("hash-2"))
+	if err != nil {
+		t.Fatalf("second VerifyEmail: %v", err)
+	}
+	if !again.EmailVerifiedAt.Time.Equal(verified.EmailVerifiedAt.Time) {
+		t.Errorf("email_verified_at changed: %v -> %v", verified.EmailVerifiedAt.Time, again.EmailVerifiedAt.Time)
+	}
+	if _, err := s.MarkEmailVerified(ctx, uuid.New()); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("MarkEmailVerified of an unknown id: got %v, want pgx.ErrNoRows", err)
+	}
+
+	// user yang sudah dihapus tidak bisa diverifikasi, dan token-nya tidak ikut terpakai
+	deleted := mustCreate(t, s, userParams("deleted@example.com", "Deleted"))
+	mustCreateToken(t, s, deleted.ID, []byte("hash-deleted"), time.Hour)
+	if _, err := s.SoftDeleteUser(ctx, deleted.ID); err != nil {
+		t.Fatalf("SoftDeleteUser: %v", err)
+	}
+	if _, err := s.VerifyEmail(ctx, []byte("hash-deleted")); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("VerifyEmail of a deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.ConsumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: []byte("hash-deleted"), Purpose: db.TokenPurposeVerifyEmail}); err != nil {
+		t.Errorf("token was consumed by a failed VerifyEmail: %v", err)
+	}
+}
+
+func testEmailChanges(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+	change := mustCreateEmailChange(t, s, user, "budi@new.example.com", []byte("confirm-1"), time.Hour)
+	if change.UserID != user.ID || change.OldEmail != user.Email || change.ConfirmedAt.Valid || change.RevertTokenHash != nil {
+		t.Errorf("created email change = %+v", change)
+	}
+
+	// hanya satu permintaan yang belum dikonfirmasi per user
+	_, err := s.CreateEmailChange(ctx, emailChangeParams(user, "other@example.com", []byte("confirm-2"), time.Hour))
+	assertCode(t, "second pending change", err, db.UniqueViolation)
+	other := mustCreate(t, s, userParams("siti@example.com", "Siti"))
+	_, err = s.CreateEmailChange(ctx, emailChangeParams(other, "siti@new.example.com", []byte("confirm-1"), time.Hour))
+	assertCode(t, "duplicate confirm token hash", err, db.UniqueViolation)
+	_, err = s.CreateEmailChange(ctx, emailChangeParams(db.User{ID: uuid.New()}, "x@example.com", []byte("confirm-3"), time.Hour))
+	assertCode(t, "change of an unknown user", err, "23503")
+
+	rows, err := s.DeletePendingEmailChanges(ctx, user.ID)
+	if err != nil || rows != 1 {
+		t.Errorf("DeletePendingEmailChanges = %d, %v; want 1, nil", rows, err)
+	}
+	mustCreateEmailChange(t, s, user, "budi@new.example.com", []byte("confirm-4"), time.Hour)
+
+	confirm := db.ConfirmEmailChangeParams{
+		ConfirmTokenHash: []byte("confirm-1"),
+		RevertTokenHash:  []byte("revert-1"),
+		RevertExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	if _, err := s.ConfirmEmailChange(ctx, confirm); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ConfirmEmailChange of a deleted change: got %v, want pgx.ErrNoRows", err)
+	}
+	confirm.ConfirmTokenHash = []byte("confirm-4")
+	confirmed, err := s.ConfirmEmailChange(ctx, confirm)
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if !confirmed.ConfirmedAt.Valid || string(confirmed.RevertTokenHash) != "revert-1" {
+		t.Errorf("confirmed change = %+v", confirmed)
+	}
+	if _, err := s.ConfirmEmailChange(ctx, confirm); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second ConfirmEmailChange: got %v, want pgx.ErrNoRows", err)
+	}
+	// permintaan yang sudah dikonfirmasi tidak ikut terhapus dan tidak menghalangi permintaan baru
+	if rows, err := s.DeletePendingEmailChanges(ctx, user.ID); err != nil || rows != 0 {
+		t.Errorf("DeletePendingEmailChanges after confirm = %d, %v; want 0, nil", rows, err)
+	}
+	mustCreateEmailChange(t, s, user, "budi@third.example.com", []byte("confirm-5"), -time.Minute)
+	confirm.ConfirmTokenHash = []byte("confirm-5")
+	confirm.RevertTokenHash = []byte("revert-2")
+	if _, err := s.ConfirmEmailChange(ctx, confirm); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ConfirmEmailChange of an expired change: got %v, want pgx.ErrNoRows", err)
+	}
+
+	if _, err := s.RevertEmailChange(ctx, []byte("unknown")); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("RevertEmailChange of an unknown token: got %v, want pgx.ErrNoRows", err)
+	}
+	reverted, err := s.RevertEmailChange(ctx, []byte("revert-1"))
+	if err != nil {
+		t.Fatalf("RevertEmailChange: %v", err)
+	}
+	if reverted.ID != confirmed.ID || !reverted.RevertedAt.Valid {
+		t.Errorf("reverted change = %+v", reverted)
+	}
+	if _, err := s.RevertEmailChange(ctx, []byte("revert-1")); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second RevertEmailChange: got %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func testApplyAndUndoEmailChange(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+	mustCreate(t, s, userParams("taken@example.com", "Taken"))
+	mustCreateToken(t, s, user.ID, []byte("verify-1"), time.Hour)
+	mustCreateResetToken(t, s, user.ID, []byte("reset-1"), time.Hour)
+	confirm := db.ConfirmEmailChangeParams{
+		RevertTokenHash: []byte("revert-1"),
+		RevertExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	// alamat baru sudah dipakai: tidak ada yang berubah dan token konfirmasi tetap bisa dipakai
+	mustCreateEmailChange(t, s, user, "taken@example.com", []byte("confirm-taken"), time.Hour)
+	confirm.ConfirmTokenHash = []byte("confirm-taken")
+	_, err := s.ApplyEmailChange(ctx, confirm)
+	assertCode(t, "ApplyEmailChange to a taken address", err, db.UniqueViolation)
+	if _, err := s.ConfirmEmailChange(ctx, confirm); err != nil {
+		t.Errorf("confirm token was consumed by a failed ApplyEmailChange: %v", err)
+	}
+
+	mustCreateEmailChange(t, s, user, "budi@new.example.com", []byte("confirm-1"), time.Hour)
+	confirm.ConfirmTokenHash = []byte("confirm-1")
+	confirm.RevertTokenHash = []byte("revert-2")
+	applied, err := s.ApplyEmailChange(ctx, confirm)
+	if err != nil {
+		t.Fatalf("ApplyEmailChange: %v", err)
+	}
+	if applied.User.ID != user.ID || applied.User.Email != "budi@new.example.com" || !applied.User.EmailVerifiedAt.Valid {
+		t.Errorf("user after ApplyEmailChange = %+v", applied.User)
+	}
+	if applied.Change.OldEmail != "budi@example.com" {
+		t.Errorf("change = %+v", applied.Change)
+	}
+	// token verifikasi untuk alamat lama tidak berlaku lagi
+	if _, err := s.VerifyEmail(ctx, []byte("verify-1")); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("VerifyEmail after ApplyEmailChange: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.ResetPassword(ctx, []byte("reset-1"), "hash"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ResetPassword after ApplyEmailChange: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.GetUserByEmail(ctx, "budi@example.com"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByEmail of the old address: got %v, want pgx.ErrNoRows", err)
+	}
+
+	undone, err := s.UndoEmailChange(ctx, []byte("revert-2"))
+	if err != nil {
+		t.Fatalf("UndoEmailChange: %v", err)
+	}
+	if undone.User.Email != "budi@example.com" || !undone.Change.RevertedAt.Valid {
+		t.Errorf("UndoEmailChange = %+v", undone)
+	}
+	if _, err := s.UndoEmailChange(ctx, []byte("revert-2")); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second UndoEmailChange: got %v, want pgx.ErrNoRows", err)
+	}
+
+	// email user sudah berubah sejak permintaan dibuat: perubahan lama tidak berlaku
+	mustCreateEmailChange(t, s, user, "budi@other.example.com", []byte("confirm-2"), time.Hour)
+	if _, err := s.UpdateUserEmail(ctx, db.UpdateUserEmailParams{NewEmail: "budi@admin.example.com", ID: user.ID, OldEmail: "budi@example.com"}); err != nil {
+		t.Fatalf("UpdateUserEmail: %v", err)
+	}
+	confirm.ConfirmTokenHash = []byte("confirm-2")
+	confirm.RevertTokenHash = []byte("revert-3")
+	if _, err := s.ApplyEmailChange(ctx, confirm); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ApplyEmailChange after the email changed: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.UpdateUserEmail(ctx, db.UpdateUserEmailParams{NewEmail: "x@example.com", ID: user.ID, OldEmail: "budi@example.com"}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("UpdateUserEmail with a stale old email: got %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func testPhoneOTPs(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, phoneUserParams("budi@example.com", "+6281234567890"))
+	if _, err := s.GetPendingPhoneOTP(ctx, user.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetPendingPhoneOTP without a code: got %v, want pgx.ErrNoRows", err)
+	}
+	otp := mustCreatePhoneOTP(t, s, user, []byte("code-1"), time.Hour)
+	if otp.UserID != user.ID || otp.PhoneNumber != "+6281234567890" || otp.Attempts != 0 || otp.UsedAt.Valid {
+		t.Errorf("created otp = %+v", otp)
+	}
+
+	// hanya satu kode yang belum dipakai per user
+	_, err := s.CreatePhoneOTP(ctx, phoneOTPParams(user, []byte("code-2"), time.Hour))
+	assertCode(t, "second pending code", err, db.UniqueViolation)
+	_, err = s.CreatePhoneOTP(ctx, phoneOTPParams(db.User{ID: uuid.New(), PhoneNumber: user.PhoneNumber}, []byte("code-3"), time.Hour))
+	assertCode(t, "code of an unknown user", err, "23503")
+	if pending, err := s.GetPendingPhoneOTP(ctx, user.ID); err != nil || pending.ID != otp.ID {
+		t.Errorf("GetPendingPhoneOTP = %+v, %v; want %s", pending, err, otp.ID)
+	}
+
+	// setiap tebakan menambah attempts sampai batas
+	claim := db.ClaimPhoneOTPAttemptParams{UserID: user.ID, MaxAttempts: 2}
+	for want := int32(1); want <= 2; want++ {
+		claimed, err := s.ClaimPhoneOTPAttempt(ctx, claim)
+		if err != nil || claimed.ID != otp.ID || claimed.Attempts != want {
+			t.Errorf("ClaimPhoneOTPAttempt #%d = %+v, %v", want, claimed, err)
+		}
+	}
+	if _, err := s.ClaimPhoneOTPAttempt(ctx, claim); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ClaimPhoneOTPAttempt over the limit: got %v, want pgx.ErrNoRows", err)
+	}
+
+	rows, err := s.DeletePhoneOTPs(ctx, user.ID)
+	if err != nil || rows != 1 {
+		t.Errorf("DeletePhoneOTPs = %d, %v; want 1, nil", rows, err)
+	}
+	mustCreatePhoneOTP(t, s, user, []byte("code-4"), -time.Minute)
+	if _, err := s.ClaimPhoneOTPAttempt(ctx, claim); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ClaimPhoneOTPAttempt of an expired code: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.DeletePhoneOTPs(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	otp = mustCreatePhoneOTP(t, s, user, []byte("code-5"), time.Hour)
+	used, err := s.ConsumePhoneOTP(ctx, otp.ID)
+	if err != nil || !used.UsedAt.Valid {
+		t.Errorf("ConsumePhoneOTP = %+v, %v", used, err)
+	}
+	if _, err := s.ConsumePhoneOTP(ctx, otp.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second ConsumePhoneOTP: got %v, want pgx.ErrNoRows", err)
+	}
+	// kode yang sudah dipakai tidak ikut terhapus dan tidak menghalangi kode baru
+	if rows, err := s.DeletePhoneOTPs(ctx, user.ID); err != nil || rows != 0 {
+		t.Errorf("DeletePhoneOTPs after consume = %d, %v; want 0, nil", rows, err)
+	}
+	mustCreatePhoneOTP(t, s, user, []byte("code-6"), time.Hour)
+}
+
+func testPhoneOTPSends(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	budi := mustCreate(t, s, phoneUserParams("budi@example.com", "+6281234567890"))
+	siti := mustCreate(t, s, phoneUserParams("siti@example.com", "+6281234567890"))
+	now := time.Now().Truncate(time.Microsecond)
+	send := func(user db.User, phoneNumber string, at time.Time) {
+		t.Helper()
+		err := s.CreatePhoneOTPSend(ctx, db.CreatePhoneOTPSendParams{
+			UserID:      user.ID,
+			PhoneNumber: phoneNumber,
+			SentAt:      pgtype.Timestamptz{Time: at, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("CreatePhoneOTPSend: %v", err)
+		}
+	}
+	send(budi, "+6281234567890", now.Add(-2*time.Hour))
+	send(siti, "+6281234567890", now.Add(-time.Hour))
+	send(budi, "+6281234567890", now.Add(-3*time.Hour))
+	send(budi, "+6281111111111", now)
+
+	// dihitung per nomor, dari semua user, hanya yang setelah since, urut dari yang terlama
+	sent, err := s.ListPhoneOTPSends(ctx, db.ListPhoneOTPSendsParams{
+		PhoneNumber: "+6281234567890",
+		Since:       pgtype.Timestamptz{Time: now.Add(-150 * time.Minute), Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("ListPhoneOTPSends: %v", err)
+	}
+	want := []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Hour)}
+	if len(sent) != len(want) {
+		t.Fatalf("ListPhoneOTPSends = %v, want %v", sent, want)
+	}
+	for i := range want {
+		if !sent[i].Time.Equal(want[i]) {
+			t.Errorf("ListPhoneOTPSends[%d] = %v, want %v", i, sent[i].Time, want[i])
+		}
+	}
+
+	err = s.CreatePhoneOTPSend(ctx, db.CreatePhoneOTPSendParams{
+		UserID:      uuid.New(),
+		PhoneNumber: "+6281234567890",
+		SentAt:      pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	assertCode(t, "send of an unknown user", err, "23503")
+}
+
+func testVerifyPhone(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, phoneUserParams("budi@example.com", "+6281234567890"))
+	if user.PhoneVerifiedAt.Valid {
+		t.Fatalf("new user has phone_verified_at = %v", user.PhoneVerifiedAt.Time)
+	}
+	otp := mustCreatePhoneOTP(t, s, user, []byte("code-1"), time.Hour)
+	verified, err := s.VerifyPhone(ctx, otp.ID)
+	if err != nil {
+		t.Fatalf("VerifyPhone: %v", err)
+	}
+	if verified.ID != user.ID || !verified.PhoneVerifiedAt.Valid {
+		t.Errorf("verified user = %+v", verified)
+	}
+	if _, err := s.VerifyPhone(ctx, otp.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second VerifyPhone: got %v, want pgx.ErrNoRows", err)
+	}
+
+	// kode untuk nomor lain: user tidak berubah dan kode tetap bisa dipakai
+	other := mustCreate(t, s, phoneUserParams("siti@example.com", "+6281111111111"))
+	otp, err = s.CreatePhoneOTP(ctx, db.CreatePhoneOTPParams{
+		UserID:      other.ID,
+		PhoneNumber: "+6289999999999",
+		CodeHash:    []byte("code-2"),
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyPhone(ctx, otp.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("VerifyPhone for another number: got %v, want pgx.ErrNoRows", err)
+	}
+	if got, _ := s.GetUserByID(ctx, other.ID); got.PhoneVerifiedAt.Valid {
+		t.Error("phone was verified with a code sent to another number")
+	}
+	if _, err := s.ConsumePhoneOTP(ctx, otp.ID); err != nil {
+		t.Errorf("code was consumed by a failed VerifyPhone: %v", err)
+	}
+
+	// user yang sudah dihapus
+	if _, err := s.DeletePhoneOTPs(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	otp = mustCreatePhoneOTP(t, s, other, []byte("code-3"), time.Hour)
+	if _, err := s.SoftDeleteUser(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyPhone(ctx, otp.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("VerifyPhone of a deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func testResetPassword(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	user := mustCreate(t, s, userParams("budi@example.com", "Budi"))
+	if user.PasswordHash.Valid || user.PasswordChangedAt.Valid || user.SessionVersion != 0 {
+		t.Fatalf("new user = %+v", user)
+	}
+	mustCreateResetToken(t, s, user.ID, []byte("reset-1"), time.Hour)
+	mustCreateResetToken(t, s, user.ID, []byte("reset-2"), time.Hour)
+	mustCreateToken(t, s, user.ID, []byte("verify-1"), time.Hour)
+
+	// token verifikasi email tidak bisa dipakai untuk reset password
+	if _, err := s.ResetPassword(ctx, []byte("verify-1"), "hash"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ResetPassword with a verification token: got %v, want pgx.ErrNoRows", err)
+	}
+
+	reset, err := s.ResetPassword(ctx, []byte("reset-1"), "hash-1")
+	if err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if reset.ID != user.ID || reset.PasswordHash.String != "hash-1" || !reset.PasswordChangedAt.Valid || reset.SessionVersion != 1 {
+		t.Errorf("user after ResetPassword = %+v", reset)
+	}
+	if _, err := s.ResetPassword(ctx, []byte("reset-1"), "hash-2"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ResetPassword with a used token: got %v, want pgx.ErrNoRows", err)
+	}
+	// token reset lain ikut dihapus, token verifikasi tidak
+	if _, err := s.ResetPassword(ctx, []byte("reset-2"), "hash-2"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ResetPassword with another pending token: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.VerifyEmail(ctx, []byte("verify-1")); err != nil {
+		t.Errorf("VerifyEmail after ResetPassword: %v", err)
+	}
+
+	// setiap reset menaikkan session_version
+	mustCreateResetToken(t, s, user.ID, []byte("reset-3"), time.Hour)
+	again, err := s.ResetPassword(ctx, []byte("reset-3"), "hash-3")
+	if err != nil {
+		t.Fatalf("second ResetPassword: %v", err)
+	}
+	if again.SessionVersion != 2 || again.PasswordHash.String != "hash-3" {
+		t.Errorf("user after second ResetPassword = %+v", again)
+	}
+
+	expired := mustCreate(t, s, userParams("siti@example.com", "Siti"))
+	mustCreateResetToken(t, s, expired.ID, []byte("reset-expired"), -time.Minute)
+	if _, err := s.ResetPassword(ctx, []byte("reset-expired"), "hash"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ResetPassword with an expired token: got %v, want pgx.ErrNoRows", err)
+	}
+
+	// user yang sudah dihapus: password tidak berubah dan token tidak ikut terpakai
+	deleted := mustCreate(t, s, userParams("deleted@example.com", "Deleted"))
+	mustCreateResetToken(t, s, deleted.ID, []byte("reset-deleted"), time.Hour)
+	if _, err := s.SoftDeleteUser(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ResetPassword(ctx, []byte("reset-deleted"), "hash"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ResetPassword of a deleted user: got %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := s.ConsumeUserToken(ctx, db.ConsumeUserTokenParams{TokenHash: []byte("reset-deleted"), Purpose: db.TokenPurposeResetPassword}); err != nil {
+		t.Errorf("token was consumed by a failed ResetPassword: %v", err)
+	}
+}
+
+func testMetadata(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	result, err := s.CreateUserWithMetadata(ctx, db.CreateuserWithMetadataParams{
+		CreateUserParams: userParams("budi@example.com", "Budi"),
+		UserMetadata:     db.UserMetadata{Device: "android", Locale: "id-ID"},
+	})
+	if err != nil {
+		t.Fatalf("CreateUserWithMetadata: %v", err)
+	}
+
+	row, err := s.GetUserWithMetadata(ctx, result.ID)
+	if err != nil {
+		t.Fatalf("GetUserWithMetadata: %v", err)
+	}
+	if row.Email != result.Email || row.Role != result.Role || !row.UserCreatedAt.Time.Equal(result.CreatedAt.Time) {
+		t.Errorf("GetUserWithMetadata = %+v, want the values of %+v", row, result.User)
+	}
+	assertJSON(t, row.Metadata, `{"device":"android","locale":"id-ID"}`)
+	if !row.MetadataCreatedAt.Valid {
+		t.Error("metadata_created_at must be set")
+	}
+
+	meta, err := s.GetUserMetadata(ctx, result.ID)
+	if err != nil {
+		t.Fatalf("GetUserMetadata: %v", err)
+	}
+	assertJSON(t, meta.Metadata, `{"device":"android","locale":"id-ID"}`)
+
+	// LEFT JOIN: user tanpa metadata tetap ditemukan
+	bare := mustCreate(t, s, userParams("bare@example.com", "Bare"))
+	row, err = s.GetUserWithMetadata(ctx, bare.ID)
+	if err != nil {
+		t.Fatalf("GetUserWithMetadata of a user without metadata: %v", err)
+	}
+	if row.Metadata != nil || row.MetadataCreatedAt.Valid {
+		t.Errorf("metadata of a user without metadata = %s, want NULL", row.Metadata)
+	}
+	if _, err := s.GetUserMetadata(ctx, bare.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserMetadata of a user without metadata: got %v, want pgx.ErrNoRows", err)
+	}
+
+	err = s.CreateUserMetadata(ctx, db.CreateUserMetadataParams{UserID: uuid.New(), Metadata: []byte(`{}`)})
+	assertCode(t, "metadata for an unknown user", err, "23503")
+
+	err = s.CreateUserMetadata(ctx, db.CreateUserMetadataParams{UserID: bare.ID, Metadata: []byte(`[1,2]`)})
+	assertCode(t, "metadata that is not an object", err, "23514")
+}
+
+func testSearch(t *testing.T, h Harness) {
+	s := h.New(t)
+
+	mustCreate(t, s, userParams("budi.santoso@example.com", "Budi Santoso"))
+	mustCreate(t, s, userParams("siti@example.com", "Siti BUDIMAN"))
+	mustCreate(t, s, userParams("agus@corp.test", "Agus"))
+	noName := userParams("dewi_1@example.com", "")
+	noName.FullName = helper.StringToPGText("")
+	mustCreate(t, s, noName)
+
+	tests := []struct {
+		name   string
+		search *string
+		want   []string
+	}{
+		{"email substring", ptr("santoso@"), []string{"budi.santoso@example.com"}},
+		{"full name, case-insensitive", ptr("budi"), []string{"siti@example.com", "budi.santoso@example.com"}},
+		{"uppercase search", ptr("AGUS"), []string{"agus@corp.test"}},
+		{"domain", ptr("corp.test"), []string{"agus@corp.test"}},
+		{"empty search matches everyone", ptr(""), []string{"dewi_1@example.com", "agus@corp.test", "siti@example.com", "budi.santoso@example.com"}},
+		{"underscore is a LIKE wildcard", ptr("i_1"), []string{"dewi_1@example.com"}},
+		{"percent is a LIKE wildcard", ptr("b%n"), []string{"siti@example.com", "budi.santoso@example.com"}},
+		{"no match", ptr("zzz"), nil},
+		{"NULL search matches nobody", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg := db.ListUsersParams{Limit: 100}
+			if tt.search != nil {
+				arg.Search = helper.StringToPGTextValid(*tt.search)
+			}
+			users, err := s.ListUsers(context.Background(), arg)
+			if err != nil {
+				t.Fatalf("ListUsers: %v", err)
+			}
+			assertEmails(t, users, tt.want...)
+		})
+	}
+}
+
+func testOrderingAndPaging(t *testing.T, h Harness) {
+	ctx := context.Background()
+	s := h.New(t)
+
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+	for _, email := range emails {
+		mustCreate(t, s, userParams(email, email))
+	}
+
+	// created_at DESC: yang terakhir dibuat muncul pertama
+	assertEmails(t, listAll(t, s), "e@example.com", "d@example.com", "c@example.com", "b@example.com", "a@example.com")
+
+	pages := []struct {
+		offset, limit int32
+		want          []string
+	}{
+		{0, 2, []string{"e@example.com", "d@example.com"}},
+		{2, 2, []string{"c@example.com", "b@example.com"}},
+		{4, 2, []string{"a@example.com"}},
+		{5, 2, nil},
+		{0, 0, nil},
+	}
+	for _, p := range pages {
+		users, err := s.ListUsers(ctx, db.ListUsersParams{Search: helper.StringToPGTextValid(""), Offset: p.offset, Limit: p.limit})
+		if err != nil {
+			t.Fatalf("ListUsers(offset=%d, limit=%d): %v", p.offset, p.limit, err)
+		}
+		assertEmails(t, users, p.want...)
+	}
+
+	_, err := s.ListUsers(ctx, db.ListUsersParams{Search: helper.StringToPGTextValid(""), Limit: -1})
+	assertCode(t, "negative limit", err, "2201W")
+	_, err = s.ListUsers(ctx, db.ListUsersParams{Search: helper.StringToPGTextValid(""), Offset: -1, Limit: 1})
+	assertCode(t, "negative offset", err, "2201X")
+}
+
+func testRollback(t *testing.T, h Harness) {
+	if h.FailMetadata == nil {
+		t.Skip("harness cannot make metadata inserts fail")
+	}
+	ctx := context.Background()
+	s := h.New(t)
+	h.FailMetadata(t, s)
+
+	arg := db.CreateuserWithMetadataParams{
+		CreateUserParams: userParams("budi@example.com", "Budi"),
+		UserMetadata:     db.UserMetadata{Device: "web"},
+	}
+	if _, err := s.CreateUserWithMetadata(ctx, arg); err == nil {
+		t.Fatal("CreateUserWithMetadata succeeded although the metadata insert was made to fail")
+	}
+	if _, err := s.GetUserByEmail(ctx, arg.Email); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("user is visible after a failed CreateUserWithMetadata: %v", err)
+	}
+	// email tidak ikut terpakai oleh user yang di-rollback
+	mustCreate(t, s, arg.CreateUserParams)
+}
+
+func userParams(email, fullName string) db.CreateUserParams {
+	return db.CreateUserParams{
+		Email:    email,
+		FullName: helper.StringToPGTextValid(fullName),
+		Role:     constants.RoleUser,
+	}
+}
+
+func tokenParams(userID uuid.UUID, hash []byte, ttl time.Duration) db.CreateUserTokenParams {
+	return db.CreateUserTokenParams{
+		UserID:    userID,
+		Purpose:   db.TokenPurposeVerifyEmail,
+		TokenHash: hash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+	}
+}
+
+func mustCreateToken(t *testing.T, s db.Store, userID uuid.UUID, hash []byte, ttl time.Duration) db.UserToken {
+	t.Helper()
+	token, err := s.CreateUserToken(context.Background(), tokenParams(userID, hash, ttl))
+	if err != nil {
+		t.Fatalf("CreateUserToken: %v", err)
+	}
+	return token
+}
+
+func mustCreateResetToken(t *testing.T, s db.Store, userID uuid.UUID, hash []byte, ttl time.Duration) db.UserToken {
+	t.Helper()
+	arg := tokenParams(userID, hash, ttl)
+	arg.Purpose = db.TokenPurposeResetPassword
+	token, err := s.CreateUserToken(context.Background(), arg)
+	if err != nil {
+		t.Fatalf("CreateUserToken: %v", err)
+	}
+	return token
+}
+
+func emailChangeParams(user db.User, newEmail string, hash []byte, ttl time.Duration) db.CreateEmailChangeParams {
+	return db.CreateEmailChangeParams{
+		UserID:           user.ID,
+		OldEmail:         user.Email,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: hash,
+		ExpiresAt:        pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+	}
+}
+
+func mustCreateEmailChange(t *testing.T, s db.Store, user db.User, newEmail string, hash []byte, ttl time.Duration) db.EmailChange {
+	t.Helper()
+	change, err := s.CreateEmailChange(context.Background(), emailChangeParams(user, newEmail, hash, ttl))
+	if err != nil {
+		t.Fatalf("CreateEmailChange: %v", err)
+	}
+	return change
+}
+
+func phoneUserParams(email, phoneNumber string) db.CreateUserParams {
+	arg := userParams(email, "")
+	arg.PhoneNumber = helper.StringToPGText(phoneNumber)
+	return arg
+}
+
+func phoneOTPParams(user db.User, hash []byte, ttl time.Duration) db.CreatePhoneOTPParams {
+	return db.CreatePhoneOTPParams{
+		UserID:      user.ID,
+		PhoneNumber: user.PhoneNumber.String,
+		CodeHash:    hash,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+	}
+}
+
+func mustCreatePhoneOTP(t *testing.T, s db.Store, user db.User, hash []byte, ttl time.Duration) db.PhoneOtp {
+	t.Helper()
+	otp, err := s.CreatePhoneOTP(context.Background(), phoneOTPParams(user, hash, ttl))
+	if err != nil {
+		t.Fatalf("CreatePhoneOTP: %v", err)
+	}
+	return otp
+}
+
+func mustCreate(t *testing.T, s db.Store, arg db.CreateUserParams) db.User {
+	t.Helper()
+	user, err := s.CreateUser(context.Background(), arg)
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", arg.Email, err)
+	}
+	return user
+}
+
+func listAll(t *testing.T, s db.Store) []db.User {
+	t.Helper()
+	users, err := s.ListUsers(context.Background(), db.ListUsersParams{Search: helper.StringToPGTextValid(""), Limit: 100})
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	return users
+}
+
+func assertSameUser(t *testing.T, got, want db.User) {
+	t.Helper()
+	if got.ID != want.ID || got.Email != want.Email || got.Role != want.Role ||
+		!got.CreatedAt.Time.Equal(want.CreatedAt.Time) || !got.UpdatedAt.Time.Equal(want.UpdatedAt.Time) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func assertEmails(t *testing.T, users []db.User, want ...string) {
+	t.Helper()
+	var got []string
+	for _, u := range users {
+		got = append(got, u.Email)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("emails = %q, want %q", got, want)
+	}
+}
+
+func assertCode(t *testing.T, what string, err error, code string) {
+	t.Helper()
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		t.Errorf("%s: got %v, want SQLSTATE %s", what, err, code)
+		return
+	}
+	if pgErr.Code != code {
+		t.Errorf("%s: got SQLSTATE %s (%s), want %s", what, pgErr.Code, pgErr.Message, code)
+	}
+}
+
+// assertJSON membandingkan isi JSON, karena Postgres menormalkan format jsonb.
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("metadata %q is not valid JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("metadata = %s, want %s", got, want)
+	}
+}
+
+func ptr(s string) *string { return &s }
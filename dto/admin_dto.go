@@ -0,0 +1,13 @@
+package dto
+
+type SetLogLevelRequest struct {
+	Level   string `json:"level" validate:"required_without=Reset,omitempty,oneof=trace debug info warn warning error"`
+	Package string `json:"package" validate:"required_with=Reset"`
+	// Reset menghapus override level milik Package sehingga kembali mengikuti root
+	Reset bool `json:"reset"`
+}
+
+type LogLevelResponse struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages"`
+}
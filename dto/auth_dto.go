@@ -0,0 +1,11 @@
+package dto
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type ResetPasswordRequest struct {
+	Token string `json:"token" doc:"token from the password reset link" validate:"required,max=256"`
+	// Password: bcrypt hanya membaca 72 byte, karakter multibyte dicek lagi di service
+	Password string `json:"password" doc:"new password, 8 to 72 characters" validate:"required,min=8,max=72"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"mime/multipart"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,25 +10,76 @@ import (
 type CreateUserRequest struct {
 	Email       string `json:"email" validate:"required,email"`
 	FullName    string `json:"full_name"`
-	PhoneNumber string `json:"phone_number"`
-	AvatarURL   string `json:"avatar_url"`
+	PhoneNumber string `json:"phone_number" doc:"international (+6281234567890) or national number in PHONE_DEFAULT_REGION, stored as E.164" validate:"omitempty,max=32,phone"`
+	AvatarURL   string `json:"avatar_url" validate:"omitempty,max=2048,public_url"`
+	// Role hanya bisa di-set dari CLI admin, tidak dari body request
+	Role string `json:"-" schema:"-" validate:"omitempty,oneof=user superadmin tenant_admin tenant_staff"`
 }
 
 type ListUsersRequest struct {
-	Search string `validate:"omitempty"`
-	Offset int32  `validate:"omitempty,gte=0"`
-	Limit  int32  `validate:"omitempty,gte=1,lte=100"`
+	Search string `query:"search" doc:"case-insensitive match on email or full name" validate:"omitempty"`
+	Offset int32  `query:"offset" default:"0" validate:"omitempty,gte=0"`
+	Limit  int32  `query:"limit" default:"10" validate:"omitempty,gte=1,lte=100"`
 }
 
 type UserResponse struct {
-	ID          uuid.UUID  `json:"id"`
-	Email       string     `json:"email"`
-	FullName    *string    `json:"full_name,omitempty"`
-	PhoneNumber *string    `json:"phone_number,omitempty"`
-	Role        string     `json:"role"`
-	AvatarUrl   *string    `json:"avatar_url,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
-	Metadata    any        `json:"metadata,omitempty"`
+	ID              uuid.UUID  `json:"id"`
+	Email           string     `json:"email"`
+	FullName        *string    `json:"full_name,omitempty"`
+	PhoneNumber     *string    `json:"phone_number,omitempty"`
+	Role            string     `json:"role"`
+	AvatarUrl       *string    `json:"avatar_url,omitempty"`
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	PhoneVerified   bool       `json:"phone_verified"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	Metadata        any        `json:"metadata,omitempty"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" doc:"token from the verification link" validate:"required,max=256"`
+}
+
+type ChangeEmailRequest struct {
+	Email string `json:"email" doc:"new email address, used only after it is confirmed" validate:"required,email,max=255"`
+}
+
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" doc:"token from the confirmation or undo link" validate:"required,max=256"`
+}
+
+type EmailChangeResponse struct {
+	NewEmail string `json:"new_email"`
+	// ExpiresAt: batas waktu link konfirmasi yang dikirim ke alamat baru
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" doc:"6-digit code from the SMS" validate:"required,len=6,number"`
+}
+
+type PhoneOTPResponse struct {
+	// PhoneNumber: nomor tujuan SMS dalam format E.164
+	PhoneNumber string    `json:"phone_number"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	// ResendAt: waktu paling awal untuk meminta kode baru
+	ResendAt time.Time `json:"resend_at"`
+}
+
+type UploadAvatarRequest struct {
+	Avatar *multipart.FileHeader `json:"avatar" doc:"JPEG, PNG, GIF or WebP image" validate:"required"`
+}
+
+type AvatarThumbnail struct {
+	Size int    `json:"size"`
+	URL  string `json:"url"`
+}
+
+type AvatarResponse struct {
+	User UserResponse `json:"user"`
+	// Thumbnails berurutan dari yang terkecil, yang terbesar sama dengan user.avatar_url
+	Thumbnails []AvatarThumbnail `json:"thumbnails"`
 }
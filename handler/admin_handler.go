@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+
+	"user-service/constants"
+	"user-service/dto"
+	logger "user-service/pkg"
+	"user-service/pkg/helper"
+)
+
+type adminHandler struct {
+	validate *helper.Validator
+}
+
+func NewAdminHandler(validator *helper.Validator) *adminHandler {
+	return &adminHandler{validate: validator}
+}
+
+func (h *adminHandler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	helper.WriteSuccess(w, logLevelResponse())
+}
+
+func (h *adminHandler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req dto.SetLogLevelRequest
+	if err := helper.BindRequest(r, &req); err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+	if req.Package != "" && !slices.Contains(logger.Packages(), req.Package) {
+		helper.WriteError(w, r, http.StatusBadRequest, "unknown package "+req.Package)
+		return
+	}
+
+	if req.Reset {
+		logger.ResetLevel(req.Package)
+	} else {
+		level, err := logger.ParseLevel(req.Level)
+		if err != nil {
+			helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.SetLevel(req.Package, level)
+	}
+
+	logger.FromContext(r.Context()).Infof("log level changed: package=%q level=%s reset=%t", req.Package, req.Level, req.Reset)
+	helper.WriteSuccess(w, logLevelResponse())
+}
+
+func logLevelResponse() dto.LogLevelResponse {
+	level, packages := logger.Levels()
+	return dto.LogLevelResponse{Level: level, Packages: packages}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"user-service/dto"
+	logger "user-service/pkg"
+	"user-service/pkg/helper"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestAdminLogLevel menjalankan alur GET/PUT /admin/log-level: token wajib, level root dan level package
+// bisa diubah terpisah, override package bertahan saat root berubah, dan reset mengembalikannya ke root.
+func TestAdminLogLevel(t *testing.T) {
+	const token = "0123456789abcdef0123456789abcdef"
+	root, _ := logger.Levels()
+	t.Cleanup(func() {
+		logger.ResetLevel("db")
+		level, _ := logger.ParseLevel(root)
+		logger.SetLevel("", level)
+	})
+
+	r := chi.NewRouter()
+	NewRegisterAdminRoutes(r, helper.NewValidator(), token)
+	do := func(method, auth, body string) (*httptest.ResponseRecorder, dto.LogLevelResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/admin/log-level", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		var resp struct {
+			Data dto.LogLevelResponse `json:"data"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp.Data
+	}
+
+	for _, auth := range []string{"", "wrong-token"} {
+		if rec, _ := do(http.MethodGet, auth, ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("GET with token %q: status %d, want 401", auth, rec.Code)
+		}
+		if rec, _ := do(http.MethodPut, auth, `{"level":"debug"}`); rec.Code != http.StatusUnauthorized {
+			t.Errorf("PUT with token %q: status %d, want 401", auth, rec.Code)
+		}
+	}
+
+	steps := []struct {
+		name    string
+		body    string
+		status  int
+		root    string
+		dbLevel string
+	}{
+		{"root", `{"level":"warn"}`, http.StatusOK, "warning", "warning"},
+		{"package override", `{"package":"db","level":"debug"}`, http.StatusOK, "warning", "debug"},
+		{"root change keeps override", `{"level":"error"}`, http.StatusOK, "error", "debug"},
+		{"reset package", `{"package":"db","reset":true}`, http.StatusOK, "error", "error"},
+		{"unknown package", `{"package":"nope","level":"debug"}`, http.StatusBadRequest, "", ""},
+		{"unknown level", `{"level":"loud"}`, http.StatusBadRequest, "", ""},
+		{"reset without package", `{"reset":true}`, http.StatusBadRequest, "", ""},
+	}
+	for _, step := range steps {
+		rec, resp := do(http.MethodPut, token, step.body)
+		if rec.Code != step.status {
+			t.Errorf("%s: status %d, want %d: %s", step.name, rec.Code, step.status, rec.Body)
+			continue
+		}
+		if step.status != http.StatusOK {
+			continue
+		}
+		if resp.Level != step.root || resp.Packages["db"] != step.dbLevel {
+			t.Errorf("%s: response level=%s db=%s, want %s, %s", step.name, resp.Level, resp.Packages["db"], step.root, step.dbLevel)
+		}
+		// GET mengembalikan keadaan yang sama dengan response PUT
+		if rec, got := do(http.MethodGet, token, ""); rec.Code != http.StatusOK || got.Level != resp.Level || got.Packages["db"] != resp.Packages["db"] {
+			t.Errorf("%s: GET status %d, level=%s db=%s", step.name, rec.Code, got.Level, got.Packages["db"])
+		}
+	}
+}
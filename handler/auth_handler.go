@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"user-service/constants"
+	"user-service/dto"
+	"user-service/pkg/helper"
+	"user-service/service"
+)
+
+// forgotPasswordMessage sama untuk email yang terdaftar maupun tidak.
+const forgotPasswordMessage = "if the email address is registered, a password reset link has been sent to it"
+
+type authHandler struct {
+	authService service.AuthService
+	validate    *helper.Validator
+}
+
+func NewAuthHandler(as service.AuthService, validator *helper.Validator) *authHandler {
+	return &authHandler{authService: as, validate: validator}
+}
+
+func (h *authHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if err := helper.BindRequest(r, &req); err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+
+	// email dicari dan dikirim di background, jadi response tidak bergantung pada terdaftar tidaknya email
+	h.authService.ForgotPassword(r.Context(), req.Email)
+	helper.WriteJSON(w, http.StatusAccepted, helper.SuccessResponse{Status: constants.Success, Message: forgotPasswordMessage})
+}
+
+func (h *authHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := helper.BindRequest(r, &req); err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+
+	user, err := h.authService.ResetPassword(r.Context(), req.Token, req.Password)
+	switch {
+	case errors.Is(err, service.ErrInvalidToken), errors.Is(err, service.ErrPasswordTooLong):
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+	default:
+		helper.WriteSuccess(w, user)
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"user-service/pkg/helper"
+	"user-service/pkg/token"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestPasswordResetFlow menjalankan alur lengkap: permintaan reset untuk email terdaftar dan tidak terdaftar
+// dijawab sama, hanya link terakhir yang berlaku, lalu reset mengganti password dan mencabut access token lama.
+func TestPasswordResetFlow(t *testing.T) {
+	store := newTestStore()
+	box := &mailbox{}
+	registry := newTestRegistry(store)
+	registry.auth = newTestAuthService(store, box)
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator())
+	ctx := t.Context()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/users", `{"email":"dewi@example.com","full_name":"Dewi Lestari"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Data struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	id := created.Data.ID
+
+	unknown := post("/auth/password/forgot", `{"email":"nobody@example.com"}`)
+	if _, ok := box.last(); ok {
+		t.Fatal("an email was sent for an unknown address")
+	}
+	// huruf besar/kecil email tidak dibedakan
+	known := post("/auth/password/forgot", `{"email":"Dewi@Example.com"}`)
+	if known.Code != http.StatusAccepted || known.Code != unknown.Code || known.Body.String() != unknown.Body.String() {
+		t.Errorf("forgot: registered %d %s, unknown %d %s", known.Code, known.Body, unknown.Code, unknown.Body)
+	}
+	first := lastToken(t, box, "dewi@example.com")
+	if msg, _ := box.last(); !strings.Contains(msg.Text, "1 hour") {
+		t.Errorf("reset email:\n%s", msg.Text)
+	}
+	post("/auth/password/forgot", `{"email":"dewi@example.com"}`)
+	second := lastToken(t, box, "dewi@example.com")
+
+	// access token yang dibuat sebelum reset
+	before := token.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: id.String()}}
+	if err := registry.auth.CheckSession(ctx, before); err != nil {
+		t.Fatalf("CheckSession before reset: %v", err)
+	}
+
+	if rec := post("/auth/password/reset", `{"token":"`+first+`","password":"correct horse battery"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("reset with a replaced link: status %d, want 400", rec.Code)
+	}
+	rec = post("/auth/password/reset", `{"token":"`+second+`","password":"correct horse battery"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"email":"dewi@example.com"`) {
+		t.Fatalf("reset: status %d: %s", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "password") || strings.Contains(rec.Body.String(), "session") {
+		t.Errorf("reset response exposes password or session fields: %s", rec.Body)
+	}
+	if msg, _ := box.last(); msg.To != "dewi@example.com" || msg.Subject != "Your password was changed" {
+		t.Errorf("notice = %+v", msg)
+	}
+	if rec := post("/auth/password/reset", `{"token":"`+second+`","password":"another password"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("second reset with the same link: status %d, want 400", rec.Code)
+	}
+
+	user, err := store.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte("correct horse battery")); err != nil {
+		t.Errorf("stored password hash: %v", err)
+	}
+
+	// semua access token lama dicabut, token baru memakai versi sesi sekarang
+	if err := registry.auth.CheckSession(ctx, before); !errors.Is(err, service.ErrSessionRevoked) {
+		t.Errorf("CheckSession after reset: got %v, want ErrSessionRevoked", err)
+	}
+	version, err := registry.auth.SessionVersion(ctx, id)
+	if err != nil || version != 1 {
+		t.Fatalf("SessionVersion = %d, %v; want 1, nil", version, err)
+	}
+	after := token.Claims{SessionVersion: version, RegisteredClaims: jwt.RegisteredClaims{Subject: id.String()}}
+	if err := registry.auth.CheckSession(ctx, after); err != nil {
+		t.Errorf("CheckSession with the new version: %v", err)
+	}
+}
+
+// TestResetPasswordChecksTokenFirst memastikan password baru tidak di-hash untuk token yang tidak dikenal:
+// cost bcrypt di luar batas membuat hashing gagal, jadi yang diharapkan tetap 400, bukan 500.
+func TestResetPasswordChecksTokenFirst(t *testing.T) {
+	store := newTestStore()
+	registry := newTestRegistry(store)
+	registry.auth = service.NewAuthService(store, service.AuthOptions{
+		HashCost: bcrypt.MaxCost + 1,
+		Mailer:   &mailbox{},
+	})
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator())
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", strings.NewReader(`{"token":"unknown","password":"correct horse battery"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("reset with an unknown token: status %d, want 400: %s", rec.Code, rec.Body)
+	}
+}
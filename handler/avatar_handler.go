@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"user-service/constants"
+	"user-service/dto"
+	logger "user-service/pkg"
+	"user-service/pkg/avatar"
+	"user-service/pkg/fetch"
+	"user-service/pkg/helper"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// multipartOverhead adalah ruang untuk boundary dan header part di luar isi file.
+const multipartOverhead = 64 << 10
+
+type avatarHandler struct {
+	avatarService       service.AvatarService
+	verificationService service.VerificationService
+	validate            *helper.Validator
+}
+
+func NewAvatarHandler(as service.AvatarService, vs service.VerificationService, validator *helper.Validator) *avatarHandler {
+	return &avatarHandler{avatarService: as, verificationService: vs, validate: validator}
+}
+
+func (h *avatarHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, "UUID is not valid")
+		return
+	}
+	logger.AddFields(r.Context(), logrus.Fields{"user_id": id})
+
+	switch err := h.verificationService.RequireVerified(r.Context(), id); {
+	case errors.Is(err, pgx.ErrNoRows):
+		helper.WriteError(w, r, http.StatusNotFound, "user not found")
+		return
+	case errors.Is(err, service.ErrEmailNotVerified):
+		helper.WriteError(w, r, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var req dto.UploadAvatarRequest
+	err = helper.BindRequest(r, &req,
+		helper.WithContentTypes("multipart/form-data"),
+		helper.WithMaxBytes(h.avatarService.MaxBytes()+multipartOverhead),
+	)
+	if err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+	if req.Avatar.Size > h.avatarService.MaxBytes() {
+		helper.WriteError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("avatar must not be larger than %d bytes", h.avatarService.MaxBytes()))
+		return
+	}
+
+	file, err := req.Avatar.Open()
+	if err != nil {
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.avatarService.UploadAvatar(r.Context(), id, data)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		helper.WriteError(w, r, http.StatusNotFound, "user not found")
+	case errors.Is(err, avatar.ErrUnsupportedType):
+		helper.WriteError(w, r, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, avatar.ErrInvalidImage), errors.Is(err, avatar.ErrTooLarge):
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+	default:
+		helper.WriteSuccess(w, resp)
+	}
+}
+
+// importError menjelaskan kenapa avatar_url tidak bisa diunduh tanpa membocorkan detail jaringan internal.
+func importError(err error, maxBytes int64) string {
+	switch {
+	case errors.Is(err, fetch.ErrBlocked):
+		return "avatar_url points to an address that is not allowed"
+	case errors.Is(err, fetch.ErrTooLarge):
+		return fmt.Sprintf("avatar_url must not be larger than %d bytes", maxBytes)
+	case errors.Is(err, avatar.ErrUnsupportedType), errors.Is(err, avatar.ErrInvalidImage), errors.Is(err, avatar.ErrTooLarge):
+		return "avatar_url: " + err.Error()
+	default:
+		return "avatar_url could not be fetched"
+	}
+}
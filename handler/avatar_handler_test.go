@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"user-service/pkg/fetch"
+	"user-service/pkg/helper"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestCreateUserImportsAvatar memakai fetcher yang semua koneksinya diarahkan ke httptest server,
+// jadi URL di request tetap berupa host publik yang lolos validasi public_url.
+func TestCreateUserImportsAvatar(t *testing.T) {
+	jpg, err := os.ReadFile("testdata/avatar.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/avatar.jpg":
+			w.Write(jpg)
+		case "/avatar.txt":
+			w.Write([]byte("not an image"))
+		case "/internal":
+			http.Redirect(w, r, "http://10.0.0.1/avatar.jpg", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	fetcher := fetch.New(fetch.Options{
+		MaxBytes: 64 << 10,
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	})
+
+	tests := []struct {
+		name       string
+		avatarURL  string
+		wantStatus int
+		want       string
+	}{
+		{"imported", "http://images.example.com/avatar.jpg", http.StatusCreated, "https://cdn.example.com/avatars/"},
+		{"not an image", "http://images.example.com/avatar.txt", http.StatusBadRequest, "avatar_url: avatar must be a JPEG, PNG, GIF or WebP image"},
+		{"redirect to internal address", "http://images.example.com/internal", http.StatusBadRequest, "avatar_url points to an address that is not allowed"},
+		{"missing", "http://images.example.com/missing.jpg", http.StatusBadRequest, "avatar_url could not be fetched"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore()
+			blobs := memBlobs{}
+			registry := fakeRegistry{
+				users: service.NewUserService(store, service.UserOptions{}),
+				avatars: service.NewAvatarService(store, blobs, service.AvatarOptions{
+					Sizes:    []int{32, 64},
+					MaxBytes: 64 << 10,
+					Fetcher:  fetcher,
+				}),
+				verification: service.NewVerificationService(store, service.VerificationOptions{Mailer: &mailbox{}}),
+			}
+			r := chi.NewRouter()
+			NewRegisterRoutes(registry, r, helper.NewValidator())
+
+			body := `{"email":"dewi@example.com","avatar_url":"` + tt.avatarURL + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("body %s does not contain %q", rec.Body, tt.want)
+			}
+
+			_, err := store.GetUserByEmail(context.Background(), "dewi@example.com")
+			if tt.wantStatus != http.StatusCreated {
+				if err == nil {
+					t.Errorf("user must not be created when the avatar cannot be imported")
+				}
+				return
+			}
+
+			var resp struct {
+				Data struct {
+					AvatarURL string `json:"avatar_url"`
+				} `json:"data"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if strings.Contains(resp.Data.AvatarURL, "images.example.com") {
+				t.Errorf("avatar_url %q still points to the original host", resp.Data.AvatarURL)
+			}
+			if len(blobs) != 2 {
+				t.Errorf("stored %d thumbnails, want 2", len(blobs))
+			}
+		})
+	}
+}
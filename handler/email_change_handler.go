@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"user-service/constants"
+	"user-service/dto"
+	logger "user-service/pkg"
+	"user-service/pkg/helper"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type emailChangeHandler struct {
+	emailChangeService service.EmailChangeService
+	validate           *helper.Validator
+}
+
+func NewEmailChangeHandler(es service.EmailChangeService, validator *helper.Validator) *emailChangeHandler {
+	return &emailChangeHandler{emailChangeService: es, validate: validator}
+}
+
+func (h *emailChangeHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, "UUID is not valid")
+		return
+	}
+	logger.AddFields(r.Context(), logrus.Fields{"user_id": id})
+
+	var req dto.ChangeEmailRequest
+	if err := helper.BindRequest(r, &req); err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+
+	resp, err := h.emailChangeService.RequestEmailChange(r.Context(), id, req.Email)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		helper.WriteError(w, r, http.StatusNotFound, "user not found")
+	case errors.Is(err, service.ErrSameEmail):
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrEmailTaken):
+		helper.WriteError(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+	default:
+		// email belum berubah sampai alamat baru dikonfirmasi
+		helper.WriteJSON(w, http.StatusAccepted, helper.SuccessResponse{Status: constants.Success, Data: resp})
+	}
+}
+
+func (h *emailChangeHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	h.useToken(w, r, h.emailChangeService.ConfirmEmailChange)
+}
+
+func (h *emailChangeHandler) RevertEmailChange(w http.ResponseWriter, r *http.Request) {
+	h.useToken(w, r, h.emailChangeService.RevertEmailChange)
+}
+
+// useToken membaca dto.EmailChangeTokenRequest lalu menjalankan use, dipakai oleh confirm dan revert.
+func (h *emailChangeHandler) useToken(w http.ResponseWriter, r *http.Request, use func(ctx context.Context, token string) (dto.UserResponse, error)) {
+	var req dto.EmailChangeTokenRequest
+	if err := helper.BindRequest(r, &req); err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+
+	user, err := use(r.Context(), req.Token)
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrEmailTaken):
+		helper.WriteError(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+	default:
+		logger.AddFields(r.Context(), logrus.Fields{"user_id": user.ID})
+		helper.WriteSuccess(w, user)
+	}
+}
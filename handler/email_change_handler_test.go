@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"user-service/pkg/helper"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// TestEmailChangeFlow menjalankan alur lengkap: permintaan ganti email, konfirmasi lewat token yang dikirim
+// ke alamat baru, lalu pembatalan lewat link yang dikirim ke alamat lama.
+func TestEmailChangeFlow(t *testing.T) {
+	store := newTestStore()
+	box := &mailbox{}
+	registry := newTestRegistry(store)
+	registry.emailChanges = service.NewEmailChangeService(store, service.EmailChangeOptions{
+		ConfirmURL: "https://app.example.com/confirm-email-change",
+		RevertURL:  "https://app.example.com/revert-email-change",
+		TokenTTL:   time.Hour,
+		RevertTTL:  48 * time.Hour,
+		Mailer:     box,
+	})
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator())
+
+	var auth string
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	email := func(rec *httptest.ResponseRecorder) string {
+		var resp struct {
+			Data struct {
+				Email string `json:"email"`
+			} `json:"data"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp.Data.Email
+	}
+
+	rec := post("/users", `{"email":"dewi@example.com","full_name":"Dewi Lestari"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Data struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	path := "/users/" + created.Data.ID.String() + "/email"
+	auth = accessToken(t, created.Data.ID, 0)
+
+	// permintaan kedua membatalkan link dari permintaan pertama
+	if rec := post(path, `{"email":"dewi.lama@example.com"}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("first request: status %d: %s", rec.Code, rec.Body)
+	}
+	msg, _ := box.lastTo("dewi.lama@example.com")
+	stale := linkToken(t, msg)
+
+	before := time.Now()
+	rec = post(path, `{"email":"dewi.baru@example.com"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("request: status %d: %s", rec.Code, rec.Body)
+	}
+	var requested struct {
+		Data struct {
+			NewEmail  string    `json:"new_email"`
+			ExpiresAt time.Time `json:"expires_at"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &requested)
+	if requested.Data.NewEmail != "dewi.baru@example.com" ||
+		requested.Data.ExpiresAt.Before(before.Add(time.Hour)) || requested.Data.ExpiresAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("request response: %s", rec.Body)
+	}
+	if rec := post("/users/email-change/confirm", `{"token":"`+stale+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("confirm with the token of a replaced request: status %d, want 400", rec.Code)
+	}
+
+	// alamat lama diberi tahu tanpa link apa pun, dan email belum berubah
+	notice, ok := box.lastTo("dewi@example.com")
+	if !ok || !strings.Contains(notice.Text, "dewi.baru@example.com") || strings.Contains(notice.Text, "token=") {
+		t.Errorf("notice to the old address:\n%s", notice.Text)
+	}
+	if user, _ := store.GetUserByEmail(t.Context(), "dewi@example.com"); user.Email != "dewi@example.com" {
+		t.Fatal("email changed before confirmation")
+	}
+
+	msg, _ = box.lastTo("dewi.baru@example.com")
+	rec = post("/users/email-change/confirm", `{"token":"`+linkToken(t, msg)+`"}`)
+	if rec.Code != http.StatusOK || email(rec) != "dewi.baru@example.com" || !strings.Contains(rec.Body.String(), `"email_verified":true`) {
+		t.Fatalf("confirm: status %d: %s", rec.Code, rec.Body)
+	}
+
+	undo, _ := box.lastTo("dewi@example.com")
+	if !strings.Contains(undo.Text, "48 hours") {
+		t.Errorf("undo email does not mention the grace period:\n%s", undo.Text)
+	}
+	revert := linkToken(t, undo)
+	rec = post("/users/email-change/revert", `{"token":"`+revert+`"}`)
+	if rec.Code != http.StatusOK || email(rec) != "dewi@example.com" {
+		t.Fatalf("revert: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := post("/users/email-change/revert", `{"token":"`+revert+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("second revert: status %d, want 400", rec.Code)
+	}
+}
+
+// TestRequestEmailChangeRequiresOwner memastikan permintaan ganti email hanya diterima dengan access token
+// milik user itu sendiri yang belum dicabut, dan permintaan yang ditolak tidak mengirim email apa pun.
+func TestRequestEmailChangeRequiresOwner(t *testing.T) {
+	store := newTestStore()
+	box := &mailbox{}
+	registry := newTestRegistry(store)
+	registry.emailChanges = service.NewEmailChangeService(store, service.EmailChangeOptions{
+		ConfirmURL: "https://app.example.com/confirm-email-change",
+		RevertURL:  "https://app.example.com/revert-email-change",
+		Mailer:     box,
+	})
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator())
+
+	dewi := mustCreateUser(t, r, "dewi@example.com")
+	siti := mustCreateUser(t, r, "siti@example.com")
+
+	request := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users/"+dewi.String()+"/email", strings.NewReader(`{"email":"dewi.baru@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name string
+		auth string
+		want int
+	}{
+		{"without credentials", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic ZGV3aTpzZWNyZXQ=", http.StatusUnauthorized},
+		{"invalid token", "Bearer not-a-token", http.StatusUnauthorized},
+		{"token of another user", "Bearer " + accessToken(t, siti, 0), http.StatusForbidden},
+		// token yang dibuat sebelum password di-reset memakai versi sesi lama
+		{"revoked token", "Bearer " + accessToken(t, dewi, 1), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(tt.auth)
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+			if msg, ok := box.last(); ok {
+				t.Errorf("a rejected request sent an email: %+v", msg)
+			}
+		})
+	}
+
+	if rec := request("Bearer " + accessToken(t, dewi, 0)); rec.Code != http.StatusAccepted {
+		t.Fatalf("request with the owner's token: status %d: %s", rec.Code, rec.Body)
+	}
+	if _, ok := box.lastTo("dewi.baru@example.com"); !ok {
+		t.Error("no confirmation email was sent to the new address")
+	}
+}
+
+// mustCreateUser membuat user lewat POST /users dan mengembalikan ID-nya.
+func mustCreateUser(t *testing.T, r http.Handler, email string) uuid.UUID {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"email":"`+email+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create %s: status %d: %s", email, rec.Code, rec.Body)
+	}
+	var created struct {
+		Data struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	return created.Data.ID
+}
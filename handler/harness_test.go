@@ -0,0 +1,395 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"user-service/constants"
+	"user-service/db/memstore"
+	logger "user-service/pkg"
+	"user-service/pkg/mail"
+	"user-service/pkg/sms"
+	"user-service/pkg/token"
+	"user-service/service"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// httpCase adalah satu request dari file tabel di testdata/<nama>.json.
+type httpCase struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	// Body dikirim sebagai JSON dengan Content-Type application/json (kecuali di-set lewat Headers)
+	Body json.RawMessage `json:"body"`
+	// RawBody dikirim apa adanya, untuk body yang bukan JSON atau JSON yang rusak
+	RawBody *string `json:"raw_body"`
+	// Form dan Files dikirim sebagai multipart/form-data; Files berisi nama field → path file di testdata
+	Form  map[string]string `json:"form"`
+	Files map[string]string `json:"files"`
+	// Fail membuat query store dengan nama tersebut gagal dengan pesan error yang diberikan
+	Fail map[string]string `json:"fail"`
+	// Auth: ID user yang access token-nya (session version 0) dikirim di header Authorization
+	Auth string `json:"auth"`
+}
+
+// golden adalah bentuk response yang disimpan di testdata/golden/<tabel>/<nama case>.json.
+type golden struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type"`
+	Body        json.RawMessage `json:"body"`
+}
+
+// fakeRegistry adalah ServiceRegistry untuk test yang memakai service asli di atas memstore.
+type fakeRegistry struct {
+	users        service.UserService
+	avatars      service.AvatarService
+	verification service.VerificationService
+	emailChanges service.EmailChangeService
+	phone        service.PhoneVerificationService
+	auth         service.AuthService
+}
+
+func (f fakeRegistry) UserService() service.UserService {
+	return f.users
+}
+
+func (f fakeRegistry) AvatarService() service.AvatarService {
+	return f.avatars
+}
+
+func (f fakeRegistry) VerificationService() service.VerificationService {
+	return f.verification
+}
+
+func (f fakeRegistry) EmailChangeService() service.EmailChangeService {
+	return f.emailChanges
+}
+
+func (f fakeRegistry) PhoneVerificationService() service.PhoneVerificationService {
+	return f.phone
+}
+
+func (f fakeRegistry) AuthService() service.AuthService {
+	return f.auth
+}
+
+// newTestRegistry menyusun semua service di atas store, dengan blob store di memori.
+func newTestRegistry(store *memstore.Store) fakeRegistry {
+	return fakeRegistry{
+		users: service.NewUserService(store, service.UserOptions{PhoneRegion: "ID"}),
+		avatars: service.NewAvatarService(store, memBlobs{}, service.AvatarOptions{
+			Sizes:    []int{32, 64},
+			MaxBytes: 64 << 10,
+		}),
+		verification: service.NewVerificationService(store, service.VerificationOptions{
+			Policy: constants.VerificationOptional,
+			URL:    "https://app.example.com/verify-email",
+			Mailer: &mailbox{},
+		}),
+		emailChanges: service.NewEmailChangeService(store, service.EmailChangeOptions{
+			ConfirmURL: "https://app.example.com/confirm-email-change",
+			RevertURL:  "https://app.example.com/revert-email-change",
+			Mailer:     &mailbox{},
+		}),
+		phone: service.NewPhoneVerificationService(store, service.PhoneVerificationOptions{
+			Sender:         &outbox{},
+			Region:         "ID",
+			MaxAttempts:    3,
+			ResendInterval: time.Minute,
+		}),
+		auth: newTestAuthService(store, &mailbox{}),
+	}
+}
+
+// newTestAuthService menjalankan pekerjaan background ForgotPassword langsung di dalam request,
+// supaya email reset sudah ada di box begitu response diterima.
+func newTestAuthService(store *memstore.Store, box *mailbox) service.AuthService {
+	return service.NewAuthService(store, service.AuthOptions{
+		ResetURL:   "https://app.example.com/reset-password",
+		HashCost:   bcrypt.MinCost,
+		Mailer:     box,
+		Background: func(fn func()) { fn() },
+		Verifier:   testVerifier(),
+	})
+}
+
+// testVerifier dan accessToken memakai key pair khusus test di testdata/jwt.
+var testVerifier = sync.OnceValue(func() *token.Verifier {
+	verifier, err := token.NewVerifierFromFile(filepath.Join("testdata", "jwt", "public.pem"))
+	if err != nil {
+		panic(err)
+	}
+	return verifier
+})
+
+// accessToken membuat access token untuk userID yang diterima Verifier dari newTestAuthService.
+func accessToken(t *testing.T, userID uuid.UUID, sessionVersion int32) string {
+	t.Helper()
+	signer, err := token.NewSignerFromFile(filepath.Join("testdata", "jwt", "private.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, _, err := signer.Issue(userID, "", "user", sessionVersion, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// mailbox adalah mail.Mailer yang menyimpan email terkirim di memori.
+type mailbox struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (m *mailbox) Send(_ context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *mailbox) last() (mail.Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.messages) == 0 {
+		return mail.Message{}, false
+	}
+	return m.messages[len(m.messages)-1], true
+}
+
+// lastTo mengembalikan email terakhir yang dikirim ke to.
+func (m *mailbox) lastTo(to string) (mail.Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return mail.Message{}, false
+}
+
+// outbox adalah sms.Sender yang menyimpan SMS terkirim di memori.
+type outbox struct {
+	mu       sync.Mutex
+	messages []sms.Message
+}
+
+func (o *outbox) Send(_ context.Context, msg sms.Message) error {
+	if err := sms.Check(msg); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+func (o *outbox) last() (sms.Message, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.messages) == 0 {
+		return sms.Message{}, false
+	}
+	return o.messages[len(o.messages)-1], true
+}
+
+// memBlobs adalah blob.Store di memori, URL-nya memakai host contoh supaya mudah dikenali di golden file.
+type memBlobs map[string][]byte
+
+func (m memBlobs) Put(_ context.Context, key string, data []byte, _ string) error {
+	m[key] = data
+	return nil
+}
+
+func (m memBlobs) Delete(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m memBlobs) URL(key string) string {
+	return "https://cdn.example.com/" + key
+}
+
+// newTestStore membuat memstore dengan ID berurutan (…0001, …0002) dan jam yang maju satu menit
+// setiap dipakai mulai 2025-01-01T00:00:00Z, supaya response selalu sama antar run.
+func newTestStore() *memstore.Store {
+	store := memstore.New()
+
+	var n int
+	store.SetIDGenerator(func() uuid.UUID {
+		n++
+		return uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", n))
+	})
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.SetClock(func() time.Time {
+		t := now
+		now = now.Add(time.Minute)
+		return t
+	})
+	return store
+}
+
+// runGolden menjalankan setiap case dari testdata/<table>.json terhadap handler yang dibuat oleh
+// newHandler, lalu membandingkan response dengan golden file. Jalankan `go test ./handler -update`
+// untuk menulis ulang golden file setelah perubahan response yang memang disengaja.
+func runGolden(t *testing.T, table string, newHandler func(store *memstore.Store) http.Handler) {
+	data, err := os.ReadFile(filepath.Join("testdata", table+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases []httpCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		t.Fatalf("testdata/%s.json: %v", table, err)
+	}
+
+	seen := map[string]bool{}
+	for _, tc := range cases {
+		if seen[tc.Name] {
+			t.Fatalf("testdata/%s.json: duplicate case name %q", table, tc.Name)
+		}
+		seen[tc.Name] = true
+
+		t.Run(tc.Name, func(t *testing.T) {
+			store := newTestStore()
+			h := newHandler(store)
+			for query, msg := range tc.Fail {
+				store.Fail(query, errors.New(msg))
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tc.request(t))
+
+			got := golden{
+				Status:      rec.Code,
+				ContentType: rec.Header().Get("Content-Type"),
+				Body:        normalizeBody(rec.Body.Bytes()),
+			}
+			gotJSON, err := json.MarshalIndent(got, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotJSON = append(gotJSON, '\n')
+
+			path := filepath.Join("testdata", "golden", table, tc.Name+".json")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, gotJSON, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run `go test ./handler -update` to create it)", err)
+			}
+			if !bytes.Equal(gotJSON, want) {
+				t.Errorf("response differs from %s (run `go test ./handler -update` if the change is intended)\ngot:\n%s\nwant:\n%s", path, gotJSON, want)
+			}
+		})
+	}
+}
+
+func (tc httpCase) request(t *testing.T) *http.Request {
+	t.Helper()
+	var body io.Reader
+	contentType := ""
+	switch {
+	case tc.Form != nil || tc.Files != nil:
+		var buf bytes.Buffer
+		contentType = writeMultipart(t, &buf, tc.Form, tc.Files)
+		body = &buf
+	case tc.RawBody != nil:
+		body = strings.NewReader(*tc.RawBody)
+	case tc.Body != nil:
+		body = bytes.NewReader(tc.Body)
+		contentType = "application/json"
+	}
+
+	req := httptest.NewRequest(tc.Method, tc.Path, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if tc.Auth != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken(t, uuid.MustParse(tc.Auth), 0))
+	}
+	for k, v := range tc.Headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+// writeMultipart menulis field dan file secara berurutan menurut nama supaya body selalu sama.
+func writeMultipart(t *testing.T, w io.Writer, form, files map[string]string) string {
+	t.Helper()
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary("golden-boundary"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(form)) {
+		if err := mw.WriteField(name, form[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		data, err := os.ReadFile(filepath.Join("testdata", files[name]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		part, err := mw.CreateFormFile(name, filepath.Base(files[name]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return mw.FormDataContentType()
+}
+
+// normalizeBody menyimpan body JSON apa adanya (diindentasi bersama golden file),
+// body yang bukan JSON disimpan sebagai string.
+func normalizeBody(body []byte) json.RawMessage {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return json.RawMessage("null")
+	}
+	if json.Valid(body) {
+		return body
+	}
+	s, _ := json.Marshal(string(body))
+	return s
+}
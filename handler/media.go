@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewRegisterMediaRoutes menyajikan file dari dir (blob.FileStore) di bawah prefix, misalnya /media.
+// Daftar isi direktori tidak ditampilkan.
+func NewRegisterMediaRoutes(r chi.Router, prefix, dir string) {
+	files := http.StripPrefix(prefix, http.FileServer(http.Dir(dir)))
+	r.Get(prefix+"/*", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		// isi file hasil upload tidak boleh ditebak ulang oleh browser
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		files.ServeHTTP(w, r)
+	})
+}
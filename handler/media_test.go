@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMediaRoutes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "avatars", "u"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "avatars", "u", "64.jpg"), []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	NewRegisterMediaRoutes(r, "/media", dir)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/media/avatars/u/64.jpg", http.StatusOK},
+		{"/media/avatars/u/", http.StatusNotFound},
+		{"/media/avatars/u/128.jpg", http.StatusNotFound},
+		{"/media/../media_test.go", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+		}
+		if rec.Code == http.StatusOK && rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("GET %s: missing X-Content-Type-Options", tt.path)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"net/http"
+
+	"user-service/dto"
+	"user-service/pkg/helper"
+	"user-service/pkg/openapi"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+// Tipe di bawah hanya untuk dokumentasi: bentuk response dari helper.WriteSuccess dan helper.WriteError.
+type userEnvelope struct {
+	Status string           `json:"status" validate:"required,oneof=success"`
+	Data   dto.UserResponse `json:"data" validate:"required"`
+}
+
+type avatarEnvelope struct {
+	Status string             `json:"status" validate:"required,oneof=success"`
+	Data   dto.AvatarResponse `json:"data" validate:"required"`
+}
+
+type emailChangeEnvelope struct {
+	Status string                  `json:"status" validate:"required,oneof=success"`
+	Data   dto.EmailChangeResponse `json:"data" validate:"required"`
+}
+
+type phoneOTPEnvelope struct {
+	Status string               `json:"status" validate:"required,oneof=success"`
+	Data   dto.PhoneOTPResponse `json:"data" validate:"required"`
+}
+
+type messageEnvelope struct {
+	Status  string `json:"status" validate:"required,oneof=success"`
+	Message string `json:"message" validate:"required"`
+}
+
+type userListEnvelope struct {
+	Status string             `json:"status" validate:"required,oneof=success"`
+	Data   []dto.UserResponse `json:"data" validate:"required"`
+}
+
+type logLevelEnvelope struct {
+	Status string               `json:"status" validate:"required,oneof=success"`
+	Data   dto.LogLevelResponse `json:"data" validate:"required"`
+}
+
+type errorEnvelope struct {
+	Status string `json:"status" validate:"required,oneof=error"`
+	Errors any    `json:"errors" validate:"required" doc:"a message, or an object of field name to message for validation errors"`
+	// Details hanya ada untuk error validasi
+	Details []helper.FieldError `json:"details,omitempty"`
+}
+
+var (
+	idParam = openapi.Parameter{
+		Name:        "id",
+		Description: "user ID",
+		Schema:      &openapi.Schema{Type: "string", Format: "uuid"},
+	}
+	badRequest   = errorResp(http.StatusBadRequest, "Invalid request")
+	notFound     = errorResp(http.StatusNotFound, "User not found")
+	unauthorized = errorResp(http.StatusUnauthorized, "Missing or wrong admin token")
+	// notOwner dan noAccessToken untuk route yang memakai middleware.RequireUser
+	noAccessToken = errorResp(http.StatusUnauthorized, "Missing, invalid, expired or revoked access token")
+	notOwner      = errorResp(http.StatusForbidden, "The access token belongs to another user")
+	serverError   = errorResp(http.StatusInternalServerError, "Unexpected error")
+	// tooLarge dan unsupportedMedia berlaku untuk semua route yang memakai helper.BindRequest
+	tooLarge         = errorResp(http.StatusRequestEntityTooLarge, "Request body is larger than the route limit")
+	unsupportedMedia = errorResp(http.StatusUnsupportedMediaType, "Content-Type is not accepted by the route")
+)
+
+// errorResp mendokumentasikan kedua format error dari helper.WriteError.
+func errorResp(status int, description string) openapi.Resp {
+	return openapi.Resp{
+		Status:       status,
+		Description:  description + ". Send Accept: application/problem+json to get an RFC 9457 problem.",
+		Body:         errorEnvelope{},
+		Alternatives: map[string]any{helper.ProblemContentType: helper.Problem{}},
+	}
+}
+
+// NewOpenAPISpec mendeskripsikan semua route dari NewRegisterRoutes dan NewRegisterAdminRoutes.
+// Route baru wajib ditambahkan di sini; TestOpenAPIMatchesRoutes gagal jika keduanya berbeda.
+func NewOpenAPISpec() *openapi.Spec {
+	spec := openapi.New(openapi.Info{
+		Title:   "User Service API",
+		Version: "1.0.0",
+	})
+	spec.AddSecurityScheme("adminToken", openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "ADMIN_TOKEN",
+	})
+	spec.AddSecurityScheme("accessToken", openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "RS256 access token of the user in the path, see `user-service token issue`",
+	})
+
+	spec.Add(openapi.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users",
+		ID:      "listUsers",
+		Summary: "List users",
+		Tags:    []string{"users"},
+		Query:   dto.ListUsersRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: userListEnvelope{}},
+			badRequest,
+			serverError,
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:  http.MethodPost,
+		Path:    "/users",
+		ID:      "createUser",
+		Summary: "Create a user",
+		Tags:    []string{"users"},
+		Body:    dto.CreateUserRequest{},
+		BodyContentTypes: []string{
+			"application/json",
+			"application/x-www-form-urlencoded",
+			"multipart/form-data",
+		},
+		Responses: []openapi.Resp{
+			{Status: http.StatusCreated, Body: userEnvelope{}},
+			badRequest,
+			errorResp(http.StatusConflict, "The email address is used by another user, ignoring case"),
+			tooLarge,
+			unsupportedMedia,
+			serverError,
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/verify-email",
+		ID:          "verifyEmail",
+		Summary:     "Verify an email address",
+		Description: "Consumes the token from the link sent after sign-up. Each token works once and expires after EMAIL_VERIFICATION_TOKEN_TTL; a newer link invalidates older ones.",
+		Tags:        []string{"users"},
+		Body:        dto.VerifyEmailRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: userEnvelope{}},
+			errorResp(http.StatusBadRequest, "Invalid request, or the token is unknown, used or expired"),
+			tooLarge,
+			unsupportedMedia,
+			serverError,
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/email-change/confirm",
+		ID:          "confirmEmailChange",
+		Summary:     "Confirm a new email address",
+		Description: "Consumes the token sent to the new address and replaces the user's email. The old address then receives a link to undo the change, valid for EMAIL_CHANGE_REVERT_TTL.",
+		Tags:        []string{"users"},
+		Body:        dto.EmailChangeTokenRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: userEnvelope{}},
+			errorResp(http.StatusBadRequest, "Invalid request, or the token is unknown, used or expired"),
+			errorResp(http.StatusConflict, "The new email address was taken by another user after the request"),
+			tooLarge,
+			unsupportedMedia,
+			serverError,
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/email-change/revert",
+		ID:          "revertEmailChange",
+		Summary:     "Undo an email change",
+		Description: "Consumes the token sent to the old address after a change and switches the user back to it.",
+		Tags:        []string{"users"},
+		Body:        dto.EmailChangeTokenRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: userEnvelope{}},
+			errorResp(http.StatusBadRequest, "Invalid request, or the token is unknown, used or expired"),
+			errorResp(http.StatusConflict, "The old email address was taken by another user in the meantime"),
+			tooLarge,
+			unsupportedMedia,
+			serverError,
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:     http.MethodGet,
+		Path:       "/users/{id}",
+		ID:         "getUserByID",
+		Summary:    "Get a user by ID",
+		Tags:       []string{"users"},
+		PathParams: []openapi.Parameter{idParam},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: userEnvelope{}},
+			badRequest,
+			serverError,
+		},
+	})
+
+	spec.Add(openapi.Operation{
+		Method:           http.MethodPost,
+		Path:             "/users/{id}/avatar",
+		ID:               "uploadAvatar",
+		Summary:          "Upload a user avatar",
+		Description:      "The image type is detected from its content. EXIF data is removed and square thumbnails are generated for every configured size; the largest becomes avatar_url. Requires an access token of the same user.",
+		Tags:             []string{"users"},
+		Security:         []string{"accessToken"},
+		PathParams:       []openapi.Parameter{idParam},
+		Body:             dto.UploadAvatarRequest{},
+		BodyContentTypes: []string{"multipart/form-data"},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: avatarEnvelope{}},
+			badRequest,
+			noAccessToken,
+			errorResp(http.StatusForbidden, "The access token belongs to another user, or the email address is not verified and EMAIL_VERIFICATION_POLICY is required"),
+			notFound,
+			errorResp(http.StatusRequestEntityTooLarge, "Avatar is larger than AVATAR_MAX_BYTES"),
+			errorResp(http.StatusUnsupportedMediaType, "Body is not multipart/form-data or the file is not a JPEG, PNG, GIF or WebP image"),
+			serverError,
+		},
+	})
+
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/{id}/email",
+		ID:          "requestEmailChange",
+		Summary:     "Request an email change",
+		Description: "Sends a confirmation link to the new address and a notice to the current one. The email is only replaced once the link is used, within EMAIL_CHANGE_TOKEN_TTL; a newer request cancels the previous one. Requires an access token of the same user.",
+		Tags:        []string{"users"},
+		Security:    []string{"accessToken"},
+		PathParams:  []openapi.Parameter{idParam},
+		Body:        dto.ChangeEmailRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusAccepted, Body: emailChangeEnvelope{}},
+			errorResp(http.StatusBadRequest, "Invalid request, or the new address is the current one"),
+			noAccessToken,
+			notOwner,
+			errorResp(http.StatusConflict, "The new email address is used by another user"),
+			tooLarge,
+			unsupportedMedia,
+			serverError,
+		},
+	})
+
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/{id}/phone/otp",
+		ID:          "sendPhoneOTP",
+		Summary:     "Send a phone verification code",
+		Description: "Sends a 6-digit code by SMS to the user's phone number. The code expires after PHONE_OTP_TTL and a new one can be requested after PHONE_OTP_RESEND_INTERVAL; a newer code invalidates the previous one. At most PHONE_OTP_MAX_SENDS codes are sent to the same phone number within PHONE_OTP_SEND_WINDOW. Requires an access token of the same user.",
+		Tags:        []string{"users"},
+		Security:    []string{"accessToken"},
+		PathParams:  []openapi.Parameter{idParam},
+		Responses: []openapi.Resp{
+			{Status: http.StatusAccepted, Body: phoneOTPEnvelope{}},
+			errorResp(http.StatusBadRequest, "Invalid request, or the user has no valid phone number or it is already verified"),
+			noAccessToken,
+			notOwner,
+			notFound,
+			errorResp(http.StatusTooManyRequests, "A code was sent less than PHONE_OTP_RESEND_INTERVAL ago, or PHONE_OTP_MAX_SENDS codes were sent to the phone number within PHONE_OTP_SEND_WINDOW; see the Retry-After header"),
+			serverError,
+			errorResp(http.StatusServiceUnavailable, "Phone verification is disabled (SMS_PROVIDER=none)"),
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/{id}/phone/verify",
+		ID:          "verifyPhone",
+		Summary:     "Verify a phone number",
+		Description: "Checks the code from the SMS. Each call uses one of PHONE_OTP_MAX_ATTEMPTS attempts, after which a new code must be requested. Requires an access token of the same user.",
+		Tags:        []string{"users"},
+		Security:    []string{"accessToken"},
+		PathParams:  []openapi.Parameter{idParam},
+		Body:        dto.VerifyPhoneRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: userEnvelope{}},
+			errorResp(http.StatusBadRequest, "Invalid request, or the code is wrong, used, expired or out of attempts"),
+			noAccessToken,
+			notOwner,
+			tooLarge,
+			unsupportedMedia,
+			serverError,
+			errorResp(http.StatusServiceUnavailable, "Phone verification is disabled (SMS_PROVIDER=none)"),
+		},
+	})
+
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/password/forgot",
+		ID:          "forgotPassword",
+		Summary:     "Request a password reset link",
+		Description: "Always answers 202 with the same message, whether or not the email is registered; the lookup and the email run after the response. A registered user receives a link valid for PASSWORD_RESET_TOKEN_TTL, and a newer link invalidates older ones.",
+		Tags:        []string{"auth"},
+		Body:        dto.ForgotPasswordRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusAccepted, Body: messageEnvelope{}},
+			badRequest,
+			tooLarge,
+			unsupportedMedia,
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/password/reset",
+		ID:          "resetPassword",
+		Summary:     "Set a new password with a reset link",
+		Description: "Consumes the token from the reset link and replaces the password. Every access token issued to the user before the reset is revoked.",
+		Tags:        []string{"auth"},
+		Body:        dto.ResetPasswordRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: userEnvelope{}},
+			errorResp(http.StatusBadRequest, "Invalid request, the password is longer than 72 bytes, or the token is unknown, used or expired"),
+			tooLarge,
+			unsupportedMedia,
+			serverError,
+		},
+	})
+
+	spec.Add(openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     "/admin/log-level",
+		ID:       "getLogLevel",
+		Summary:  "Get the root and per-package log levels",
+		Tags:     []string{"admin"},
+		Security: []string{"adminToken"},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: logLevelEnvelope{}},
+			unauthorized,
+		},
+	})
+	spec.Add(openapi.Operation{
+		Method:      http.MethodPut,
+		Path:        "/admin/log-level",
+		ID:          "setLogLevel",
+		Summary:     "Change the log level of the root logger or one package",
+		Description: "An empty package changes the root level. reset removes the override of package so it follows the root level again.",
+		Tags:        []string{"admin"},
+		Security:    []string{"adminToken"},
+		Body:        dto.SetLogLevelRequest{},
+		Responses: []openapi.Resp{
+			{Status: http.StatusOK, Body: logLevelEnvelope{}},
+			badRequest,
+			unauthorized,
+			tooLarge,
+			unsupportedMedia,
+		},
+	})
+
+	return spec
+}
+
+// NewRegisterDocsRoutes menyajikan dokumen OpenAPI di /openapi.json dan Swagger UI di /docs/.
+func NewRegisterDocsRoutes(r chi.Router) {
+	r.Method(http.MethodGet, "/openapi.json", NewOpenAPISpec().Handler())
+	r.Handle("/docs/*", v5emb.New("User Service API", "/openapi.json", "/docs/"))
+	r.Handle("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently))
+}
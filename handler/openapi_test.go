@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"user-service/db/memstore"
+	"user-service/pkg/helper"
+	"user-service/pkg/openapi"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestOpenAPIMatchesRoutes gagal jika ada route yang tidak terdokumentasi atau sebaliknya.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	r := chi.NewRouter()
+	NewRegisterRoutes(newTestRegistry(memstore.New()), r, helper.NewValidator())
+	NewRegisterAdminRoutes(r, helper.NewValidator(), "token")
+
+	var routes []openapi.Route
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// r.Get("/") di dalam r.Route("/users") terdaftar sebagai "/users/"
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		routes = append(routes, openapi.Route{Method: method, Path: route})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	documented := NewOpenAPISpec().Routes()
+	if !reflect.DeepEqual(routes, documented) {
+		t.Errorf("routes and OpenAPI spec differ, update NewOpenAPISpec\nrouter:  %v\nopenapi: %v", routes, documented)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	r := chi.NewRouter()
+	NewRegisterDocsRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string                  `json:"required"`
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q, want 3.1.0", doc.OpenAPI)
+	}
+
+	// schema diturunkan dari tag validate di DTO
+	create := doc.Components.Schemas["CreateUserRequest"]
+	if !reflect.DeepEqual(create.Required, []string{"email"}) {
+		t.Errorf("CreateUserRequest.required = %v, want [email]", create.Required)
+	}
+	if got := create.Properties["email"]["format"]; got != "email" {
+		t.Errorf("CreateUserRequest.email format = %v, want email", got)
+	}
+	if _, ok := create.Properties["role"]; ok {
+		t.Error("CreateUserRequest.role is json:\"-\" and must not be documented")
+	}
+
+	var list struct {
+		Parameters []struct {
+			Name   string         `json:"name"`
+			Schema map[string]any `json:"schema"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(doc.Paths["/users"]["get"], &list); err != nil {
+		t.Fatal(err)
+	}
+	limit := map[string]any{"type": "integer", "format": "int32", "default": float64(10), "minimum": float64(1), "maximum": float64(100)}
+	for _, p := range list.Parameters {
+		if p.Name == "limit" && !reflect.DeepEqual(p.Schema, limit) {
+			t.Errorf("limit schema = %v, want %v", p.Schema, limit)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/openapi.json") {
+		t.Errorf("GET /docs/ = %d, want the Swagger UI page pointing at /openapi.json", rec.Code)
+	}
+}
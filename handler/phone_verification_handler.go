@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"user-service/constants"
+	"user-service/dto"
+	logger "user-service/pkg"
+	"user-service/pkg/helper"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type phoneVerificationHandler struct {
+	phoneVerificationService service.PhoneVerificationService
+	validate                 *helper.Validator
+}
+
+func NewPhoneVerificationHandler(ps service.PhoneVerificationService, validator *helper.Validator) *phoneVerificationHandler {
+	return &phoneVerificationHandler{phoneVerificationService: ps, validate: validator}
+}
+
+func (h *phoneVerificationHandler) SendOTP(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, "UUID is not valid")
+		return
+	}
+	logger.AddFields(r.Context(), logrus.Fields{"user_id": id})
+
+	resp, err := h.phoneVerificationService.SendOTP(r.Context(), id)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		helper.WriteError(w, r, http.StatusNotFound, "user not found")
+	case errors.Is(err, service.ErrNoPhone), errors.Is(err, service.ErrInvalidPhone), errors.Is(err, service.ErrPhoneAlreadyVerified):
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrOTPTooSoon), errors.Is(err, service.ErrOTPLimit):
+		seconds := math.Ceil(time.Until(resp.ResendAt).Seconds())
+		w.Header().Set("Retry-After", strconv.Itoa(max(int(seconds), 1)))
+		helper.WriteError(w, r, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrPhoneVerificationDisabled):
+		helper.WriteError(w, r, http.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+	default:
+		// nomor belum terverifikasi sampai kode dikirim ke POST /users/{id}/phone/verify
+		helper.WriteJSON(w, http.StatusAccepted, helper.SuccessResponse{Status: constants.Success, Data: resp})
+	}
+}
+
+func (h *phoneVerificationHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, "UUID is not valid")
+		return
+	}
+	logger.AddFields(r.Context(), logrus.Fields{"user_id": id})
+
+	var req dto.VerifyPhoneRequest
+	if err := helper.BindRequest(r, &req); err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+
+	user, err := h.phoneVerificationService.VerifyOTP(r.Context(), id, req.Code)
+	switch {
+	case errors.Is(err, service.ErrInvalidOTP):
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrPhoneVerificationDisabled):
+		helper.WriteError(w, r, http.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+	default:
+		helper.WriteSuccess(w, user)
+	}
+}
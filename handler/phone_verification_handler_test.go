@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"user-service/db/memstore"
+	"user-service/pkg/helper"
+	"user-service/pkg/sms"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var otpCode = regexp.MustCompile(`\b[0-9]{6}\b`)
+
+// asOwner memasang access token milik user {id} pada request ke /users/{id}/...; request lain dibiarkan.
+func asOwner(t *testing.T, req *http.Request) {
+	t.Helper()
+	parts := strings.Split(req.URL.Path, "/")
+	if len(parts) < 4 || parts[1] != "users" {
+		return
+	}
+	if id, err := uuid.Parse(parts[2]); err == nil {
+		req.Header.Set("Authorization", "Bearer "+accessToken(t, id, 0))
+	}
+}
+
+// smsCode mengambil kode OTP dari isi SMS.
+func smsCode(t *testing.T, msg sms.Message) string {
+	t.Helper()
+	code := otpCode.FindString(msg.Text)
+	if code == "" {
+		t.Fatalf("no code in sms:\n%s", msg.Text)
+	}
+	return code
+}
+
+// TestPhoneVerificationFlow menjalankan alur lengkap: nomor nasional dinormalkan saat user dibuat, kode dikirim
+// lewat SMS dengan jeda kirim ulang, tebakan dibatasi, lalu nomor terverifikasi dengan kode yang benar.
+func TestPhoneVerificationFlow(t *testing.T) {
+	store := newTestStore()
+	box := &outbox{}
+	r := newPhoneRouter(store, box)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		asOwner(t, req)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	create := func(email, phone string) string {
+		rec := post("/users", `{"email":"`+email+`","phone_number":"`+phone+`"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create %s: status %d: %s", email, rec.Code, rec.Body)
+		}
+		var created struct {
+			Data struct {
+				ID          string `json:"id"`
+				PhoneNumber string `json:"phone_number"`
+			} `json:"data"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &created)
+		if created.Data.PhoneNumber != "+6281234567899" {
+			t.Errorf("create %s: phone_number = %q, want +6281234567899", email, created.Data.PhoneNumber)
+		}
+		return "/users/" + created.Data.ID + "/phone"
+	}
+
+	path := create("dewi@example.com", "0812 3456 7899")
+
+	// tanpa access token, atau dengan token user lain, kode tidak dikirim dan tidak diperiksa
+	rec := post("/users", `{"email":"budi@example.com"}`)
+	var other struct {
+		Data struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &other)
+	for _, tc := range []struct {
+		name, token string
+		want        int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"another user's token", accessToken(t, other.Data.ID, 0), http.StatusForbidden},
+	} {
+		for _, action := range []string{"/otp", "/verify"} {
+			req := httptest.NewRequest(http.MethodPost, path+action, strings.NewReader(`{"code":"123456"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("%s %s: status %d, want %d: %s", tc.name, action, rec.Code, tc.want, rec.Body)
+			}
+		}
+	}
+	if _, ok := box.last(); ok {
+		t.Fatal("an sms was sent without the user's access token")
+	}
+
+	before := time.Now()
+	rec = post(path+"/otp", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("send: status %d: %s", rec.Code, rec.Body)
+	}
+	var sent struct {
+		Data struct {
+			PhoneNumber string    `json:"phone_number"`
+			ExpiresAt   time.Time `json:"expires_at"`
+			ResendAt    time.Time `json:"resend_at"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &sent)
+	if sent.Data.PhoneNumber != "+6281234567899" ||
+		sent.Data.ExpiresAt.Before(before.Add(5*time.Minute)) || sent.Data.ResendAt.Before(before.Add(time.Minute)) {
+		t.Errorf("send response: %s", rec.Body)
+	}
+	msg, _ := box.last()
+	if msg.To != "+6281234567899" || !strings.Contains(msg.Text, "5 minutes") {
+		t.Errorf("sms = %+v", msg)
+	}
+	code := smsCode(t, msg)
+
+	// kirim ulang sebelum jeda habis
+	rec = post(path+"/otp", "")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("resend: status %d, Retry-After %q: %s", rec.Code, rec.Header().Get("Retry-After"), rec.Body)
+	}
+	if again, _ := box.last(); again != msg {
+		t.Error("a new code was sent before the resend interval")
+	}
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if rec := post(path+"/verify", `{"code":"`+wrong+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("wrong code: status %d, want 400", rec.Code)
+	}
+	rec = post(path+"/verify", `{"code":"`+code+`"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"phone_verified":true`) {
+		t.Fatalf("verify: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := post(path+"/verify", `{"code":"`+code+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("second verify: status %d, want 400", rec.Code)
+	}
+	if rec := post(path+"/otp", ""); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "already verified") {
+		t.Errorf("send after verify: status %d: %s", rec.Code, rec.Body)
+	}
+
+	// setelah MaxAttempts tebakan, kode yang benar pun ditolak
+	path = create("eko@example.com", "+62-812-3456-7899")
+	if rec := post(path+"/otp", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("send: status %d: %s", rec.Code, rec.Body)
+	}
+	msg, _ = box.last()
+	code = smsCode(t, msg)
+	wrong = "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	for range 2 {
+		post(path+"/verify", `{"code":"`+wrong+`"}`)
+	}
+	if rec := post(path+"/verify", `{"code":"`+code+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("verify after too many attempts: status %d, want 400", rec.Code)
+	}
+}
+
+// TestPhoneOTPSendLimit memastikan satu nomor telepon hanya dikirimi MaxSends kode per SendWindow, dari user mana pun.
+// Setelah batas tercapai dan tebakan kode terakhir habis, verifikasi terkunci sampai jendela lewat.
+func TestPhoneOTPSendLimit(t *testing.T) {
+	store := newTestStore()
+	box := &outbox{}
+	registry := newTestRegistry(store)
+	registry.phone = service.NewPhoneVerificationService(store, service.PhoneVerificationOptions{
+		Sender:      box,
+		Region:      "ID",
+		TTL:         5 * time.Minute,
+		MaxAttempts: 2,
+		MaxSends:    3,
+		SendWindow:  24 * time.Hour,
+	})
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator(helper.WithPhoneRegion("ID")))
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		asOwner(t, req)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	create := func(email, phone string) string {
+		rec := post("/users", `{"email":"`+email+`","phone_number":"`+phone+`"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create %s: status %d: %s", email, rec.Code, rec.Body)
+		}
+		var created struct {
+			Data struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &created)
+		return "/users/" + created.Data.ID + "/phone"
+	}
+	wrongCode := func(code string) string {
+		if code == "000000" {
+			return "111111"
+		}
+		return "000000"
+	}
+
+	path := create("dewi@example.com", "0812 3456 7899")
+	var codes []string
+	for i := range 3 {
+		if rec := post(path+"/otp", ""); rec.Code != http.StatusAccepted {
+			t.Fatalf("send #%d: status %d: %s", i+1, rec.Code, rec.Body)
+		}
+		msg, _ := box.last()
+		codes = append(codes, smsCode(t, msg))
+	}
+	last, _ := box.last()
+
+	rec := post(path+"/otp", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("send over the limit: status %d: %s", rec.Code, rec.Body)
+	}
+	if retry, _ := strconv.Atoi(rec.Header().Get("Retry-After")); retry < int((23 * time.Hour).Seconds()) {
+		t.Errorf("send over the limit: Retry-After %q, want about 24h", rec.Header().Get("Retry-After"))
+	}
+	// nomor yang sama ditulis berbeda oleh user lain tetap kena batas
+	other := create("eko@example.com", "+62-812-3456-7899")
+	if rec := post(other+"/otp", ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("send to the same number from another user: status %d: %s", rec.Code, rec.Body)
+	}
+	if msg, _ := box.last(); msg != last {
+		t.Errorf("an sms was sent over the limit: %+v", msg)
+	}
+
+	// kode lama dibatalkan kode baru; tebakan kode terakhir habis, lalu kode baru tidak bisa diminta
+	code := codes[len(codes)-1]
+	if codes[0] != code {
+		if rec := post(path+"/verify", `{"code":"`+codes[0]+`"}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("superseded code: status %d, want 400", rec.Code)
+		}
+	}
+	for range 2 {
+		post(path+"/verify", `{"code":"`+wrongCode(code)+`"}`)
+	}
+	if rec := post(path+"/verify", `{"code":"`+code+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("verify after too many attempts: status %d, want 400", rec.Code)
+	}
+	if rec := post(path+"/otp", ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("send after lockout: status %d, want 429", rec.Code)
+	}
+
+	// nomor lain punya jatahnya sendiri
+	if rec := post(create("siti@example.com", "0811 1111 1111")+"/otp", ""); rec.Code != http.StatusAccepted {
+		t.Errorf("send to another number: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestPhoneVerificationDisabled(t *testing.T) {
+	store := newTestStore()
+	r := newPhoneRouter(store, nil)
+	ctx := t.Context()
+	for _, u := range userFixtures {
+		if _, err := store.CreateUserWithMetadata(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, path := range []string{"otp", "verify"} {
+		req := httptest.NewRequest(http.MethodPost, "/users/00000000-0000-0000-0000-000000000001/phone/"+path, strings.NewReader(`{"code":"123456"}`))
+		req.Header.Set("Content-Type", "application/json")
+		asOwner(t, req)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: status %d, want 503: %s", path, rec.Code, rec.Body)
+		}
+	}
+}
+
+// newPhoneRouter memasang route dengan PhoneVerificationService yang mengirim SMS ke box; box nil berarti
+// verifikasi nomor telepon dimatikan.
+func newPhoneRouter(store *memstore.Store, box *outbox) http.Handler {
+	opts := service.PhoneVerificationOptions{
+		Region:         "ID",
+		TTL:            5 * time.Minute,
+		MaxAttempts:    2,
+		ResendInterval: time.Minute,
+	}
+	if box != nil {
+		opts.Sender = box
+	}
+	registry := newTestRegistry(store)
+	registry.phone = service.NewPhoneVerificationService(store, opts)
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator(helper.WithPhoneRegion("ID")))
+	return r
+}
@@ -1,18 +1,48 @@
 package handler
 
 import (
+	"user-service/middleware"
+	"user-service/pkg/helper"
 	"user-service/service"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
-func NewRegisterRoutes(service service.ServiceRegistry, r chi.Router, validator *validator.Validate) {
-	userHandler := NewUserHandler(service.UserService(), validator)
+func NewRegisterRoutes(service service.ServiceRegistry, r chi.Router, validator *helper.Validator) {
+	userHandler := NewUserHandler(service.UserService(), service.AvatarService(), service.VerificationService(), validator)
+	avatarHandler := NewAvatarHandler(service.AvatarService(), service.VerificationService(), validator)
+	verificationHandler := NewVerificationHandler(service.VerificationService(), validator)
+	emailChangeHandler := NewEmailChangeHandler(service.EmailChangeService(), validator)
+	phoneVerificationHandler := NewPhoneVerificationHandler(service.PhoneVerificationService(), validator)
+	authHandler := NewAuthHandler(service.AuthService(), validator)
+	// route yang mengubah data milik user {id} hanya boleh dipanggil dengan access token user itu sendiri
+	requireUser := middleware.RequireUser(service.AuthService())
 
 	r.Route("/users", func(r chi.Router) {
 		r.Get("/", userHandler.ListUsers)
 		r.Post("/", userHandler.CreateUser)
+		r.Post("/verify-email", verificationHandler.VerifyEmail)
+		r.Post("/email-change/confirm", emailChangeHandler.ConfirmEmailChange)
+		r.Post("/email-change/revert", emailChangeHandler.RevertEmailChange)
 		r.Get("/{id}", userHandler.GetUserByID)
+		r.With(requireUser).Post("/{id}/avatar", avatarHandler.UploadAvatar)
+		r.With(requireUser).Post("/{id}/email", emailChangeHandler.RequestEmailChange)
+		r.With(requireUser).Post("/{id}/phone/otp", phoneVerificationHandler.SendOTP)
+		r.With(requireUser).Post("/{id}/phone/verify", phoneVerificationHandler.VerifyOTP)
+	})
+
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/password/forgot", authHandler.ForgotPassword)
+		r.Post("/password/reset", authHandler.ResetPassword)
+	})
+}
+
+func NewRegisterAdminRoutes(r chi.Router, validator *helper.Validator, token string) {
+	adminHandler := NewAdminHandler(validator)
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(middleware.AdminAuth(token))
+		r.Get("/log-level", adminHandler.GetLogLevel)
+		r.Put("/log-level", adminHandler.SetLogLevel)
 	})
 }
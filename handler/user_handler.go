@@ -1,44 +1,86 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"user-service/constants"
 	db "user-service/db/sqlc"
 	"user-service/dto"
+	logger "user-service/pkg"
+	"user-service/pkg/avatar"
 	"user-service/pkg/helper"
 	"user-service/service"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
 )
 
 type userHandler struct {
-	userService service.UserService
-	validate    *validator.Validate
+	userService         service.UserService
+	avatarService       service.AvatarService
+	verificationService service.VerificationService
+	validate            *helper.Validator
 }
 
-func NewUserHandler(us service.UserService, validator *validator.Validate) *userHandler {
-	return &userHandler{userService: us, validate: validator}
+func NewUserHandler(us service.UserService, as service.AvatarService, vs service.VerificationService, validator *helper.Validator) *userHandler {
+	return &userHandler{userService: us, avatarService: as, verificationService: vs, validate: validator}
 }
 
 func (h *userHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateUserRequest
 	if err := helper.BindRequest(r, &req); err != nil {
-		helper.WriteError(w, http.StatusBadRequest, err.Error())
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
 		return
 	}
+	// sisa normalisasi email dilakukan service, spasi dibuang lebih dulu supaya lolos validasi
+	req.Email = strings.TrimSpace(req.Email)
 	if err := h.validate.Struct(&req); err != nil {
-		err := helper.GenerateMessage(err, constants.FromRequestBody)
-		helper.WriteError(w, http.StatusBadRequest, err)
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
 		return
 	}
 
+	// gambar diunduh sebelum user dibuat, jadi URL yang gagal tidak meninggalkan user setengah jadi
+	var imported *avatar.Result
+	if req.AvatarURL != "" && h.avatarService.ImportsURLs() {
+		result, err := h.avatarService.FetchAvatar(r.Context(), req.AvatarURL)
+		if err != nil {
+			helper.WriteError(w, r, http.StatusBadRequest, importError(err, h.avatarService.MaxBytes()))
+			return
+		}
+		imported = result
+		req.AvatarURL = ""
+	}
+
 	user, err := h.userService.CreateUser(r.Context(), req)
+	if errors.Is(err, service.ErrEmailTaken) {
+		helper.WriteError(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrInvalidPhone) {
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
+	logger.AddFields(r.Context(), logrus.Fields{"user_id": user.ID})
+
+	if imported != nil {
+		resp, err := h.avatarService.SaveAvatar(r.Context(), user.ID, imported)
+		if err != nil {
+			// user sudah dibuat, kegagalan menyimpan avatar tidak membatalkannya
+			logger.FromContext(r.Context()).Errorf("failed to save imported avatar: %v", err)
+		} else {
+			user = resp.User
+		}
+	}
+	// email yang gagal dikirim tidak membatalkan user, kirim ulang lewat `user send-verification`
+	if err := h.verificationService.SendVerification(r.Context(), user.ID); err != nil {
+		logger.FromContext(r.Context()).Errorf("failed to send verification email: %v", err)
+	}
 
 	helper.WriteCreated(w, user)
 }
@@ -46,12 +88,13 @@ func (h *userHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 func (h *userHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	uuid, err := helper.ParseUUID(chi.URLParam(r, "id"))
 	if err != nil {
-		helper.WriteError(w, http.StatusBadRequest, "UUID is not valid")
+		helper.WriteError(w, r, http.StatusBadRequest, "UUID is not valid")
 		return
 	}
+	logger.AddFields(r.Context(), logrus.Fields{"user_id": uuid})
 	user, err := h.userService.GetUserByID(r.Context(), uuid)
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -65,8 +108,7 @@ func (h *userHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		Limit:  helper.ParseInt32(r.URL.Query().Get("limit"), 10),
 	}
 	if err := h.validate.Struct(&req); err != nil {
-		err := helper.GenerateMessage(err, constants.FromQueryParams)
-		helper.WriteError(w, http.StatusBadRequest, err)
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromQueryParams))
 		return
 	}
 
@@ -77,7 +119,7 @@ func (h *userHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	}
 	users, err := h.userService.ListUsers(r.Context(), arg)
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"testing"
+	"time"
+
+	"user-service/db/memstore"
+	db "user-service/db/sqlc"
+	"user-service/pkg/helper"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// userFixtures dibuat di setiap case testdata/user_handler.json, dengan ID …0001 sampai …0003.
+var userFixtures = []db.CreateuserWithMetadataParams{
+	{
+		CreateUserParams: db.CreateUserParams{
+			Email:       "budi@example.com",
+			FullName:    helper.StringToPGTextValid("Budi Santoso"),
+			PhoneNumber: helper.StringToPGText("+6281234567890"),
+			Role:        "user",
+			AvatarUrl:   helper.StringToPGText("https://example.com/budi.png"),
+		},
+		UserMetadata: db.UserMetadata{Device: "web"},
+	},
+	{
+		CreateUserParams: db.CreateUserParams{
+			Email:    "siti@example.com",
+			FullName: helper.StringToPGTextValid("Siti Rahayu"),
+			Role:     "tenant_admin",
+		},
+		UserMetadata: db.UserMetadata{Device: "android"},
+	},
+	{
+		CreateUserParams: db.CreateUserParams{
+			Email: "agus@example.com",
+			Role:  "user",
+		},
+		UserMetadata: db.UserMetadata{Device: "ios"},
+	},
+}
+
+// tokenFixtures adalah token verifikasi untuk user fixture: budi punya token yang masih berlaku,
+// siti token yang sudah kedaluwarsa.
+var tokenFixtures = []struct {
+	user  int
+	token string
+	ttl   time.Duration
+}{
+	{0, "budi-verification-token", time.Hour},
+	{1, "siti-expired-token", -time.Hour},
+}
+
+// emailChangeFixtures adalah permintaan ganti email yang menunggu konfirmasi: siti ke alamat yang masih bebas,
+// budi ke alamat agus yang sudah terpakai.
+var emailChangeFixtures = []struct {
+	user     int
+	newEmail string
+	token    string
+}{
+	{1, "siti.rahayu@example.com", "siti-email-change-token"},
+	{0, "agus@example.com", "budi-taken-email-token"},
+}
+
+func TestUserHandler(t *testing.T) {
+	runGolden(t, "user_handler", func(store *memstore.Store) http.Handler {
+		var users []db.User
+		for _, u := range userFixtures {
+			result, err := store.CreateUserWithMetadata(context.Background(), u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			users = append(users, result.User)
+		}
+		for _, f := range tokenFixtures {
+			hash := sha256.Sum256([]byte(f.token))
+			_, err := store.CreateUserToken(context.Background(), db.CreateUserTokenParams{
+				UserID:    users[f.user].ID,
+				Purpose:   db.TokenPurposeVerifyEmail,
+				TokenHash: hash[:],
+				// jam store dimulai 2025-01-01T00:00:00Z
+				ExpiresAt: pgtype.Timestamptz{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(f.ttl), Valid: true},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, f := range emailChangeFixtures {
+			hash := sha256.Sum256([]byte(f.token))
+			_, err := store.CreateEmailChange(context.Background(), db.CreateEmailChangeParams{
+				UserID:           users[f.user].ID,
+				OldEmail:         users[f.user].Email,
+				NewEmail:         f.newEmail,
+				ConfirmTokenHash: hash[:],
+				ExpiresAt:        pgtype.Timestamptz{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r := chi.NewRouter()
+		NewRegisterRoutes(newTestRegistry(store), r, helper.NewValidator(helper.WithPhoneRegion("ID")))
+		return r
+	})
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"user-service/constants"
+	"user-service/dto"
+	"user-service/pkg/helper"
+	"user-service/service"
+)
+
+type verificationHandler struct {
+	verificationService service.VerificationService
+	validate            *helper.Validator
+}
+
+func NewVerificationHandler(vs service.VerificationService, validator *helper.Validator) *verificationHandler {
+	return &verificationHandler{verificationService: vs, validate: validator}
+}
+
+func (h *verificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if err := helper.BindRequest(r, &req); err != nil {
+		helper.WriteError(w, r, helper.BindStatus(err), err.Error())
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		helper.WriteError(w, r, http.StatusBadRequest, h.validate.TranslateRequest(r, err, constants.FromRequestBody))
+		return
+	}
+
+	user, err := h.verificationService.VerifyEmail(r.Context(), req.Token)
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		helper.WriteError(w, r, http.StatusBadRequest, err.Error())
+	case err != nil:
+		helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+	default:
+		helper.WriteSuccess(w, user)
+	}
+}
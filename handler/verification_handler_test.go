@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"user-service/constants"
+	"user-service/dto"
+	"user-service/pkg/helper"
+	"user-service/pkg/mail"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// TestEmailVerificationFlow menjalankan alur lengkap dengan policy required: daftar, upload avatar
+// ditolak, verifikasi lewat token dari email, lalu upload avatar diterima.
+func TestEmailVerificationFlow(t *testing.T) {
+	store := newTestStore()
+	box := &mailbox{}
+	registry := newTestRegistry(store)
+	registry.verification = service.NewVerificationService(store, service.VerificationOptions{
+		Policy: constants.VerificationRequired,
+		URL:    "https://app.example.com/verify-email?lang=id",
+		Mailer: box,
+	})
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator())
+
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return do(req)
+	}
+	upload := func(id uuid.UUID) *httptest.ResponseRecorder {
+		tc := httpCase{Method: http.MethodPost, Path: "/users/" + id.String() + "/avatar", Files: map[string]string{"avatar": "avatar.jpg"}, Auth: id.String()}
+		return do(tc.request(t))
+	}
+
+	rec := post("/users", `{"email":"dewi@example.com","full_name":"Dewi Lestari"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Data struct {
+			ID uuid.UUID `json:"id"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+
+	token := lastToken(t, box, "dewi@example.com")
+	if msg, _ := box.last(); !strings.Contains(msg.Text, "Hi Dewi Lestari,") || !strings.Contains(msg.Text, "lang=id") {
+		t.Errorf("unexpected email:\n%s", msg.Text)
+	}
+
+	if rec := upload(created.Data.ID); rec.Code != http.StatusForbidden {
+		t.Fatalf("upload before verification: status %d, want 403: %s", rec.Code, rec.Body)
+	}
+
+	rec = post("/users/verify-email", `{"token":"`+token+`"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"email_verified":true`) {
+		t.Fatalf("verify: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := post("/users/verify-email", `{"token":"`+token+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("second verify with the same token: status %d, want 400", rec.Code)
+	}
+
+	if rec := upload(created.Data.ID); rec.Code != http.StatusOK {
+		t.Errorf("upload after verification: status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if err := registry.verification.SendVerification(context.Background(), created.Data.ID); !errors.Is(err, service.ErrAlreadyVerified) {
+		t.Errorf("SendVerification of a verified user: got %v, want ErrAlreadyVerified", err)
+	}
+}
+
+func TestResendInvalidatesOldToken(t *testing.T) {
+	store := newTestStore()
+	box := &mailbox{}
+	registry := newTestRegistry(store)
+	registry.verification = service.NewVerificationService(store, service.VerificationOptions{
+		URL:    "https://app.example.com/verify-email",
+		Mailer: box,
+	})
+	r := chi.NewRouter()
+	NewRegisterRoutes(registry, r, helper.NewValidator())
+
+	user, err := registry.users.CreateUser(context.Background(), dto.CreateUserRequest{Email: "dewi@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs := registry.verification
+	if err := vs.SendVerification(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	first := lastToken(t, box, "dewi@example.com")
+	if err := vs.SendVerification(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	second := lastToken(t, box, "dewi@example.com")
+
+	for _, tt := range []struct {
+		token string
+		want  int
+	}{
+		{first, http.StatusBadRequest},
+		{second, http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/users/verify-email", strings.NewReader(`{"token":"`+tt.token+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("token %s…: status %d, want %d", tt.token[:6], rec.Code, tt.want)
+		}
+	}
+}
+
+// lastToken mengambil token dari link di email terakhir dan memastikan email-nya untuk to.
+func lastToken(t *testing.T, box *mailbox, to string) string {
+	t.Helper()
+	msg, ok := box.last()
+	if !ok {
+		t.Fatal("no email was sent")
+	}
+	if msg.To != to {
+		t.Fatalf("email sent to %q, want %q", msg.To, to)
+	}
+	return linkToken(t, msg)
+}
+
+// linkToken mengambil token dari link app.example.com di isi msg.
+func linkToken(t *testing.T, msg mail.Message) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Text) {
+		if u, err := url.Parse(field); err == nil && u.Host == "app.example.com" {
+			if token := u.Query().Get("token"); token != "" {
+				return token
+			}
+		}
+	}
+	t.Fatalf("no link with a token in:\n%s", msg.Text)
+	return ""
+}
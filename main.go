@@ -1,19 +1,7 @@
 package main
 
-import (
-	"context"
-
-	"user-service/cmd"
-	"user-service/config"
-	db "user-service/db/sqlc"
-	logger "user-service/pkg"
-)
+import "user-service/cmd"
 
 func main() {
-	ctx := context.Background()
-	config := config.LoadConfig()
-	logger.Init(config)
-	pool := db.PostgresDB(ctx, config)
-
-	cmd.Run(cmd.ServerOptions{Config: config, DB: pool})
+	cmd.Execute()
 }
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuth(t *testing.T) {
+	const token = "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{"valid token", token, "Bearer " + token, http.StatusOK},
+		{"missing header", token, "", http.StatusUnauthorized},
+		{"wrong token", token, "Bearer " + token[:31] + "0", http.StatusUnauthorized},
+		{"token prefix", token, "Bearer " + token[:16], http.StatusUnauthorized},
+		{"other scheme", token, "Basic " + token, http.StatusUnauthorized},
+		{"lowercase scheme", token, "bearer " + token, http.StatusUnauthorized},
+		// ADMIN_TOKEN kosong menolak semua request, termasuk bearer kosong
+		{"no admin token", "", "Bearer ", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := AdminAuth(tt.token)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/admin/log-level", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			challenge := rec.Header().Get("WWW-Authenticate")
+			if tt.want == http.StatusUnauthorized && challenge != `Bearer realm="admin"` {
+				t.Errorf("WWW-Authenticate = %q", challenge)
+			}
+			if tt.want == http.StatusOK && challenge != "" {
+				t.Errorf("WWW-Authenticate on success = %q", challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"user-service/pkg/metrics"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Metrics mencatat durasi request HTTP berdasarkan route pattern chi (bukan path mentah)
+// supaya label tidak meledak untuk path seperti /users/{id}.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(r.Method, route, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-service/pkg/metrics"
+
+	"github.com/go-chi/chi/v5"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// TestMetricsLabels memastikan durasi request dicatat dengan route pattern chi, bukan path mentah, dan status
+// response yang sebenarnya.
+func TestMetricsLabels(t *testing.T) {
+	metrics.HTTPRequestDuration.Reset()
+	t.Cleanup(metrics.HTTPRequestDuration.Reset)
+
+	r := chi.NewRouter()
+	r.Use(Metrics)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// tanpa WriteHeader status dianggap 200
+		w.Write([]byte("ok"))
+	})
+	r.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/users/1"},
+		{http.MethodGet, "/users/2"},
+		{http.MethodGet, "/users/missing"},
+		{http.MethodPost, "/users"},
+		{http.MethodGet, "/nope"},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	want := map[[3]string]uint64{
+		{http.MethodGet, "/users/{id}", "200"}: 2,
+		{http.MethodGet, "/users/{id}", "404"}: 1,
+		{http.MethodPost, "/users", "201"}:     1,
+		{http.MethodGet, "unmatched", "404"}:   1,
+	}
+	got := requestCounts(t)
+	for labels, n := range want {
+		if got[labels] != n {
+			t.Errorf("%v: %d requests, want %d", labels, got[labels], n)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got series %v, want %v", got, want)
+	}
+	if v := metricValue(t, "user_service_http_requests_in_flight"); v != 0 {
+		t.Errorf("requests in flight = %v after all requests finished", v)
+	}
+}
+
+// requestCounts mengembalikan jumlah request per label method, route dan status dari metrics.Registry.
+func requestCounts(t *testing.T) map[[3]string]uint64 {
+	t.Helper()
+	counts := map[[3]string]uint64{}
+	for _, m := range gather(t, "user_service_http_request_duration_seconds") {
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		counts[[3]string{labels["method"], labels["route"], labels["status"]}] = m.GetHistogram().GetSampleCount()
+	}
+	return counts
+}
+
+// metricValue mengembalikan nilai gauge name yang hanya punya satu series.
+func metricValue(t *testing.T, name string) float64 {
+	t.Helper()
+	ms := gather(t, name)
+	if len(ms) != 1 {
+		t.Fatalf("%s has %d series, want 1", name, len(ms))
+	}
+	return ms[0].GetGauge().GetValue()
+}
+
+// gather mengembalikan semua series metric name dari metrics.Registry.
+func gather(t *testing.T, name string) []*dto.Metric {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() == name {
+			return f.GetMetric()
+		}
+	}
+	return nil
+}
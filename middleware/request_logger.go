@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	logger "user-service/pkg"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+var accessLog = logger.Named("http")
+
+// RequestLogger memasang logger per request di context dan menulis satu access log terstruktur
+// setelah request selesai. Harus dipasang setelah chi middleware.RequestID dan Tracing.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		reqID := chimiddleware.GetReqID(r.Context())
+		if reqID != "" {
+			w.Header().Set(chimiddleware.RequestIDHeader, reqID)
+		}
+
+		ctx := logger.NewContext(r.Context(), logrus.Fields{
+			"request_id": reqID,
+			"method":     r.Method,
+			"path":       r.URL.Path,
+		})
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			logger.AddFields(ctx, logrus.Fields{"route": rctx.RoutePattern()})
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		entry := accessLog.FromContext(ctx).WithFields(logrus.Fields{
+			"status":      status,
+			"bytes":       ww.BytesWritten(),
+			"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"remote_ip":   r.RemoteAddr,
+			"user_agent":  r.UserAgent(),
+		})
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error("request completed")
+		case status >= http.StatusBadRequest:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	logger "user-service/pkg"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// TestRequestLoggerFields memastikan field yang ditambahkan handler lewat logger.AddFields dan request ID
+// muncul di satu-satunya access log milik request.
+func TestRequestLoggerFields(t *testing.T) {
+	logger.SetOutput(io.Discard)
+	t.Cleanup(func() { logger.SetOutput(os.Stdout) })
+	hook := test.NewLocal(accessLog.Logger)
+
+	r := chi.NewRouter()
+	r.Use(chimiddleware.RequestID)
+	r.Use(RequestLogger)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logger.AddFields(r.Context(), logrus.Fields{"user_id": chi.URLParam(r, "id")})
+		if r.URL.Query().Has("fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		level  logrus.Level
+	}{
+		{"ok", "/users/42", http.StatusOK, logrus.InfoLevel},
+		{"server error", "/users/42?fail", http.StatusInternalServerError, logrus.ErrorLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(chimiddleware.RequestIDHeader, "req-"+tt.name)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get(chimiddleware.RequestIDHeader); got != "req-"+tt.name {
+				t.Errorf("response %s = %q", chimiddleware.RequestIDHeader, got)
+			}
+			entries := hook.AllEntries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d access log entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.level || entry.Message != "request completed" {
+				t.Errorf("entry %s %q, want %s", entry.Level, entry.Message, tt.level)
+			}
+			want := logrus.Fields{
+				"request_id": "req-" + tt.name,
+				"user_id":    "42",
+				"route":      "/users/{id}",
+				"method":     http.MethodGet,
+				"path":       "/users/42",
+				"status":     tt.status,
+				"package":    "http",
+			}
+			for k, v := range want {
+				if entry.Data[k] != v {
+					t.Errorf("field %s = %v, want %v", k, entry.Data[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing membuka server span untuk setiap request (termasuk ekstraksi header traceparent),
+// lalu mengganti nama span dengan route pattern chi setelah routing selesai.
+func Tracing(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil || rctx.RoutePattern() == "" {
+			return
+		}
+		pattern := rctx.RoutePattern()
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + pattern)
+		span.SetAttributes(attribute.String("http.route", pattern))
+	})
+
+	return otelhttp.NewHandler(named, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/metrics"
+		}),
+	)
+}
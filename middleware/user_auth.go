@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"user-service/pkg/helper"
+	"user-service/pkg/token"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Authenticator memeriksa access token, biasanya service.AuthService.
+// Error token.ErrInvalid atau service.ErrSessionRevoked berarti token ditolak.
+type Authenticator interface {
+	Authenticate(ctx context.Context, accessToken string) (token.Claims, error)
+}
+
+// RequireUser mewajibkan header "Authorization: Bearer <access token>" milik user {id} di path.
+// Token yang tidak ada, tidak valid atau sudah dicabut dijawab 401, token milik user lain 403.
+func RequireUser(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || got == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="user-service"`)
+				helper.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			claims, err := auth.Authenticate(r.Context(), got)
+			switch {
+			case errors.Is(err, token.ErrInvalid), errors.Is(err, service.ErrSessionRevoked):
+				w.Header().Set("WWW-Authenticate", `Bearer realm="user-service", error="invalid_token"`)
+				helper.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+				return
+			case err != nil:
+				helper.WriteError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			if id, err := uuid.Parse(chi.URLParam(r, "id")); err != nil || id.String() != claims.Subject {
+				helper.WriteError(w, r, http.StatusForbidden, "access token belongs to another user")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	// decoder untuk image.Decode
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrUnsupportedType = errors.New("avatar must be a JPEG, PNG, GIF or WebP image")
+	ErrInvalidImage    = errors.New("avatar image is corrupt or truncated")
+	ErrTooLarge        = errors.New("avatar image dimensions are too large")
+)
+
+// MaxDimension dan MaxPixels membatasi ukuran gambar sebelum di-decode, supaya file kecil dengan
+// dimensi raksasa (decompression bomb) tidak menghabiskan memori. MaxPixels cukup untuk foto kamera
+// ponsel 12 MP dan membatasi hasil decode RGBA sekitar 50 MB per request.
+const (
+	MaxDimension = 4096
+	MaxPixels    = 4096 * 3072
+)
+
+// DefaultSizes adalah sisi thumbnail dalam pixel jika config tidak mengaturnya.
+var DefaultSizes = []int{64, 128, 256}
+
+// sniffed memetakan hasil http.DetectContentType (magic bytes) ke nama format image.Decode.
+var sniffed = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+type Thumbnail struct {
+	Size int
+	Data []byte
+}
+
+// Result berisi thumbnail persegi untuk setiap ukuran, semuanya dengan format yang sama.
+// EXIF dan metadata lain tidak ikut karena gambar di-encode ulang.
+type Result struct {
+	ContentType string
+	// Ext adalah ekstensi file tanpa titik: "jpg" atau "png"
+	Ext        string
+	Thumbnails []Thumbnail
+	// Version berubah setiap isi gambar berubah, dipakai untuk cache busting di URL
+	Version string
+}
+
+// Process memvalidasi gambar lewat magic bytes (bukan Content-Type dari client), memotong bagian
+// tengah menjadi persegi, menerapkan orientasi EXIF, lalu membuat thumbnail untuk setiap ukuran.
+// Gambar tanpa transparansi di-encode sebagai JPEG, selain itu PNG.
+func Process(data []byte, sizes []int) (*Result, error) {
+	format, ok := sniffed[http.DetectContentType(data)]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+	cfg, decodedFormat, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || decodedFormat != format {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width > MaxDimension || cfg.Height > MaxDimension || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+
+	opaque := isOpaque(src)
+	result := &Result{ContentType: "image/png", Ext: "png"}
+	if opaque {
+		result.ContentType, result.Ext = "image/jpeg", "jpg"
+	}
+	sum := sha256.Sum256(data)
+	result.Version = hex.EncodeToString(sum[:8])
+
+	for _, size := range sizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+		dst = orient(dst, orientation)
+
+		var buf bytes.Buffer
+		if opaque {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buf, dst)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Thumbnails = append(result.Thumbnails, Thumbnail{Size: size, Data: buf.Bytes()})
+	}
+	return result, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
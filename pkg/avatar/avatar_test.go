@@ -0,0 +1,178 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{255, 0, 0, 255}
+	blue = color.RGBA{0, 0, 255, 255}
+)
+
+// leftRed membuat gambar w×h dengan setengah kiri merah dan setengah kanan biru.
+func leftRed(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation menyisipkan segment APP1 Exif berisi satu tag Orientation setelah SOI.
+func withOrientation(jpg []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)           // jumlah entry
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)      // tag
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)           // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)           // count
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation) // value
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)                   // padding value + next IFD
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	seg = append(seg, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func TestProcessJPEG(t *testing.T) {
+	// 200×100: potongan tengah 100×100 tetap setengah merah (kiri), setengah biru (kanan)
+	data := withOrientation(encodeJPEG(t, leftRed(200, 100)), 1)
+
+	res, err := Process(data, []int{32, 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ContentType != "image/jpeg" || res.Ext != "jpg" || res.Version == "" {
+		t.Errorf("result = %s %s %q", res.ContentType, res.Ext, res.Version)
+	}
+	if len(res.Thumbnails) != 2 {
+		t.Fatalf("got %d thumbnails, want 2", len(res.Thumbnails))
+	}
+	for _, th := range res.Thumbnails {
+		img, err := jpeg.Decode(bytes.NewReader(th.Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != th.Size || b.Dy() != th.Size {
+			t.Errorf("thumbnail %d is %v", th.Size, b)
+		}
+		if bytes.Contains(th.Data, []byte("Exif")) {
+			t.Errorf("thumbnail %d still contains EXIF", th.Size)
+		}
+		if !isRed(img.At(2, th.Size/2)) || isRed(img.At(th.Size-3, th.Size/2)) {
+			t.Errorf("thumbnail %d: expected red on the left and blue on the right", th.Size)
+		}
+	}
+}
+
+func TestProcessOrientation(t *testing.T) {
+	tests := []struct {
+		orientation uint16
+		// titik yang harus merah setelah orientasi diterapkan, pada thumbnail 64×64
+		redAt image.Point
+	}{
+		{1, image.Pt(5, 32)},  // kiri
+		{3, image.Pt(58, 32)}, // kanan
+		{6, image.Pt(32, 5)},  // atas
+		{8, image.Pt(32, 58)}, // bawah
+	}
+	for _, tt := range tests {
+		data := withOrientation(encodeJPEG(t, leftRed(100, 100)), tt.orientation)
+		if got := exifOrientation(data); got != int(tt.orientation) {
+			t.Fatalf("exifOrientation = %d, want %d", got, tt.orientation)
+		}
+		res, err := Process(data, []int{64})
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, _ := jpeg.Decode(bytes.NewReader(res.Thumbnails[0].Data))
+		if !isRed(img.At(tt.redAt.X, tt.redAt.Y)) {
+			t.Errorf("orientation %d: pixel %v is not red", tt.orientation, tt.redAt)
+		}
+	}
+}
+
+func TestProcessPNGWithAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(5, 5, color.NRGBA{0, 255, 0, 128})
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+
+	res, err := Process(buf.Bytes(), []int{16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ContentType != "image/png" || res.Ext != "png" {
+		t.Errorf("transparent image encoded as %s", res.ContentType)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	jpg := encodeJPEG(t, leftRed(10, 10))
+	var huge bytes.Buffer
+	png.Encode(&huge, image.NewGray(image.Rect(0, 0, MaxDimension+1, 1)))
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("hello, not an image"), ErrUnsupportedType},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ErrUnsupportedType},
+		{"truncated jpeg", jpg[:len(jpg)/2], ErrInvalidImage},
+		{"too large", huge.Bytes(), ErrTooLarge},
+		// header kecil yang mengaku berdimensi besar tidak boleh sampai di-decode
+		{"too many pixels", pngHeader(MaxDimension, MaxPixels/MaxDimension+1), ErrTooLarge},
+		{"bomb", pngHeader(8000, 8000), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		if _, err := Process(tt.data, DefaultSizes); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// pngHeader membuat awal file PNG yang hanya berisi IHDR dengan dimensi w×h, cukup untuk image.DecodeConfig.
+func pngHeader(w, h int) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(w))
+	binary.BigEndian.PutUint32(ihdr[8:], uint32(h))
+	ihdr[12] = 8 // bit depth, color type 0 (grayscale)
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
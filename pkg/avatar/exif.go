@@ -0,0 +1,102 @@
+package avatar
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientation membaca tag Orientation (0x0112) dari segment APP1 Exif di JPEG.
+// Mengembalikan 1 (normal) jika tidak ada atau rusak.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		// SOS: setelah ini data gambar, tidak ada lagi segment metadata
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		seg := data[pos+4 : end]
+		if marker == 0xE1 && len(seg) > 6 && string(seg[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(seg[6:])
+		}
+		pos = end
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient memutar atau membalik gambar persegi sesuai nilai Orientation EXIF (1-8).
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	n := img.Bounds().Dx()
+	out := image.NewRGBA(img.Bounds())
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			// (sx, sy) adalah pixel sumber untuk pixel (x, y) di hasil
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontal
+				sx, sy = n-1-x, y
+			case 3: // putar 180°
+				sx, sy = n-1-x, n-1-y
+			case 4: // flip vertikal
+				sx, sy = x, n-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // putar 90° searah jarum jam
+				sx, sy = y, n-1-x
+			case 7: // transverse
+				sx, sy = n-1-y, n-1-x
+			case 8: // putar 90° berlawanan arah jarum jam
+				sx, sy = n-1-y, x
+			}
+			copy(out.Pix[out.PixOffset(x, y):out.PixOffset(x, y)+4], img.Pix[img.PixOffset(sx, sy):img.PixOffset(sx, sy)+4])
+		}
+	}
+	return out
+}
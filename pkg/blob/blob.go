@@ -0,0 +1,30 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Store menyimpan object biner seperti avatar berdasarkan key, misalnya "avatars/<id>/128.jpg".
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete tidak mengembalikan error jika object tidak ada.
+	Delete(ctx context.Context, key string) error
+	// URL mengembalikan alamat publik object, dipakai untuk avatar_url.
+	URL(key string) string
+}
+
+// ValidateKey menolak key kosong, absolut, atau yang keluar dari root lewat "..".
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) || path.Clean(key) != key ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("blob: invalid key %q", key)
+	}
+	return nil
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -0,0 +1,63 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileStore menyimpan object sebagai file di bawah Dir. BaseURL adalah alamat publik Dir,
+// misalnya "/media" jika Dir disajikan oleh service sendiri, atau URL CDN.
+type FileStore struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewFileStore(dir, baseURL string) *FileStore {
+	return &FileStore{Dir: dir, BaseURL: baseURL}
+}
+
+// Put menulis ke file sementara lalu rename, jadi pembaca tidak pernah melihat file setengah jadi.
+func (s *FileStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	name := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileStore) URL(key string) string {
+	return joinURL(s.BaseURL, key)
+}
@@ -0,0 +1,209 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Store menyimpan object di bucket S3 atau server lain dengan API yang sama (MinIO, R2, Spaces).
+// Request ditandatangani dengan AWS Signature Version 4.
+type S3Store struct {
+	// Endpoint misalnya https://s3.ap-southeast-1.amazonaws.com atau http://localhost:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle memakai {Endpoint}/{Bucket}/{key} (MinIO), selain itu {Bucket}.{host}/{key}
+	PathStyle bool
+	// PublicURL dipakai oleh URL, misalnya alamat CDN; kosong berarti alamat object di Endpoint
+	PublicURL string
+	Client    *http.Client
+
+	now func() time.Time
+}
+
+func NewS3Store(endpoint, region, bucket, accessKey, secretKey string, pathStyle bool, publicURL string) *S3Store {
+	return &S3Store{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		PathStyle: pathStyle,
+		PublicURL: publicURL,
+		Client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	sum := sha256.Sum256(data)
+	return s.do(req, hex.EncodeToString(sum[:]), http.StatusOK)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	// S3 mengembalikan 204 juga untuk object yang tidak ada, server lain kadang 404
+	return s.do(req, emptyPayloadHash, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+func (s *S3Store) URL(key string) string {
+	if s.PublicURL != "" {
+		return joinURL(s.PublicURL, key)
+	}
+	return s.objectURL(key)
+}
+
+func (s *S3Store) objectURL(key string) string {
+	if s.PathStyle {
+		return s.Endpoint + "/" + uriEncode(s.Bucket, false) + "/" + uriEncode(key, true)
+	}
+	scheme, host, _ := strings.Cut(s.Endpoint, "://")
+	return scheme + "://" + s.Bucket + "." + host + "/" + uriEncode(key, true)
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (s *S3Store) do(req *http.Request, payloadHash string, okStatus ...int) error {
+	s.sign(req, payloadHash, s.now().UTC())
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	for _, status := range okStatus {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	var e s3Error
+	if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+		return fmt.Errorf("s3: %s %s: %s: %s", req.Method, req.URL.Path, e.Code, e.Message)
+	}
+	return fmt.Errorf("s3: %s %s: %s", req.Method, req.URL.Path, resp.Status)
+}
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sign menambahkan header x-amz-date, x-amz-content-sha256 dan Authorization.
+// Semua header yang sudah ada di req ikut ditandatangani.
+func (s *S3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, true),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, false)+"="+uriEncode(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode meng-encode semua byte selain karakter unreserved RFC 3986, sesuai aturan SigV4.
+func uriEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
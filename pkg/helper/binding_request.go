@@ -1,31 +1,323 @@
 package helper
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"mime"
+	"mime/multipart"
 	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/gorilla/schema"
 )
 
-var decoder = schema.NewDecoder()
+const (
+	// DefaultMaxBodyBytes adalah batas ukuran body jika route tidak memakai WithMaxBytes.
+	DefaultMaxBodyBytes = 1 << 20
+	// DefaultMaxMemory adalah bagian multipart yang disimpan di memori, sisanya ke file sementara.
+	DefaultMaxMemory = 1 << 20
+)
+
+// decoder dipakai bersama oleh semua request, jadi opsinya tidak boleh diubah per request
+var (
+	strictDecoder  = newFormDecoder(false)
+	lenientDecoder = newFormDecoder(true)
+)
+
+// newFormDecoder memakai tag json untuk nama field form supaya sama dengan body JSON.
+func newFormDecoder(ignoreUnknown bool) *schema.Decoder {
+	d := schema.NewDecoder()
+	d.SetAliasTag("json")
+	d.IgnoreUnknownKeys(ignoreUnknown)
+	return d
+}
+
+type bindOptions struct {
+	maxBytes     int64
+	maxMemory    int64
+	allowUnknown bool
+	contentTypes []string
+}
+
+type BindOption func(*bindOptions)
+
+// WithMaxBytes mengganti batas ukuran body untuk satu route. Body yang lebih besar ditolak dengan 413.
+func WithMaxBytes(n int64) BindOption {
+	return func(o *bindOptions) {
+		o.maxBytes = n
+	}
+}
+
+// WithMaxMemory mengatur berapa byte multipart yang disimpan di memori sebelum ditulis ke file sementara.
+func WithMaxMemory(n int64) BindOption {
+	return func(o *bindOptions) {
+		o.maxMemory = n
+	}
+}
+
+// AllowUnknownFields menerima field yang tidak ada di struct tujuan. Default-nya ditolak dengan 400.
+func AllowUnknownFields() BindOption {
+	return func(o *bindOptions) {
+		o.allowUnknown = true
+	}
+}
+
+// WithContentTypes membatasi Content-Type yang diterima route, misalnya hanya multipart/form-data
+// untuk upload. Content-Type lain ditolak dengan 415.
+func WithContentTypes(types ...string) BindOption {
+	return func(o *bindOptions) {
+		o.contentTypes = types
+	}
+}
+
+// BindError adalah error dari BindRequest beserta status HTTP-nya: 400, 413 atau 415.
+type BindError struct {
+	Status int
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// BindStatus mengembalikan status HTTP untuk error dari BindRequest, 400 jika tidak diketahui.
+func BindStatus(err error) int {
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		return bindErr.Status
+	}
+	return http.StatusBadRequest
+}
+
+func badRequest(format string, args ...any) error {
+	return &BindError{Status: http.StatusBadRequest, Err: fmt.Errorf(format, args...)}
+}
+
+// BindRequest mengisi dst dari body application/json, application/x-www-form-urlencoded atau
+// multipart/form-data. Body dibatasi DefaultMaxBodyBytes, field yang tidak dikenal ditolak, dan
+// body JSON harus berisi tepat satu value. Untuk multipart, file dimasukkan ke field bertipe
+// *multipart.FileHeader atau []*multipart.FileHeader dengan nama dari tag json; file sementara
+// dihapus setelah request selesai.
+func BindRequest(r *http.Request, dst interface{}, opts ...BindOption) error {
+	o := bindOptions{
+		maxBytes:     DefaultMaxBodyBytes,
+		maxMemory:    DefaultMaxMemory,
+		contentTypes: []string{"application/json", "application/x-www-form-urlencoded", "multipart/form-data"},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-func BindRequest(r *http.Request, dst interface{}) error {
 	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !slices.Contains(o.contentTypes, contentType) {
+		return &BindError{
+			Status: http.StatusUnsupportedMediaType,
+			Err:    fmt.Errorf("unsupported Content-Type %q, expected %s", contentType, strings.Join(o.contentTypes, " or ")),
+		}
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, o.maxBytes)
 
+	var err error
 	switch contentType {
 	case "application/json":
-		return json.NewDecoder(r.Body).Decode(dst)
+		err = bindJSON(r.Body, dst, o)
+	case "application/x-www-form-urlencoded":
+		if err = r.ParseForm(); err == nil {
+			err = bindForm(r.PostForm, nil, dst, o)
+		}
+	case "multipart/form-data":
+		if err = r.ParseMultipartForm(o.maxMemory); err == nil {
+			form := r.MultipartForm
+			context.AfterFunc(r.Context(), func() { form.RemoveAll() })
+			err = bindForm(form.Value, form.File, dst, o)
+		}
+	}
+	return bindError(err, o)
+}
+
+// bindError memetakan error dari parsing body ke BindError dengan status yang sesuai.
+func bindError(err error, o bindOptions) error {
+	if err == nil {
+		return nil
+	}
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		return err
+	}
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return &BindError{
+			Status: http.StatusRequestEntityTooLarge,
+			Err:    fmt.Errorf("request body must not be larger than %d bytes", o.maxBytes),
+		}
+	}
+	return &BindError{Status: http.StatusBadRequest, Err: err}
+}
+
+func bindJSON(body io.Reader, dst interface{}, o bindOptions) error {
+	dec := json.NewDecoder(body)
+	if !o.allowUnknown {
+		dec.DisallowUnknownFields()
+	}
 
-	case "application/x-www-form-urlencoded", "multipart/form-data":
-		if err := r.ParseForm(); err != nil {
+	if err := dec.Decode(dst); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return badRequest("request body must not be empty")
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return badRequest("request body contains malformed JSON")
+		case errors.As(err, &syntaxErr):
+			return badRequest("request body contains malformed JSON at offset %d", syntaxErr.Offset)
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return badRequest("field %q must be %s, got %s", typeErr.Field, jsonKind(typeErr.Type), typeErr.Value)
+		case errors.As(err, &typeErr):
+			return badRequest("request body must be %s, got %s", jsonKind(typeErr.Type), typeErr.Value)
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return badRequest("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+		}
+		return err
+	}
+
+	// body harus berisi satu value saja, tidak boleh ada sisa selain whitespace
+	var rest json.RawMessage
+	if err := dec.Decode(&rest); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
 			return err
 		}
-		decoder.IgnoreUnknownKeys(true)
-		return decoder.Decode(dst, r.PostForm)
+		return badRequest("request body must contain a single JSON value")
+	}
+	return nil
+}
+
+// jsonKind menyebut tipe Go dengan nama tipe JSON untuk pesan error.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+func bindForm(values map[string][]string, files map[string][]*multipart.FileHeader, dst interface{}, o bindOptions) error {
+	fileFields := setFiles(dst, files)
+	if !o.allowUnknown {
+		for name := range files {
+			if _, ok := fileFields[name]; !ok {
+				return badRequest("unknown field %q", name)
+			}
+		}
+	}
+	for name := range values {
+		if _, ok := fileFields[name]; ok {
+			return badRequest("field %q must be a file", name)
+		}
+	}
+	for name, fhs := range files {
+		if fileFields[name] == fileHeaderType && len(fhs) > 1 {
+			return badRequest("field %q accepts a single file", name)
+		}
+	}
+
+	decoder := strictDecoder
+	if o.allowUnknown {
+		decoder = lenientDecoder
+	}
+	err := decoder.Decode(dst, values)
+	var multiErr schema.MultiError
+	if !errors.As(err, &multiErr) {
+		return err
+	}
 
+	// MultiError adalah map, ambil key terkecil supaya pesan error selalu sama
+	keys := make([]string, 0, len(multiErr))
+	for k := range multiErr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	key := keys[0]
+
+	var unknownErr schema.UnknownKeyError
+	var convErr schema.ConversionError
+	var emptyErr schema.EmptyFieldError
+	switch e := multiErr[key]; {
+	case errors.As(e, &unknownErr):
+		return badRequest("unknown field %q", unknownErr.Key)
+	case errors.As(e, &convErr):
+		return badRequest("field %q must be %s", key, formKind(convErr.Type))
+	case errors.As(e, &emptyErr):
+		return badRequest("field %q must not be empty", emptyErr.Key)
 	default:
-		return errors.New("unsupported Content-Type header")
+		return badRequest("field %q: %v", key, e)
+	}
+}
+
+// formKind seperti jsonKind untuk nilai form yang selalu berupa teks.
+func formKind(t reflect.Type) string {
+	if t.Kind() == reflect.Bool {
+		return "true or false"
+	}
+	return jsonKind(t)
+}
+
+// setFiles mengisi field file di dst dan mengembalikan nama form → tipe field file.
+func setFiles(dst interface{}, files map[string][]*multipart.FileHeader) map[string]reflect.Type {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+
+	fields := map[string]reflect.Type{}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Type != fileHeaderType && f.Type != fileHeadersType {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+
+		fhs := files[name]
+		switch {
+		case len(fhs) == 0:
+		case f.Type == fileHeaderType:
+			v.Field(i).Set(reflect.ValueOf(fhs[0]))
+		default:
+			v.Field(i).Set(reflect.ValueOf(fhs))
+		}
 	}
+	return fields
 }
@@ -0,0 +1,120 @@
+package helper
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindTarget struct {
+	Name   string                  `json:"name"`
+	Avatar *multipart.FileHeader   `json:"avatar"`
+	Photos []*multipart.FileHeader `json:"photos"`
+}
+
+// part adalah satu bagian multipart; file jika filename tidak kosong.
+type part struct {
+	name, filename, content string
+}
+
+func multipartRequest(t *testing.T, parts ...part) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		var w io.Writer
+		var err error
+		if p.filename != "" {
+			w, err = mw.CreateFormFile(p.name, p.filename)
+		} else {
+			w, err = mw.CreateFormField(p.name)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, p.content)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func request(contentType, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func TestBindRequestStatus(t *testing.T) {
+	big := strings.Repeat("a", 64)
+	tests := []struct {
+		name string
+		req  *http.Request
+		opts []BindOption
+		want int
+	}{
+		{"json", request("application/json", `{"name":"budi"}`), nil, 0},
+		{"json charset", request("application/json; charset=utf-8", `{"name":"budi"}`), nil, 0},
+		{"json too large", request("application/json", `{"name":"`+big+`"}`), []BindOption{WithMaxBytes(32)}, http.StatusRequestEntityTooLarge},
+		{"json trailing too large", request("application/json", `{"name":"budi"}`+strings.Repeat(" ", 64)), []BindOption{WithMaxBytes(32)}, http.StatusRequestEntityTooLarge},
+		{"json unknown field", request("application/json", `{"name":"budi","age":1}`), nil, http.StatusBadRequest},
+		{"json unknown field allowed", request("application/json", `{"name":"budi","age":1}`), []BindOption{AllowUnknownFields()}, 0},
+		{"form too large", request("application/x-www-form-urlencoded", "name="+big), []BindOption{WithMaxBytes(32)}, http.StatusRequestEntityTooLarge},
+		{"form unknown field allowed", request("application/x-www-form-urlencoded", "name=budi&age=1"), []BindOption{AllowUnknownFields()}, 0},
+		{"multipart too large", multipartRequest(t, part{"avatar", "a.png", big}), []BindOption{WithMaxBytes(64)}, http.StatusRequestEntityTooLarge},
+		{"multipart text for file field", multipartRequest(t, part{"avatar", "", "a.png"}), nil, http.StatusBadRequest},
+		{"multipart two files for single field", multipartRequest(t, part{"avatar", "a.png", "a"}, part{"avatar", "b.png", "b"}), nil, http.StatusBadRequest},
+		{"content type not allowed for route", request("application/json", `{}`), []BindOption{WithContentTypes("multipart/form-data")}, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst bindTarget
+			err := BindRequest(tt.req, &dst, tt.opts...)
+			switch {
+			case tt.want == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.want != 0 && err == nil:
+				t.Fatalf("expected status %d, got no error", tt.want)
+			case tt.want != 0 && BindStatus(err) != tt.want:
+				t.Fatalf("status = %d, want %d (%v)", BindStatus(err), tt.want, err)
+			}
+		})
+	}
+}
+
+func TestBindRequestMultipartFiles(t *testing.T) {
+	req := multipartRequest(t,
+		part{"name", "", "budi"},
+		part{"avatar", "avatar.png", "png"},
+		part{"photos", "1.jpg", "one"},
+		part{"photos", "2.jpg", "two"},
+	)
+
+	var dst bindTarget
+	if err := BindRequest(req, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != "budi" {
+		t.Errorf("Name = %q, want budi", dst.Name)
+	}
+	if dst.Avatar == nil || dst.Avatar.Filename != "avatar.png" {
+		t.Fatalf("Avatar = %+v, want avatar.png", dst.Avatar)
+	}
+	f, err := dst.Avatar.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if b, _ := io.ReadAll(f); string(b) != "png" {
+		t.Errorf("Avatar content = %q, want png", b)
+	}
+	if len(dst.Photos) != 2 || dst.Photos[0].Filename != "1.jpg" || dst.Photos[1].Filename != "2.jpg" {
+		t.Errorf("Photos = %+v, want 1.jpg and 2.jpg", dst.Photos)
+	}
+}
@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"strings"
+)
+
+// asciiSpace adalah whitespace yang dibuang NormalizeEmail, sama dengan btrim di fungsi SQL canonical_email.
+// Spasi Unicode seperti U+00A0 tidak ikut dibuang supaya kunci di Go dan database tidak berbeda.
+const asciiSpace = " \t\n\r\f\v"
+
+// NormalizeEmail menormalkan alamat email sebelum disimpan atau dicari: whitespace ASCII di awal dan akhir dibuang
+// dan domain dijadikan huruf kecil. Local part dibiarkan apa adanya karena hanya server tujuan yang berhak
+// menafsirkannya; keunikan memakai CanonicalEmail.
+//
+// Dengan providerRules, aturan provider di emailProviders juga dipakai, misalnya alamat Gmail dibuat
+// kanonik sehingga b.udi+promo@gmail.com dan budi@googlemail.com sama dengan budi@gmail.com.
+func NormalizeEmail(email string, providerRules bool) string {
+	email = strings.Trim(email, asciiSpace)
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], strings.ToLower(email[at+1:])
+	if rule, ok := emailProviders[domain]; ok && providerRules {
+		if l, d := rule(local); l != "" {
+			local, domain = l, d
+		}
+	}
+	return local + "@" + domain
+}
+
+// CanonicalEmail mengembalikan kunci keunikan dan pencarian email: NormalizeEmail dengan aturan provider,
+// lalu huruf kecil semua. Kunci ini tidak bergantung pada EMAIL_PROVIDER_RULES, jadi flag itu boleh dinyalakan
+// kapan saja tanpa membuat duplikat di antara alamat yang sudah tersimpan. Harus sama dengan fungsi SQL
+// canonical_email (migrasi 000007) yang mengisi kolom users.email_canonical.
+func CanonicalEmail(email string) string {
+	return strings.ToLower(NormalizeEmail(email, true))
+}
+
+// emailProviders berisi aturan per domain (huruf kecil) untuk NormalizeEmail, mengembalikan local part dan
+// domain kanonik. Local part kosong berarti alamat dibiarkan.
+var emailProviders = map[string]func(local string) (string, string){
+	"gmail.com":      gmailAddress,
+	"googlemail.com": gmailAddress,
+}
+
+// gmailAddress: Gmail mengabaikan huruf besar, titik dan +tag di local part.
+func gmailAddress(local string) (string, string) {
+	local = strings.ToLower(local)
+	if i := strings.IndexByte(local, '+'); i >= 0 {
+		local = local[:i]
+	}
+	return strings.ReplaceAll(local, ".", ""), "gmail.com"
+}
@@ -0,0 +1,52 @@
+package helper
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email         string
+		providerRules bool
+		want          string
+	}{
+		{"  Budi@Example.COM \n", false, "Budi@example.com"},
+		{"budi@example.com", false, "budi@example.com"},
+		{"B.udi+Promo@GMail.com", false, "B.udi+Promo@gmail.com"},
+		{"B.udi+Promo@GMail.com", true, "budi@gmail.com"},
+		{"budi@googlemail.com", true, "budi@gmail.com"},
+		// +tag hanya dibuang untuk provider yang dikenal
+		{"budi+promo@example.com", true, "budi+promo@example.com"},
+		// local part yang habis oleh aturan provider dibiarkan
+		{"+promo@gmail.com", true, "+promo@gmail.com"},
+		{"no-at-sign", true, "no-at-sign"},
+		{`"a@b"@Example.com`, false, `"a@b"@example.com`},
+		// hanya whitespace ASCII yang dibuang, sama dengan canonical_email di database
+		{"\t budi@example.com\v\f\r", false, "budi@example.com"},
+		{"\u00a0budi@example.com\u00a0", false, "\u00a0budi@example.com\u00a0"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeEmail(tt.email, tt.providerRules); got != tt.want {
+			t.Errorf("NormalizeEmail(%q, %v) = %q, want %q", tt.email, tt.providerRules, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"  Budi@Example.COM \n", "budi@example.com"},
+		{"B.udi+Promo@GMail.com", "budi@gmail.com"},
+		{"budi@googlemail.com", "budi@gmail.com"},
+		{"Budi+Promo@example.com", "budi+promo@example.com"},
+		{"+Promo@GoogleMail.com", "+promo@googlemail.com"},
+		{"No-At-Sign", "no-at-sign"},
+		{`"A@b"@Example.com`, `"a@b"@example.com`},
+		{"\u00a0Budi@Example.COM\u2003", "\u00a0budi@example.com\u2003"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalEmail(tt.email); got != tt.want {
+			t.Errorf("CanonicalEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
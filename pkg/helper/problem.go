@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType adalah media type RFC 9457.
+const ProblemContentType = "application/problem+json"
+
+// Problem adalah body error RFC 9457, dikirim jika client meminta application/problem+json lewat Accept.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// InvalidParams diisi untuk error validasi, satu entri per field
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	// Code adalah kode stabil dari FieldError.Code, misalnya "required"
+	Code string `json:"code,omitempty"`
+}
+
+// NewProblem membangun Problem dari err dengan bentuk yang sama seperti argumen WriteError:
+// string, error, atau ValidationErrors.
+func NewProblem(r *http.Request, statusCode int, err any) Problem {
+	p := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Instance: r.URL.Path,
+	}
+
+	switch e := err.(type) {
+	case string:
+		p.Detail = e
+	case ValidationErrors:
+		p.Detail = "request validation failed"
+		for _, fe := range e {
+			p.InvalidParams = append(p.InvalidParams, InvalidParam{Name: fe.Field, Reason: fe.Message, Code: fe.Code})
+		}
+		sort.SliceStable(p.InvalidParams, func(i, j int) bool {
+			return p.InvalidParams[i].Name < p.InvalidParams[j].Name
+		})
+	case error:
+		p.Detail = e.Error()
+	}
+	return p
+}
+
+// WantsProblem bernilai true jika Accept lebih memilih application/problem+json daripada
+// application/json. Client tanpa Accept atau dengan */* tetap mendapat format lama.
+func WantsProblem(r *http.Request) bool {
+	var problemQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case ProblemContentType:
+			problemQ = max(problemQ, q)
+		case "application/json", "application/*", "*/*":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
@@ -14,6 +14,8 @@ type SuccessResponse struct {
 type ErrorResponse struct {
 	Status string `json:"status"` // always "error"
 	Errors any    `json:"errors"`
+	// Details berisi kode error per field, hanya untuk error validasi
+	Details []FieldError `json:"details,omitempty"`
 }
 
 func WriteJSON(w http.ResponseWriter, statusCode int, payload any) {
@@ -38,10 +40,24 @@ func WriteCreated(w http.ResponseWriter, data any) {
 	WriteJSON(w, http.StatusCreated, resp)
 }
 
-func WriteError(w http.ResponseWriter, statusCode int, err any) {
+// WriteError menulis error sebagai application/problem+json jika client memintanya lewat Accept,
+// selain itu memakai ErrorResponse. err boleh berupa string, error, atau ValidationErrors.
+func WriteError(w http.ResponseWriter, r *http.Request, statusCode int, err any) {
+	w.Header().Add("Vary", "Accept")
+	if WantsProblem(r) {
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(NewProblem(r, statusCode, err))
+		return
+	}
+
 	resp := ErrorResponse{
 		Status: "error",
 		Errors: err,
 	}
+	if vErrs, ok := err.(ValidationErrors); ok {
+		resp.Errors = vErrs.Messages()
+		resp.Details = vErrs
+	}
 	WriteJSON(w, statusCode, resp)
 }
@@ -2,58 +2,245 @@ package helper
 
 import (
 	"errors"
-	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
+	"user-service/pkg/fetch"
+	"user-service/pkg/phone"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
 )
 
-func GenerateMessage(err error, source string) map[string]string {
-	var vErr validator.ValidationErrors
-	if errors.As(err, &vErr) {
-		messages := make(map[string]string, len(vErr))
-		for _, v := range vErr {
-			switch v.Tag() {
-			case "email":
-				messages[v.Field()] = fmt.Sprintf("%s %s is not valid email", source, v.Value())
-			case "required":
-				messages[v.Field()] = fmt.Sprintf("%s %s is required", source, v.Field())
-			case "min":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be at least %s characters", source, v.Field(), v.Param())
-			case "max":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be at most %s characters", source, v.Field(), v.Param())
-			case "len":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be exactly %s characters", source, v.Field(), v.Param())
-			case "uuid":
-				messages[v.Field()] = fmt.Sprintf("%s %s is not a valid UUID", source, v.Field())
-			case "alpha":
-				messages[v.Field()] = fmt.Sprintf("%s %s must only contain alphabetic characters", source, v.Field())
-			case "alpha_dash":
-				messages[v.Field()] = fmt.Sprintf("%s %s must only contain alphabetic characters, dashes, and underscores", source, v.Field())
-			case "alpha_num":
-				messages[v.Field()] = fmt.Sprintf("%s %s must only contain alphabetic characters and numbers", source, v.Field())
-			case "numeric":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be a numeric value", source, v.Field())
-			case "gt":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be greater than %s", source, v.Field(), v.Param())
-			case "gte":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be greater than or equal to %s", source, v.Field(), v.Param())
-			case "lt":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be less than %s", source, v.Field(), v.Param())
-			case "lte":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be less than or equal to %s", source, v.Field(), v.Param())
-			case "url":
-				messages[v.Field()] = fmt.Sprintf("%s %s is not a valid URL", source, v.Field())
-			case "hex":
-				messages[v.Field()] = fmt.Sprintf("%s %s must be a valid hexadecimal string", source, v.Field())
-			case "date":
-				messages[v.Field()] = fmt.Sprintf("%s %s is not a valid date, e.g: 2006-01-02", source, v.Field())
-			case "timezone":
-				messages[v.Field()] = fmt.Sprintf("%s %s is not a valid timezone e.g: UTC,+08:00,Asia,Jakarta,America,New_York", source, v.Field())
-			case "ip":
-				messages[v.Field()] = fmt.Sprintf("%s %s is not a valid IP address", source, v.Field())
+// DefaultLocale dipakai jika Accept-Language kosong atau tidak ada locale yang didukung.
+const DefaultLocale = "en"
+
+// fallbackKey adalah pesan untuk tag validator yang tidak punya terjemahan.
+const fallbackKey = "fallback"
+
+var locales = []struct {
+	name     string
+	register func(*validator.Validate, ut.Translator) error
+	fallback string
+	// custom berisi pesan untuk tag yang didaftarkan di customValidations
+	custom map[string]string
+}{
+	{"en", en_translations.RegisterDefaultTranslations, "{0} failed the '{1}' validation", map[string]string{
+		"public_url": "{0} must be a public http or https URL",
+		"phone":      "{0} must be a valid phone number, e.g. +6281234567890",
+	}},
+	{"id", id_translations.RegisterDefaultTranslations, "{0} tidak memenuhi validasi '{1}'", map[string]string{
+		"public_url": "{0} harus berupa URL http atau https publik",
+		"phone":      "{0} harus berupa nomor telepon yang valid, misalnya +6281234567890",
+	}},
+}
+
+// customValidations adalah tag tambahan di luar bawaan validator.
+var customValidations = map[string]validator.Func{
+	// public_url: URL http(s) absolut tanpa kredensial yang tidak menunjuk ke localhost atau IP internal
+	"public_url": func(fl validator.FieldLevel) bool {
+		u, err := url.Parse(fl.Field().String())
+		return err == nil && fetch.CheckURL(u) == nil
+	},
+}
+
+// Validator membungkus validator.Validate beserta katalog pesan untuk setiap locale.
+// Terjemahan terikat ke instance validator, jadi selalu buat lewat NewValidator.
+type Validator struct {
+	*validator.Validate
+	uni *ut.UniversalTranslator
+}
+
+// ValidatorOption mengubah aturan validator yang bergantung pada config.
+type ValidatorOption func(*validatorConfig)
+
+type validatorConfig struct {
+	phoneRegion string
+}
+
+// WithPhoneRegion membuat tag phone juga menerima nomor nasional dari region (PHONE_DEFAULT_REGION).
+// Tanpa opsi ini hanya nomor internasional yang diawali + atau 00 yang lolos.
+func WithPhoneRegion(region string) ValidatorOption {
+	return func(c *validatorConfig) { c.phoneRegion = region }
+}
+
+// NewValidator membuat validator dengan nama field dari tag json/query dan pesan en serta id.
+func NewValidator(opts ...ValidatorOption) *Validator {
+	var cfg validatorConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+	for tag, fn := range customValidations {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+	// phone: nomor yang bisa dinormalkan ke E.164 oleh phone.Parse
+	err := v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		_, err := phone.Parse(fl.Field().String(), cfg.phoneRegion)
+		return err == nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	uni := ut.New(en.New(), en.New(), id.New())
+	for _, l := range locales {
+		trans, _ := uni.GetTranslator(l.name)
+		if err := l.register(v, trans); err != nil {
+			panic(err)
+		}
+		if err := trans.Add(fallbackKey, l.fallback, false); err != nil {
+			panic(err)
+		}
+		for tag, msg := range l.custom {
+			if err := v.RegisterTranslation(tag, trans, registerMessage(tag, msg), translateField); err != nil {
+				panic(err)
 			}
 		}
-		return messages
 	}
-	return map[string]string{"error": err.Error()}
+	return &Validator{Validate: v, uni: uni}
+}
+
+func registerMessage(tag, msg string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, msg, false)
+	}
+}
+
+func translateField(trans ut.Translator, fe validator.FieldError) string {
+	msg, _ := trans.T(fe.Tag(), fe.Field())
+	return msg
+}
+
+// fieldName memakai nama yang dilihat client: tag json, lalu tag query, lalu nama field Go.
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "query"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// FieldError adalah satu pelanggaran validasi. Code adalah nama tag validator
+// (misalnya "required", "email", "oneof") dan stabil untuk dipakai client.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+	// Source: constants.FromRequestBody atau constants.FromQueryParams
+	Source string `json:"source,omitempty"`
+}
+
+// ValidationErrors dikirim lewat WriteError: sebagai map field → pesan ditambah details,
+// atau invalid-params untuk application/problem+json.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Messages mengembalikan map field → pesan, bentuk lama dari field "errors".
+func (e ValidationErrors) Messages() map[string]string {
+	messages := make(map[string]string, len(e))
+	for _, fe := range e {
+		messages[fe.Field] = fe.Message
+	}
+	return messages
+}
+
+// Translate mengubah error dari Struct menjadi ValidationErrors dalam bahasa trans.
+// Tag tanpa terjemahan memakai pesan generik, jadi tidak ada pelanggaran yang hilang.
+func (v *Validator) Translate(err error, source string, trans ut.Translator) ValidationErrors {
+	var vErrs validator.ValidationErrors
+	if !errors.As(err, &vErrs) {
+		return ValidationErrors{{Field: "", Code: "invalid", Message: err.Error(), Source: source}}
+	}
+
+	out := make(ValidationErrors, 0, len(vErrs))
+	for _, fe := range vErrs {
+		msg := fe.Translate(trans)
+		// validator mengembalikan fe.Error() jika tag tidak punya terjemahan
+		if msg == fe.Error() {
+			msg, _ = trans.T(fallbackKey, fe.Field(), fe.Tag())
+		}
+		out = append(out, FieldError{
+			Field:   fe.Field(),
+			Code:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: msg,
+			Source:  source,
+		})
+	}
+	return out
+}
+
+// TranslateRequest sama seperti Translate dengan locale dari header Accept-Language.
+func (v *Validator) TranslateRequest(r *http.Request, err error, source string) ValidationErrors {
+	return v.Translate(err, source, v.TranslatorFor(r.Header.Get("Accept-Language")))
+}
+
+// Translator mengembalikan translator untuk locale, atau DefaultLocale jika tidak didukung.
+func (v *Validator) Translator(locale string) ut.Translator {
+	trans, _ := v.uni.FindTranslator(locale, DefaultLocale)
+	return trans
+}
+
+// TranslatorFor memilih translator dari nilai Accept-Language, misalnya "id-ID,id;q=0.9,en;q=0.8".
+func (v *Validator) TranslatorFor(acceptLanguage string) ut.Translator {
+	trans, _ := v.uni.FindTranslator(append(parseAcceptLanguage(acceptLanguage), DefaultLocale)...)
+	return trans
+}
+
+// parseAcceptLanguage mengurutkan bahasa berdasarkan q. Setiap tag regional (id-ID) diikuti
+// bahasa dasarnya (id) karena katalog hanya berisi bahasa dasar.
+func parseAcceptLanguage(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+	var langs []lang
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			langs = append(langs, lang{tag: strings.ToLower(tag), q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	var out []string
+	for _, l := range langs {
+		out = append(out, strings.ReplaceAll(l.tag, "-", "_"))
+		if base, _, ok := strings.Cut(l.tag, "-"); ok {
+			out = append(out, base)
+		}
+	}
+	return out
 }
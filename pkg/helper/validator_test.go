@@ -0,0 +1,120 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestTranslateFallback(t *testing.T) {
+	v := NewValidator()
+	// tag tanpa terjemahan di katalog mana pun
+	if err := v.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := struct {
+		Count int    `json:"count" validate:"even"`
+		Email string `json:"email" validate:"required,email"`
+	}{Count: 3, Email: "x"}
+	err := v.Struct(&req)
+
+	tests := []struct {
+		locale string
+		want   ValidationErrors
+	}{
+		{"en", ValidationErrors{
+			{Field: "count", Code: "even", Message: "count failed the 'even' validation", Source: "body"},
+			{Field: "email", Code: "email", Message: "email must be a valid email address", Source: "body"},
+		}},
+		{"id", ValidationErrors{
+			{Field: "count", Code: "even", Message: "count tidak memenuhi validasi 'even'", Source: "body"},
+			{Field: "email", Code: "email", Message: "email harus berupa alamat email yang valid", Source: "body"},
+		}},
+	}
+	for _, tt := range tests {
+		got := v.Translate(err, "body", v.Translator(tt.locale))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestTranslatorFor(t *testing.T) {
+	v := NewValidator()
+	tests := map[string]string{
+		"":                        "en",
+		"id":                      "id",
+		"id-ID,id;q=0.9,en;q=0.8": "id",
+		"en-US,id;q=0.5":          "en",
+		"fr-FR,id;q=0.7,en;q=0.3": "id",
+		"fr, de":                  "en",
+		"id;q=0, en":              "en",
+		"*":                       "en",
+	}
+	for header, want := range tests {
+		if got := v.TranslatorFor(header).Locale(); got != want {
+			t.Errorf("TranslatorFor(%q) = %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	v := NewValidator()
+	type req struct {
+		AvatarURL string `json:"avatar_url" validate:"omitempty,public_url"`
+	}
+
+	for _, ok := range []string{"", "https://example.com/a.png", "http://cdn.example.com:8080/a.png"} {
+		if err := v.Struct(&req{AvatarURL: ok}); err != nil {
+			t.Errorf("%q: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"javascript:alert(1)", "/a.png", "http://127.0.0.1/a.png", "http://localhost/a.png", "https://u:p@example.com/"} {
+		err := v.Struct(&req{AvatarURL: bad})
+		if err == nil {
+			t.Errorf("%q: expected an error", bad)
+			continue
+		}
+		got := v.Translate(err, "body", v.Translator("id"))
+		if want := "avatar_url harus berupa URL http atau https publik"; got[0].Message != want || got[0].Code != "public_url" {
+			t.Errorf("%q: got %+v, want %q", bad, got[0], want)
+		}
+	}
+}
+
+func TestPhone(t *testing.T) {
+	type req struct {
+		PhoneNumber string `json:"phone_number" validate:"omitempty,phone"`
+	}
+	tests := []struct {
+		region string
+		ok     []string
+		bad    []string
+	}{
+		{"", []string{"", "+62 812-3456-7890", "0044 20 7946 0958"}, []string{"081234567890", "+62 12", "abc"}},
+		{"ID", []string{"081234567890", "+6581234567"}, []string{"0812", "+999 1234567"}},
+	}
+	for _, tt := range tests {
+		v := NewValidator(WithPhoneRegion(tt.region))
+		for _, ok := range tt.ok {
+			if err := v.Struct(&req{PhoneNumber: ok}); err != nil {
+				t.Errorf("region %q, %q: %v", tt.region, ok, err)
+			}
+		}
+		for _, bad := range tt.bad {
+			err := v.Struct(&req{PhoneNumber: bad})
+			if err == nil {
+				t.Errorf("region %q, %q: expected an error", tt.region, bad)
+				continue
+			}
+			got := v.Translate(err, "body", v.Translator("en"))
+			if want := "phone_number must be a valid phone number, e.g. +6281234567890"; got[0].Message != want || got[0].Code != "phone" {
+				t.Errorf("region %q, %q: got %+v, want %q", tt.region, bad, got[0], want)
+			}
+		}
+	}
+}
@@ -1,19 +1,41 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"sync"
 
 	"user-service/config"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var Log = logrus.New()
 
+var (
+	mu sync.RWMutex
+	// named menyimpan logger per package, level-nya bisa diubah terpisah dari Log.
+	named = map[string]*Logger{}
+	// overrides berisi package yang level-nya di-set manual, sisanya ikut level Log.
+	overrides = map[string]logrus.Level{}
+	// configuredLevel adalah level dari LOG_LEVEL, dipakai saat toggle debug dimatikan.
+	configuredLevel = logrus.InfoLevel
+)
+
+// Logger adalah logrus.Logger milik satu package (lihat Named).
+type Logger struct {
+	*logrus.Logger
+	name string
+}
+
 func Init(cfg *config.AppConfig) {
 	Log.SetOutput(os.Stdout)
 
-	if cfg.AppEnv == "prod" || cfg.AppEnv == "production" {
+	if cfg.IsProduction() {
 		Log.SetFormatter(&logrus.JSONFormatter{})
 	} else {
 		Log.SetFormatter(&logrus.TextFormatter{
@@ -21,5 +43,206 @@ func Init(cfg *config.AppConfig) {
 		})
 	}
 
-	Log.SetLevel(logrus.InfoLevel)
+	level, err := logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		Log.Warnf("invalid LOG_LEVEL %q, falling back to info", cfg.LogLevel)
+		level = logrus.InfoLevel
+	}
+
+	mu.Lock()
+	configuredLevel = level
+	mu.Unlock()
+
+	SetLevel("", level)
+}
+
+// SetOutput mengganti output Log dan semua logger package, misalnya ke stderr untuk CLI.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	Log.SetOutput(w)
+	for _, l := range named {
+		syncLogger(l)
+	}
+}
+
+// Named mengembalikan logger untuk package tertentu, misalnya Named("db").
+// Output dan formatter mengikuti Log, level mengikuti Log kecuali di-override lewat SetLevel.
+func Named(name string) *Logger {
+	mu.RLock()
+	l, ok := named[name]
+	mu.RUnlock()
+	if ok {
+		return l
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if l, ok := named[name]; ok {
+		return l
+	}
+
+	l = &Logger{Logger: logrus.New(), name: name}
+	syncLogger(l)
+	named[name] = l
+	return l
+}
+
+// syncLogger menyalin output, formatter dan level dari Log. Harus dipanggil dengan mu terkunci.
+func syncLogger(l *Logger) {
+	l.SetOutput(Log.Out)
+	l.SetFormatter(Log.Formatter)
+	l.ReplaceHooks(Log.Hooks)
+	if level, ok := overrides[l.name]; ok {
+		l.SetLevel(level)
+	} else {
+		l.SetLevel(Log.GetLevel())
+	}
+}
+
+// SetLevel mengubah level saat runtime. Jika name kosong yang diubah level root (Log)
+// beserta semua package yang tidak di-override.
+func SetLevel(name string, level logrus.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	setLevel(name, level)
+}
+
+// setLevel adalah isi SetLevel. Harus dipanggil dengan mu terkunci.
+func setLevel(name string, level logrus.Level) {
+	if name == "" {
+		Log.SetLevel(level)
+	} else {
+		overrides[name] = level
+	}
+	for _, l := range named {
+		syncLogger(l)
+	}
+}
+
+// ResetLevel menghapus override level milik package sehingga kembali mengikuti root.
+func ResetLevel(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(overrides, name)
+	if l, ok := named[name]; ok {
+		syncLogger(l)
+	}
+}
+
+// Levels mengembalikan level root dan level setiap package yang sudah terdaftar.
+func Levels() (string, map[string]string) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	packages := make(map[string]string, len(named))
+	for name, l := range named {
+		packages[name] = l.GetLevel().String()
+	}
+	return Log.GetLevel().String(), packages
+}
+
+// Packages mengembalikan nama package yang punya logger sendiri, terurut.
+func Packages() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseLevel sama dengan logrus.ParseLevel dengan pesan error yang lebih jelas.
+func ParseLevel(s string) (logrus.Level, error) {
+	level, err := logrus.ParseLevel(s)
+	if err != nil {
+		return level, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
+
+// ToggleDebug mengganti level root antara debug dan level dari LOG_LEVEL (dipakai oleh SIGUSR1).
+// Level dibaca dan diubah dalam satu lock, jadi dua sinyal yang datang bersamaan tidak membaca level yang sama.
+func ToggleDebug() logrus.Level {
+	mu.Lock()
+	defer mu.Unlock()
+
+	level := configuredLevel
+	if Log.GetLevel() != logrus.DebugLevel {
+		level = logrus.DebugLevel
+	}
+	setLevel("", level)
+	return level
+}
+
+type ctxKey struct{}
+
+// fields disimpan sebagai pointer supaya field yang ditambahkan belakangan (route, user_id)
+// ikut terlihat oleh access log yang memegang context induknya.
+type fields struct {
+	mu     sync.RWMutex
+	values logrus.Fields
+}
+
+// NewContext menempelkan field logging (request_id, method, dll) ke context.
+func NewContext(ctx context.Context, f logrus.Fields) context.Context {
+	values := make(logrus.Fields, len(f))
+	if parent, ok := ctx.Value(ctxKey{}).(*fields); ok {
+		parent.mu.RLock()
+		for k, v := range parent.values {
+			values[k] = v
+		}
+		parent.mu.RUnlock()
+	}
+	for k, v := range f {
+		values[k] = v
+	}
+	return context.WithValue(ctx, ctxKey{}, &fields{values: values})
+}
+
+// AddFields menambahkan field ke logger milik request yang sedang berjalan.
+// Tidak melakukan apa-apa jika context belum punya logger dari NewContext.
+func AddFields(ctx context.Context, f logrus.Fields) {
+	holder, ok := ctx.Value(ctxKey{}).(*fields)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	for k, v := range f {
+		holder.values[k] = v
+	}
+	holder.mu.Unlock()
+}
+
+// FromContext mengembalikan logger yang sudah diperkaya dengan field request dan trace_id/span_id.
+func FromContext(ctx context.Context) *logrus.Entry {
+	return entryFromContext(Log, ctx)
+}
+
+// FromContext sama seperti FromContext milik package, tapi memakai level logger ini
+// dan menambahkan field "package".
+func (l *Logger) FromContext(ctx context.Context) *logrus.Entry {
+	return entryFromContext(l.Logger, ctx).WithField("package", l.name)
+}
+
+func entryFromContext(l *logrus.Logger, ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(l).WithContext(ctx)
+	if holder, ok := ctx.Value(ctxKey{}).(*fields); ok {
+		holder.mu.RLock()
+		entry = entry.WithFields(holder.values)
+		holder.mu.RUnlock()
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		entry = entry.WithFields(logrus.Fields{
+			"trace_id": sc.TraceID().String(),
+			"span_id":  sc.SpanID().String(),
+		})
+	}
+	return entry
 }
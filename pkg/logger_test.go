@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// resetLevels mengembalikan level root, override dan LOG_LEVEL setelah test selesai.
+func resetLevels(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	root, configured, saved := Log.GetLevel(), configuredLevel, overrides
+	overrides = map[string]logrus.Level{}
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		configuredLevel, overrides = configured, saved
+		mu.Unlock()
+		SetLevel("", root)
+	})
+}
+
+func TestSetLevelOverride(t *testing.T) {
+	resetLevels(t)
+
+	a, b := Named("test-a"), Named("test-b")
+	SetLevel("", logrus.InfoLevel)
+	SetLevel("test-a", logrus.DebugLevel)
+	if a.GetLevel() != logrus.DebugLevel || b.GetLevel() != logrus.InfoLevel {
+		t.Fatalf("after override: test-a=%s test-b=%s, want debug, info", a.GetLevel(), b.GetLevel())
+	}
+
+	// override bertahan saat level root berubah, package lain ikut root
+	SetLevel("", logrus.WarnLevel)
+	if a.GetLevel() != logrus.DebugLevel || b.GetLevel() != logrus.WarnLevel {
+		t.Errorf("after root change: test-a=%s test-b=%s, want debug, warn", a.GetLevel(), b.GetLevel())
+	}
+	root, packages := Levels()
+	if root != "warning" || packages["test-a"] != "debug" || packages["test-b"] != "warning" {
+		t.Errorf("Levels() = %s, %v", root, packages)
+	}
+
+	ResetLevel("test-a")
+	if a.GetLevel() != logrus.WarnLevel {
+		t.Errorf("after reset: test-a=%s, want warning", a.GetLevel())
+	}
+
+	// override yang di-set sebelum logger dibuat tetap dipakai
+	SetLevel("test-c", logrus.ErrorLevel)
+	if c := Named("test-c"); c.GetLevel() != logrus.ErrorLevel {
+		t.Errorf("test-c created after override: level %s, want error", c.GetLevel())
+	}
+}
+
+func TestToggleDebug(t *testing.T) {
+	resetLevels(t)
+
+	mu.Lock()
+	configuredLevel = logrus.WarnLevel
+	mu.Unlock()
+	SetLevel("", logrus.WarnLevel)
+	pkg := Named("test-toggle")
+
+	if got := ToggleDebug(); got != logrus.DebugLevel || Log.GetLevel() != logrus.DebugLevel || pkg.GetLevel() != logrus.DebugLevel {
+		t.Fatalf("first toggle = %s, root %s, package %s; want debug", got, Log.GetLevel(), pkg.GetLevel())
+	}
+	if got := ToggleDebug(); got != logrus.WarnLevel || Log.GetLevel() != logrus.WarnLevel {
+		t.Fatalf("second toggle = %s, root %s; want warning", got, Log.GetLevel())
+	}
+
+	// setiap toggle membalik level, jadi jumlah toggle genap selalu kembali ke LOG_LEVEL
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				ToggleDebug()
+			}
+		}()
+	}
+	wg.Wait()
+	if Log.GetLevel() != logrus.WarnLevel {
+		t.Errorf("after 400 concurrent toggles: root %s, want warning", Log.GetLevel())
+	}
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer menulis setiap email sebagai file .eml di Dir, untuk development dan test.
+// File-nya bisa dibuka dengan email client biasa.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{Dir: dir, From: from}
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	now := time.Now()
+	data, err := Encode(m.From, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	// nama file terurut menurut waktu, suffix acak mencegah tabrakan
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := now.UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o600)
+}
@@ -0,0 +1,28 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogMailer tidak mengirim apa pun, hanya menulis email ke log. Hanya untuk development:
+// isi email (termasuk link dengan token) ikut tercatat di log.
+type LogMailer struct {
+	Log logrus.FieldLogger
+}
+
+func NewLogMailer(log logrus.FieldLogger) *LogMailer {
+	return &LogMailer{Log: log}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	if _, err := envelope(msg.To); err != nil {
+		return err
+	}
+	m.Log.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	}).Info("email (not sent):\n" + msg.Text)
+	return nil
+}
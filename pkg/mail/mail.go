@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMessage dikembalikan jika alamat atau subject tidak valid, misalnya berisi baris baru.
+var ErrInvalidMessage = errors.New("invalid email message")
+
+// Message adalah email teks biasa untuk satu penerima.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer mengirim email. Implementasinya: SMTPMailer untuk production, FileMailer dan LogMailer
+// untuk development.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Encode menyusun msg menjadi email RFC 5322 (UTF-8, quoted-printable) dari alamat from.
+func Encode(from string, msg Message, date time.Time) ([]byte, error) {
+	sender, err := netmail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from %q: %v", ErrInvalidMessage, from, err)
+	}
+	rcpt, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to %q: %v", ErrInvalidMessage, msg.To, err)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("%w: subject must not contain line breaks", ErrInvalidMessage)
+	}
+
+	var b bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+	}
+	header("From", sender.String())
+	header("To", rcpt.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(sender.Address))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(msg.Text, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok {
+		domain = d
+	}
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return "<" + hex.EncodeToString(buf) + "@" + domain + ">"
+}
+
+// envelope mengembalikan alamat email saja (tanpa nama) untuk MAIL FROM dan RCPT TO.
+func envelope(address string) (string, error) {
+	a, err := netmail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %v", ErrInvalidMessage, address, err)
+	}
+	return a.Address, nil
+}
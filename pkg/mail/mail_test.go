@@ -0,0 +1,163 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	date := time.Date(2025, 1, 1, 7, 0, 0, 0, time.UTC)
+	msg := Message{
+		To:      "Dewi <dewi@example.com>",
+		Subject: "Verifikasi email Anda ✓",
+		Text:    "Halo Dewi,\n\nBuka https://example.com/verify-email?token=abc=def\n",
+	}
+	data, err := Encode("User Service <no-reply@example.com>", msg, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, body, _ := strings.Cut(string(data), "\r\n\r\n")
+	for _, want := range []string{
+		`From: "User Service" <no-reply@example.com>`,
+		`To: "Dewi" <dewi@example.com>`,
+		"Subject: =?utf-8?q?Verifikasi_email_Anda_=E2=9C=93?=",
+		"Date: Wed, 01 Jan 2025 07:00:00 +0000",
+		"Content-Transfer-Encoding: quoted-printable",
+	} {
+		if !strings.Contains(head+"\r\n", want+"\r\n") {
+			t.Errorf("header %q not found in:\n%s", want, head)
+		}
+	}
+	if !strings.Contains(head, "Message-ID: <") || !strings.Contains(head, "@example.com>") {
+		t.Errorf("Message-ID missing in:\n%s", head)
+	}
+	if want := "Halo Dewi,\r\n\r\nBuka https://example.com/verify-email?token=3Dabc=3Ddef\r\n"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestEncodeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		msg  Message
+	}{
+		{"subject injection", "a@example.com", Message{To: "b@example.com", Subject: "hi\r\nBcc: x@example.com"}},
+		{"invalid recipient", "a@example.com", Message{To: "b@example.com\r\nBcc: x@example.com"}},
+		{"empty recipient", "a@example.com", Message{}},
+		{"invalid sender", "no-reply", Message{To: "b@example.com"}},
+	}
+	for _, tt := range tests {
+		if _, err := Encode(tt.from, tt.msg, time.Now()); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("%s: got %v, want ErrInvalidMessage", tt.name, err)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(dir, "no-reply@example.com")
+	for range 2 {
+		if err := m.Send(context.Background(), Message{To: "dewi@example.com", Subject: "Hi", Text: "hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "To: <dewi@example.com>") {
+		t.Errorf("unexpected file content:\n%s", data)
+	}
+}
+
+// fakeSMTP adalah server SMTP minimal tanpa TLS dan AUTH yang mencatat satu transaksi.
+type fakeSMTP struct {
+	addr string
+	done chan transaction
+}
+
+type transaction struct {
+	from, to, data string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeSMTP{addr: ln.Addr().String(), done: make(chan transaction, 1)}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var tx transaction
+		tp.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 fake")
+			case "MAIL":
+				tx.from = arg
+				tp.PrintfLine("250 ok")
+			case "RCPT":
+				tx.to = arg
+				tp.PrintfLine("250 ok")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				data, _ := io.ReadAll(tp.DotReader())
+				tx.data = string(data)
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				s.done <- tx
+				return
+			default:
+				tp.PrintfLine("502 unsupported")
+			}
+		}
+	}()
+	return s
+}
+
+func TestSMTPMailer(t *testing.T) {
+	srv := newFakeSMTP(t)
+	host, port, _ := net.SplitHostPort(srv.addr)
+
+	m := NewSMTPMailer(host, port, "", "", "User Service <no-reply@example.com>")
+	m.Timeout = 5 * time.Second
+	err := m.Send(context.Background(), Message{To: "Dewi <dewi@example.com>", Subject: "Hi", Text: "hello\n.\nworld"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := <-srv.done
+	if tx.from != "FROM:<no-reply@example.com>" || tx.to != "TO:<dewi@example.com>" {
+		t.Errorf("envelope = %q -> %q", tx.from, tx.to)
+	}
+	// baris yang hanya berisi titik harus di-escape oleh client dan dikembalikan oleh DotReader
+	body := tx.data[strings.Index(tx.data, "\n\n")+2:]
+	if body != "hello\n.\nworld\n" {
+		t.Errorf("body = %q", body)
+	}
+}
@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer mengirim email lewat server SMTP. Port 465 memakai TLS sejak awal (SMTPS), port lain
+// memakai STARTTLS jika server mendukungnya. Username kosong berarti tanpa AUTH; net/smtp menolak
+// AUTH PLAIN tanpa TLS kecuali ke localhost, jadi password tidak pernah dikirim terbuka.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// Timeout untuk seluruh percakapan dengan server, default 30 detik
+	Timeout time.Duration
+	// TLSConfig opsional, misalnya untuk CA sendiri; ServerName default-nya Host
+	TLSConfig *tls.Config
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := Encode(m.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, err := envelope(m.From)
+	if err != nil {
+		return err
+	}
+	to, err := envelope(msg.To)
+	if err != nil {
+		return err
+	}
+
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(m.Host, m.Port))
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	if m.Port == "465" {
+		conn = tls.Client(conn, m.tlsConfig())
+	}
+
+	c, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+
+	if err := m.send(c, from, to, data); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return c.Quit()
+}
+
+func (m *SMTPMailer) send(c *smtp.Client, from, to string, data []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok && m.Port != "465" {
+		if err := c.StartTLS(m.tlsConfig()); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (m *SMTPMailer) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if m.TLSConfig != nil {
+		cfg = m.TLSConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = m.Host
+	}
+	return cfg
+}
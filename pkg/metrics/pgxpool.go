@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector membaca pgxpool.Stat setiap kali /metrics di-scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireWaitSeconds   *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	emptyAcquireWait     *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	newConnsCount        *prometheus.Desc
+}
+
+// NewPoolCollector membuat collector statistik pgxpool. Pemanggil yang mendaftarkannya ke Registry
+// dan melepasnya (Registry.Unregister) sebelum pool ditutup.
+func NewPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Number of currently acquired connections."),
+		idleConns:            desc("idle_conns", "Number of currently idle connections."),
+		totalConns:           desc("total_conns", "Total number of connections in the pool."),
+		maxConns:             desc("max_conns", "Maximum size of the pool."),
+		constructingConns:    desc("constructing_conns", "Number of connections being constructed."),
+		acquireCount:         desc("acquire_total", "Cumulative count of successful acquires."),
+		acquireWaitSeconds:   desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquireCount:    desc("empty_acquire_total", "Cumulative count of acquires that had to wait for a connection."),
+		emptyAcquireWait:     desc("empty_acquire_wait_seconds_total", "Total time spent waiting for a connection when the pool was empty."),
+		canceledAcquireCount: desc("canceled_acquire_total", "Cumulative count of acquires canceled by context."),
+		newConnsCount:        desc("new_conns_total", "Cumulative count of new connections opened."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.constructingConns
+	ch <- c.acquireCount
+	ch <- c.acquireWaitSeconds
+	ch <- c.emptyAcquireCount
+	ch <- c.emptyAcquireWait
+	ch <- c.canceledAcquireCount
+	ch <- c.newConnsCount
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(s.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWaitSeconds, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireWait, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.newConnsCount, prometheus.CounterValue, float64(s.NewConnsCount()))
+}
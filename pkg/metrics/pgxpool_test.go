@@ -0,0 +1,78 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"user-service/pkg/metrics"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestPoolCollector membaca statistik pool yang belum membuka koneksi, jadi semua nilai selain max_conns nol.
+func TestPoolCollector(t *testing.T) {
+	cfg, err := pgxpool.ParseConfig("postgres://app@127.0.0.1:1/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MaxConns = 7
+	pool, err := pgxpool.NewWithConfig(t.Context(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	want := `
+# HELP user_service_db_pool_acquire_duration_seconds_total Total time spent acquiring connections.
+# TYPE user_service_db_pool_acquire_duration_seconds_total counter
+user_service_db_pool_acquire_duration_seconds_total 0
+# HELP user_service_db_pool_acquire_total Cumulative count of successful acquires.
+# TYPE user_service_db_pool_acquire_total counter
+user_service_db_pool_acquire_total 0
+# HELP user_service_db_pool_acquired_conns Number of currently acquired connections.
+# TYPE user_service_db_pool_acquired_conns gauge
+user_service_db_pool_acquired_conns 0
+# HELP user_service_db_pool_canceled_acquire_total Cumulative count of acquires canceled by context.
+# TYPE user_service_db_pool_canceled_acquire_total counter
+user_service_db_pool_canceled_acquire_total 0
+# HELP user_service_db_pool_constructing_conns Number of connections being constructed.
+# TYPE user_service_db_pool_constructing_conns gauge
+user_service_db_pool_constructing_conns 0
+# HELP user_service_db_pool_empty_acquire_total Cumulative count of acquires that had to wait for a connection.
+# TYPE user_service_db_pool_empty_acquire_total counter
+user_service_db_pool_empty_acquire_total 0
+# HELP user_service_db_pool_empty_acquire_wait_seconds_total Total time spent waiting for a connection when the pool was empty.
+# TYPE user_service_db_pool_empty_acquire_wait_seconds_total counter
+user_service_db_pool_empty_acquire_wait_seconds_total 0
+# HELP user_service_db_pool_idle_conns Number of currently idle connections.
+# TYPE user_service_db_pool_idle_conns gauge
+user_service_db_pool_idle_conns 0
+# HELP user_service_db_pool_max_conns Maximum size of the pool.
+# TYPE user_service_db_pool_max_conns gauge
+user_service_db_pool_max_conns 7
+# HELP user_service_db_pool_new_conns_total Cumulative count of new connections opened.
+# TYPE user_service_db_pool_new_conns_total counter
+user_service_db_pool_new_conns_total 0
+# HELP user_service_db_pool_total_conns Total number of connections in the pool.
+# TYPE user_service_db_pool_total_conns gauge
+user_service_db_pool_total_conns 0
+`
+	collector := metrics.NewPoolCollector(pool)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	// collector bisa dilepas dan didaftarkan ulang untuk pool baru tanpa AlreadyRegistered
+	if err := metrics.Registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+	if !metrics.Registry.Unregister(collector) {
+		t.Error("Unregister did not find the pool collector")
+	}
+	next := metrics.NewPoolCollector(pool)
+	if err := metrics.Registry.Register(next); err != nil {
+		t.Fatalf("registering a collector for the next pool: %v", err)
+	}
+	metrics.Registry.Unregister(next)
+}
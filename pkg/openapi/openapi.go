@@ -0,0 +1,487 @@
+// Package openapi membangun dokumen OpenAPI 3.1 dari daftar operasi dan struct DTO.
+// Schema diturunkan lewat reflection dari tag `json`, `validate`, `query`, `default` dan `doc`,
+// jadi dokumen selalu mengikuti struct yang benar-benar dipakai handler.
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const Version = "3.1.0"
+
+// Document adalah root dokumen OpenAPI. Hanya bagian yang dipakai service ini yang dimodelkan.
+type Document struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       Info                                   `json:"info"`
+	Paths      map[string]map[string]*OperationObject `json:"paths"`
+	Components Components                             `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// OperationObject adalah satu operasi (method + path) di dokumen.
+type OperationObject struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema adalah subset JSON Schema 2020-12 yang dipakai OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Operation mendeskripsikan satu route. Query, Body dan Resp.Body diisi dengan nilai
+// struct (misalnya dto.CreateUserRequest{}); schema-nya diturunkan dari tag struct tersebut.
+type Operation struct {
+	Method      string
+	Path        string // pola chi, misalnya "/users/{id}"
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+	// PathParams berisi deskripsi dan schema untuk setiap {param} di Path
+	PathParams []Parameter
+	Query      any
+	Body       any
+	// BodyContentTypes default-nya application/json
+	BodyContentTypes []string
+	Responses        []Resp
+	// Security berisi nama security scheme yang wajib, misalnya "adminToken"
+	Security []string
+}
+
+// Spec membangun Document secara bertahap.
+type Spec struct {
+	doc   Document
+	names map[reflect.Type]string
+}
+
+func New(info Info) *Spec {
+	return &Spec{
+		doc: Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]map[string]*OperationObject{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+			},
+		},
+		names: map[reflect.Type]string{},
+	}
+}
+
+// AddSecurityScheme mendaftarkan security scheme yang bisa dirujuk lewat Operation.Security.
+func (s *Spec) AddSecurityScheme(name string, scheme SecurityScheme) {
+	if s.doc.Components.SecuritySchemes == nil {
+		s.doc.Components.SecuritySchemes = map[string]*SecurityScheme{}
+	}
+	s.doc.Components.SecuritySchemes[name] = &scheme
+}
+
+// Add menambahkan satu operasi ke dokumen.
+func (s *Spec) Add(op Operation) {
+	item := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Responses:   map[string]*Response{},
+	}
+
+	for _, p := range op.PathParams {
+		p.In = "path"
+		p.Required = true
+		item.Parameters = append(item.Parameters, &p)
+	}
+	if op.Query != nil {
+		item.Parameters = append(item.Parameters, s.queryParams(reflect.TypeOf(op.Query))...)
+	}
+
+	if op.Body != nil {
+		types := op.BodyContentTypes
+		if len(types) == 0 {
+			types = []string{"application/json"}
+		}
+		schema := s.SchemaOf(reflect.TypeOf(op.Body))
+		item.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{}}
+		for _, ct := range types {
+			item.RequestBody.Content[ct] = &MediaType{Schema: schema}
+		}
+	}
+
+	for _, r := range op.Responses {
+		resp := &Response{Description: r.Description}
+		if r.Description == "" {
+			resp.Description = http.StatusText(r.Status)
+		}
+		if r.Body != nil {
+			ct := r.ContentType
+			if ct == "" {
+				ct = "application/json"
+			}
+			resp.Content = map[string]*MediaType{ct: {Schema: s.SchemaOf(reflect.TypeOf(r.Body))}}
+		}
+		for ct, body := range r.Alternatives {
+			if resp.Content == nil {
+				resp.Content = map[string]*MediaType{}
+			}
+			resp.Content[ct] = &MediaType{Schema: s.SchemaOf(reflect.TypeOf(body))}
+		}
+		item.Responses[strconv.Itoa(r.Status)] = resp
+	}
+
+	for _, name := range op.Security {
+		item.Security = append(item.Security, map[string][]string{name: {}})
+	}
+
+	method := strings.ToLower(op.Method)
+	if s.doc.Paths[op.Path] == nil {
+		s.doc.Paths[op.Path] = map[string]*OperationObject{}
+	}
+	s.doc.Paths[op.Path][method] = item
+}
+
+// Resp adalah satu kemungkinan response dari sebuah operasi. Body nil berarti tanpa body.
+type Resp struct {
+	Status      int
+	Description string
+	ContentType string
+	Body        any
+	// Alternatives berisi content type lain untuk response yang sama, dipilih client lewat Accept
+	Alternatives map[string]any
+}
+
+// Document mengembalikan dokumen yang sudah dibangun.
+func (s *Spec) Document() *Document {
+	return &s.doc
+}
+
+// Route adalah pasangan method dan path yang terdokumentasi.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Routes mengembalikan semua operasi yang terdokumentasi, terurut, dengan method huruf besar.
+func (s *Spec) Routes() []Route {
+	var routes []Route
+	for path, methods := range s.doc.Paths {
+		for method := range methods {
+			routes = append(routes, Route{Method: strings.ToUpper(method), Path: path})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Handler menyajikan dokumen sebagai JSON.
+func (s *Spec) Handler() http.Handler {
+	body, err := json.MarshalIndent(s.doc, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	fileType = reflect.TypeOf(multipart.FileHeader{})
+)
+
+// SchemaOf mengembalikan schema untuk t. Struct bernama didaftarkan di components.schemas
+// dan dirujuk lewat $ref.
+func (s *Spec) SchemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case fileType:
+		// file di multipart/form-data
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.SchemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.SchemaOf(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		name, ok := s.names[t]
+		if !ok {
+			// tipe khusus dokumentasi boleh unexported, nama component tetap diawali huruf besar
+			name = strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+			s.names[t] = name
+			// daftarkan dulu sebelum membangun properties, supaya struct rekursif tidak loop
+			s.doc.Components.Schemas[name] = &Schema{}
+			*s.doc.Components.Schemas[name] = *s.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (s *Spec) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := s.fieldSchema(f)
+		if applyValidate(prop, f.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = prop
+	}
+	return schema
+}
+
+func (s *Spec) queryParams(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Tag.Get("query")
+		if name == "" {
+			name, _, _ = strings.Cut(f.Tag.Get("json"), ",")
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		schema := s.fieldSchema(f)
+		required := applyValidate(schema, f.Tag.Get("validate"))
+		params = append(params, &Parameter{
+			Name:        name,
+			In:          "query",
+			Required:    required,
+			Description: schema.Description,
+			Schema:      schema,
+		})
+		schema.Description = ""
+	}
+	return params
+}
+
+// fieldSchema membangun schema field beserta tag `doc` dan `default`. Schema $ref
+// dibungkus agar keyword lain tidak menimpa schema yang dirujuk.
+func (s *Spec) fieldSchema(f reflect.StructField) *Schema {
+	schema := s.SchemaOf(f.Type)
+	if schema.Ref != "" {
+		return schema
+	}
+	schema.Description = f.Tag.Get("doc")
+	if def, ok := f.Tag.Lookup("default"); ok {
+		schema.Default = typedValue(schema.Type, def)
+	}
+	return schema
+}
+
+// applyValidate menerjemahkan tag validator ke keyword JSON Schema dan mengembalikan
+// true jika field wajib diisi. Tag yang tidak punya padanan diabaikan.
+func applyValidate(schema *Schema, tag string) (required bool) {
+	if tag == "" || schema.Ref != "" {
+		return strings.Contains(","+tag+",", ",required,")
+	}
+	numeric := schema.Type == "integer" || schema.Type == "number"
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url", "http_url", "public_url":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "number":
+			if !numeric {
+				schema.Pattern = "^[0-9]+$"
+			}
+		case "phone":
+			// nomor nasional juga diterima, jadi pattern hanya membatasi karakternya
+			schema.Pattern = `^\+?[0-9 ().-]+$`
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, typedValue(schema.Type, v))
+			}
+		case "gte", "min":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				if numeric {
+					schema.Minimum = &n
+				} else {
+					schema.MinLength = intPtr(int(n))
+				}
+			}
+		case "lte", "max":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				if numeric {
+					schema.Maximum = &n
+				} else {
+					schema.MaxLength = intPtr(int(n))
+				}
+			}
+		case "gt":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				if numeric {
+					schema.ExclusiveMinimum = &n
+				} else {
+					schema.MinLength = intPtr(int(n) + 1)
+				}
+			}
+		case "lt":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				if numeric {
+					schema.ExclusiveMaximum = &n
+				} else {
+					schema.MaxLength = intPtr(int(n) - 1)
+				}
+			}
+		case "len":
+			if n, err := strconv.Atoi(param); err == nil && !numeric {
+				schema.MinLength, schema.MaxLength = intPtr(n), intPtr(n)
+			}
+		}
+	}
+	return required
+}
+
+// typedValue mengubah nilai tag menjadi tipe JSON yang sesuai dengan schema.
+func typedValue(schemaType, v string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+func intPtr(n int) *int { return &n }
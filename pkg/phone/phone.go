@@ -0,0 +1,196 @@
+// Package phone mem-parse nomor telepon dan menormalkannya ke format E.164 (+<kode negara><nomor>).
+//
+// Ini bukan pengganti libphonenumber: kode negara dikenali dari seluruh tabel ITU, tetapi panjang nomor
+// hanya dicek per negara untuk region di tabel regions; negara lain cukup memenuhi batas 15 digit E.164.
+package phone
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrInvalid dikembalikan untuk input yang bukan nomor telepon (huruf, terlalu pendek atau terlalu panjang).
+	ErrInvalid = errors.New("phone number is not valid")
+	// ErrNoRegion dikembalikan untuk nomor nasional (tanpa +) jika tidak ada region default.
+	ErrNoRegion = errors.New("phone number must start with + and the country code")
+	// ErrUnknownCountry dikembalikan untuk kode negara yang tidak terdaftar.
+	ErrUnknownCountry = errors.New("phone number has an unknown country code")
+)
+
+// region adalah aturan nomor satu negara. min dan max adalah panjang nomor nasional tanpa trunk prefix.
+type region struct {
+	code  string
+	trunk string
+	min   int
+	max   int
+	// valid memeriksa aturan tambahan pada nomor nasional, boleh nil
+	valid func(national string) bool
+}
+
+// regions berisi negara yang panjang nomornya dicek, dengan kode region ISO 3166-1.
+var regions = map[string]region{
+	"ID": {code: "62", trunk: "0", min: 7, max: 12},
+	"MY": {code: "60", trunk: "0", min: 7, max: 10},
+	"SG": {code: "65", min: 8, max: 8},
+	"PH": {code: "63", trunk: "0", min: 8, max: 10},
+	"TH": {code: "66", trunk: "0", min: 8, max: 9},
+	"VN": {code: "84", trunk: "0", min: 9, max: 10},
+	"AU": {code: "61", trunk: "0", min: 9, max: 9},
+	"IN": {code: "91", trunk: "0", min: 10, max: 10},
+	"JP": {code: "81", trunk: "0", min: 9, max: 10},
+	"NL": {code: "31", trunk: "0", min: 9, max: 9},
+	"DE": {code: "49", trunk: "0", min: 6, max: 13},
+	"GB": {code: "44", trunk: "0", min: 7, max: 10},
+	// NANP: kode area dan kode exchange tidak boleh diawali 0 atau 1
+	"US": {code: "1", trunk: "1", min: 10, max: 10, valid: nanp},
+	"CA": {code: "1", trunk: "1", min: 10, max: 10, valid: nanp},
+}
+
+// byCode memetakan kode negara ke aturan panjang nomornya. Region yang berbagi kode (US dan CA)
+// harus punya aturan yang sama.
+var byCode = func() map[string]region {
+	m := map[string]region{}
+	for _, r := range regions {
+		m[r.code] = r
+	}
+	return m
+}()
+
+// Regions mengembalikan kode region yang bisa dipakai sebagai region default, berurutan.
+func Regions() []string {
+	names := make([]string, 0, len(regions))
+	for name := range regions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsRegion bernilai true jika name ada di Regions.
+func IsRegion(name string) bool {
+	_, ok := regions[name]
+	return ok
+}
+
+// Parse menormalkan raw ke E.164. Spasi, tanda hubung, titik dan kurung diabaikan. Nomor yang diawali +
+// atau 00 dibaca sebagai nomor internasional; selain itu sebagai nomor nasional defaultRegion, dengan trunk
+// prefix (misalnya 0 di Indonesia) dibuang. defaultRegion kosong berarti hanya nomor internasional diterima.
+func Parse(raw, defaultRegion string) (string, error) {
+	digits, international, err := clean(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if international {
+		code, national, err := splitCode(digits)
+		if err != nil {
+			return "", err
+		}
+		if r, ok := byCode[code]; ok {
+			// kesalahan umum: trunk prefix ikut ditulis, misalnya +62 0812...
+			if r.trunk == "0" && strings.HasPrefix(national, "0") {
+				national = national[1:]
+			}
+			if !r.accepts(national) {
+				return "", ErrInvalid
+			}
+		}
+		if len(code)+len(national) > 15 || len(national) < 4 {
+			return "", ErrInvalid
+		}
+		return "+" + code + national, nil
+	}
+
+	if defaultRegion == "" {
+		return "", ErrNoRegion
+	}
+	r, ok := regions[defaultRegion]
+	if !ok {
+		return "", ErrUnknownCountry
+	}
+	national := digits
+	if r.trunk != "" && strings.HasPrefix(national, r.trunk) {
+		national = national[len(r.trunk):]
+	}
+	if !r.accepts(national) {
+		return "", ErrInvalid
+	}
+	return "+" + r.code + national, nil
+}
+
+// clean membuang pemisah yang umum dan mengembalikan digit saja, serta apakah nomor ditulis internasional.
+func clean(raw string) (string, bool, error) {
+	s := strings.TrimSpace(raw)
+	international := false
+	if rest, ok := strings.CutPrefix(s, "+"); ok {
+		s, international = rest, true
+	}
+
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return "", false, ErrInvalid
+		}
+	}
+	digits := b.String()
+	if !international {
+		if rest, ok := strings.CutPrefix(digits, "00"); ok {
+			digits, international = rest, true
+		}
+	}
+	if digits == "" {
+		return "", false, ErrInvalid
+	}
+	return digits, international, nil
+}
+
+// splitCode memisahkan kode negara dari digits. Kode negara ITU bersifat prefix-free, jadi cukup dicoba
+// dari yang terpendek.
+func splitCode(digits string) (string, string, error) {
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		if countryCodes[digits[:n]] {
+			return digits[:n], digits[n:], nil
+		}
+	}
+	return "", "", ErrUnknownCountry
+}
+
+func (r region) accepts(national string) bool {
+	if len(national) < r.min || len(national) > r.max {
+		return false
+	}
+	return r.valid == nil || r.valid(national)
+}
+
+func nanp(national string) bool {
+	return national[0] >= '2' && national[3] >= '2'
+}
+
+// countryCodes adalah kode negara yang terdaftar di ITU-T E.164 (termasuk kode layanan global seperti 800 dan 882).
+var countryCodes = func() map[string]bool {
+	m := map[string]bool{}
+	for _, code := range strings.Fields(`
+		1 7
+		20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49 51 52 53 54 55 56 57 58
+		60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98
+		211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234 235 236 237 238 239
+		240 241 242 243 244 245 246 247 248 249 250 251 252 253 254 255 256 257 258 260 261 262 263 264 265
+		266 267 268 269 290 291 297 298 299
+		350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 379 380 381 382 383 385
+		386 387 389
+		420 421 423
+		500 501 502 503 504 505 506 507 508 509 590 591 592 593 594 595 596 597 598 599
+		670 672 673 674 675 676 677 678 679 680 681 682 683 685 686 687 688 689 690 691 692
+		800 808 850 852 853 855 856 870 878 880 881 882 883 886 888
+		960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 979 992 993 994 995 996 998
+	`) {
+		m[code] = true
+	}
+	return m
+}()
@@ -0,0 +1,56 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   string
+		err    error
+	}{
+		{"+62 812-3456-7890", "", "+6281234567890", nil},
+		{"0812 3456 7890", "ID", "+6281234567890", nil},
+		{"(021) 555-1234", "ID", "+62215551234", nil},
+		{"+62 0812 3456 7890", "ID", "+6281234567890", nil},
+		{"006281234567890", "", "+6281234567890", nil},
+		{"+1 (415) 555-2671", "ID", "+14155552671", nil},
+		{"415.555.2671", "US", "+14155552671", nil},
+		{"1 415 555 2671", "US", "+14155552671", nil},
+		{"+44 20 7946 0958", "", "+442079460958", nil},
+		// negara tanpa aturan panjang hanya dicek batas E.164
+		{"+385 91 234 5678", "", "+385912345678", nil},
+
+		{"0812 3456 7890", "", "", ErrNoRegion},
+		{"+999 1234 5678", "", "", ErrUnknownCountry},
+		{"0812", "ID", "", ErrInvalid},
+		{"0812 3456 7890 1234", "ID", "", ErrInvalid},
+		{"+1 015 555 2671", "", "", ErrInvalid},
+		{"+62 812 abc", "", "", ErrInvalid},
+		{"+", "", "", ErrInvalid},
+		{"", "ID", "", ErrInvalid},
+		{"+385 1234 5678 9012 34", "", "", ErrInvalid},
+		{"0812 3456 7890", "ZZ", "", ErrUnknownCountry},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.raw, tt.region)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q, %q) = %q, %v; want %q, %v", tt.raw, tt.region, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestRegionsShareCodeRules(t *testing.T) {
+	for _, name := range Regions() {
+		r := regions[name]
+		if b := byCode[r.code]; b.min != r.min || b.max != r.max || b.trunk != r.trunk {
+			t.Errorf("region %s does not match the other regions with code %s", name, r.code)
+		}
+		if !countryCodes[r.code] {
+			t.Errorf("region %s: code %s is not in countryCodes", name, r.code)
+		}
+	}
+}
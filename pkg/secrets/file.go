@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileProvider membaca secret dari file <Dir>/<name>, cocok untuk Docker secrets
+// (/run/secrets) atau Kubernetes secret yang di-mount sebagai volume.
+type FileProvider struct {
+	Dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{Dir: dir}
+}
+
+func (p *FileProvider) GetSecret(_ context.Context, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", ErrNotFound
+	}
+	return ReadFile(filepath.Join(p.Dir, name))
+}
+
+// ReadFile membaca secret dari file dan membuang newline di akhir.
+func ReadFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileProvider(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "secrets")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "db_password"), "s3cret\r\n")
+	// file di luar Dir tidak boleh terbaca lewat nama secret
+	writeFile(t, filepath.Join(root, "outside"), "leaked")
+
+	p := NewFileProvider(dir)
+	value, err := p.GetSecret(context.Background(), "db_password")
+	if err != nil || value != "s3cret" {
+		t.Fatalf("GetSecret = %q, %v; want s3cret, nil", value, err)
+	}
+
+	for _, name := range []string{"", ".", "..", "../outside", `..\outside`, "sub/db_password", "/etc/passwd", "missing"} {
+		if value, err := p.GetSecret(context.Background(), name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetSecret(%q) = %q, %v; want ErrNotFound", name, value, err)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
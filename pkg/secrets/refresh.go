@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Refreshing menyimpan nilai secret terakhir dan memperbaruinya secara berkala,
+// sehingga credential yang di-rotate ikut terpakai tanpa restart.
+type Refreshing struct {
+	provider Provider
+	name     string
+
+	mu    sync.RWMutex
+	value string
+
+	// OnError dipanggil jika refresh gagal; nilai lama tetap dipakai.
+	OnError func(err error)
+	// OnChange dipanggil setelah nilai berubah.
+	OnChange func()
+}
+
+func NewRefreshing(provider Provider, name, initial string) *Refreshing {
+	return &Refreshing{provider: provider, name: name, value: initial}
+}
+
+// Get mengembalikan nilai secret terbaru.
+func (r *Refreshing) Get() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.value
+}
+
+// Refresh mengambil ulang nilai dari provider.
+func (r *Refreshing) Refresh(ctx context.Context) error {
+	value, err := r.provider.GetSecret(ctx, r.name)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	changed := value != r.value
+	r.value = value
+	r.mu.Unlock()
+
+	if changed && r.OnChange != nil {
+		r.OnChange()
+	}
+	return nil
+}
+
+// Start menjalankan Refresh setiap interval sampai ctx selesai.
+func (r *Refreshing) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refreshCtx, cancel := context.WithTimeout(ctx, interval)
+				err := r.Refresh(refreshCtx)
+				cancel()
+				if err != nil && !errors.Is(err, context.Canceled) && r.OnError != nil {
+					r.OnError(err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,111 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider mengembalikan value dan err yang di-set test, dan menghitung pemanggilan GetSecret.
+type fakeProvider struct {
+	mu    sync.Mutex
+	value string
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) GetSecret(_ context.Context, name string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if name != "db_password" {
+		return "", ErrNotFound
+	}
+	return p.value, p.err
+}
+
+func (p *fakeProvider) set(value string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.value, p.err = value, err
+}
+
+func (p *fakeProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func TestRefresh(t *testing.T) {
+	provider := &fakeProvider{value: "old"}
+	r := NewRefreshing(provider, "db_password", "old")
+	changes := 0
+	r.OnChange = func() { changes++ }
+	ctx := context.Background()
+
+	// nilai sama tidak memanggil OnChange
+	if err := r.Refresh(ctx); err != nil || r.Get() != "old" || changes != 0 {
+		t.Errorf("Refresh without a change: err %v, value %q, %d changes", err, r.Get(), changes)
+	}
+
+	provider.set("new", nil)
+	if err := r.Refresh(ctx); err != nil || r.Get() != "new" || changes != 1 {
+		t.Errorf("Refresh after rotation: err %v, value %q, %d changes", err, r.Get(), changes)
+	}
+
+	// nilai lama tetap dipakai jika provider gagal
+	boom := errors.New("vault unavailable")
+	provider.set("", boom)
+	if err := r.Refresh(ctx); !errors.Is(err, boom) || r.Get() != "new" || changes != 1 {
+		t.Errorf("failed Refresh: err %v, value %q, %d changes", err, r.Get(), changes)
+	}
+}
+
+func TestStartStopsWithContext(t *testing.T) {
+	provider := &fakeProvider{value: "old"}
+	r := NewRefreshing(provider, "db_password", "old")
+	changed := make(chan struct{}, 1)
+	r.OnChange = func() { changed <- struct{}{} }
+	errs := make(chan error, 1)
+	r.OnError = func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Start(ctx, 5*time.Millisecond)
+
+	provider.set("new", nil)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not pick up the rotated value")
+	}
+	if r.Get() != "new" {
+		t.Errorf("Get = %q, want new", r.Get())
+	}
+
+	boom := errors.New("vault unavailable")
+	provider.set("", boom)
+	select {
+	case err := <-errs:
+		if !errors.Is(err, boom) {
+			t.Errorf("OnError got %v, want %v", err, boom)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnError was not called")
+	}
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	before := provider.count()
+	time.Sleep(50 * time.Millisecond)
+	if after := provider.count(); after != before {
+		t.Errorf("provider called %d more times after ctx was cancelled", after-before)
+	}
+}
@@ -0,0 +1,14 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound dikembalikan provider jika secret dengan nama tersebut tidak ada.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider mengambil nilai secret berdasarkan nama, misalnya "db_password".
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
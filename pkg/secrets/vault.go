@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultProvider membaca secret dari HashiCorp Vault KV v2 (atau server lain dengan API yang sama):
+// GET {Addr}/v1/{Mount}/data/{Path} dan mengambil field `name` dari data.data.
+type VaultProvider struct {
+	Addr   string
+	Token  string
+	Mount  string
+	Path   string
+	Client *http.Client
+}
+
+func NewVaultProvider(addr, token, mount, path string) *VaultProvider {
+	return &VaultProvider{
+		Addr:   strings.TrimRight(addr, "/"),
+		Token:  token,
+		Mount:  strings.Trim(mount, "/"),
+		Path:   strings.Trim(path, "/"),
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+func (p *VaultProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", p.Addr, url.PathEscape(p.Mount), p.escapedPath())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+
+	var kv vaultKVResponse
+	if err := json.Unmarshal(body, &kv); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("vault: invalid response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("vault: %s: %s", resp.Status, strings.Join(kv.Errors, "; "))
+	}
+
+	value, ok := kv.Data.Data[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault: secret %q is not a string", name)
+	}
+	return s, nil
+}
+
+func (p *VaultProvider) escapedPath() string {
+	parts := strings.Split(p.Path, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVaultProvider(t *testing.T) {
+	var gotPath, gotToken string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotToken = r.URL.EscapedPath(), r.Header.Get("X-Vault-Token")
+		switch r.Header.Get("X-Vault-Token") {
+		case "denied":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		case "broken":
+			w.Write([]byte(`<html>`))
+		default:
+			w.Write([]byte(`{"data":{"data":{"db_password":"s3cret","port":5432},"metadata":{"version":3}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewVaultProvider(srv.URL+"/", "root", "/secret/", "/user service/prod/")
+	value, err := p.GetSecret(context.Background(), "db_password")
+	if err != nil || value != "s3cret" {
+		t.Fatalf("GetSecret = %q, %v; want s3cret, nil", value, err)
+	}
+	if gotPath != "/v1/secret/data/user%20service/prod" || gotToken != "root" {
+		t.Errorf("request path %q, token %q", gotPath, gotToken)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+		// want: ErrNotFound, atau potongan pesan error jika bukan
+		want error
+		msg  string
+	}{
+		{"missing key", "root", "api_key", ErrNotFound, ""},
+		{"not a string", "root", "port", nil, `secret "port" is not a string`},
+		{"not found", "missing", "db_password", ErrNotFound, ""},
+		{"forbidden", "denied", "db_password", nil, "403 Forbidden: permission denied"},
+		{"invalid body", "broken", "db_password", nil, "invalid response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewVaultProvider(srv.URL, tt.token, "secret", "app")
+			value, err := p.GetSecret(context.Background(), tt.secret)
+			switch {
+			case tt.want != nil && !errors.Is(err, tt.want):
+				t.Errorf("GetSecret = %q, %v; want %v", value, err, tt.want)
+			case tt.want == nil && (err == nil || errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), tt.msg)):
+				t.Errorf("GetSecret = %q, %v; want an error containing %q", value, err, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSender tidak mengirim apa pun, hanya menulis SMS ke log. Hanya untuk development:
+// kode OTP ikut tercatat di log.
+type LogSender struct {
+	Log logrus.FieldLogger
+}
+
+func NewLogSender(log logrus.FieldLogger) *LogSender {
+	return &LogSender{Log: log}
+}
+
+func (s *LogSender) Send(_ context.Context, msg Message) error {
+	if err := Check(msg); err != nil {
+		return err
+	}
+	s.Log.WithField("to", msg.To).Info("sms (not sent): " + msg.Text)
+	return nil
+}
@@ -0,0 +1,37 @@
+// Package sms mengirim pesan teks singkat, misalnya kode OTP verifikasi nomor telepon.
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrInvalidMessage dikembalikan jika nomor tujuan bukan E.164 atau isi pesan kosong.
+var ErrInvalidMessage = errors.New("invalid sms message")
+
+// Message adalah satu SMS. To harus sudah dinormalkan ke E.164, lihat phone.Parse.
+type Message struct {
+	To   string
+	Text string
+}
+
+// Sender mengirim SMS. Implementasi provider (Twilio, Vonage, gateway operator, ...) cukup memenuhi
+// interface ini; LogSender dipakai untuk development.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{3,14}$`)
+
+// Check memeriksa msg sebelum dikirim, dipakai oleh setiap Sender.
+func Check(msg Message) error {
+	if !e164.MatchString(msg.To) {
+		return fmt.Errorf("%w: to %q is not an E.164 number", ErrInvalidMessage, msg.To)
+	}
+	if msg.Text == "" {
+		return fmt.Errorf("%w: empty text", ErrInvalidMessage)
+	}
+	return nil
+}
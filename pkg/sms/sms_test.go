@@ -0,0 +1,34 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLogSender(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	s := NewLogSender(log)
+
+	if err := s.Send(context.Background(), Message{To: "+6281234567890", Text: "Your code is 123456"}); err != nil {
+		t.Fatal(err)
+	}
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != logrus.InfoLevel || entry.Data["to"] != "+6281234567890" ||
+		entry.Message != "sms (not sent): Your code is 123456" {
+		t.Errorf("log entry = %+v", entry)
+	}
+
+	for _, msg := range []Message{
+		{To: "081234567890", Text: "x"},
+		{To: "+62 812 3456", Text: "x"},
+		{To: "+6281234567890"},
+	} {
+		if err := s.Send(context.Background(), msg); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("Send(%+v) = %v, want ErrInvalidMessage", msg, err)
+		}
+	}
+}
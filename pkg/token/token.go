@@ -0,0 +1,106 @@
+package token
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const Issuer = "user-service"
+
+// Claims adalah isi access token yang dikeluarkan service ini.
+type Claims struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	// SessionVersion adalah users.session_version saat token dibuat. Token dengan versi lebih lama sudah dicabut,
+	// misalnya karena password di-reset.
+	SessionVersion int32 `json:"sv"`
+	jwt.RegisteredClaims
+}
+
+// Signer menandatangani token dengan private key RSA (RS256).
+type Signer struct {
+	key *rsa.PrivateKey
+}
+
+// NewSignerFromFile membaca private key PEM dari path, misalnya JWT_PRIVATE_KEY_PATH.
+func NewSignerFromFile(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key %s: %w", path, err)
+	}
+	return &Signer{key: key}, nil
+}
+
+// Issue membuat access token untuk user dengan masa berlaku ttl.
+func (s *Signer) Issue(userID uuid.UUID, email, role string, sessionVersion int32, ttl time.Duration) (string, Claims, error) {
+	now := time.Now()
+	claims := Claims{
+		Email:          email,
+		Role:           role,
+		SessionVersion: sessionVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    Issuer,
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(s.key)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	return signed, claims, nil
+}
+
+// ErrInvalid dikembalikan Verify untuk token yang rusak, tanda tangannya salah, bukan dari Issuer ini,
+// atau sudah kedaluwarsa.
+var ErrInvalid = errors.New("token is invalid or has expired")
+
+// Verifier memeriksa token dengan public key RSA pasangan Signer.
+type Verifier struct {
+	key *rsa.PublicKey
+}
+
+// NewVerifierFromFile membaca public key PEM dari path, misalnya JWT_PUBLIC_KEY_PATH.
+func NewVerifierFromFile(path string) (*Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key %s: %w", path, err)
+	}
+	return &Verifier{key: key}, nil
+}
+
+// Verify memeriksa tanda tangan dan masa berlaku signed lalu mengembalikan claims-nya. SessionVersion tidak
+// dicek di sini karena butuh database.
+func (v *Verifier) Verify(signed string) (Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(signed, &claims, func(*jwt.Token) (any, error) { return v.key, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if _, err := uuid.Parse(claims.Subject); err != nil {
+		return Claims{}, fmt.Errorf("%w: subject is not a user id", ErrInvalid)
+	}
+	return claims, nil
+}
@@ -0,0 +1,98 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestIssueAndVerify(t *testing.T) {
+	signer, verifier := newKeyPair(t)
+	userID := uuid.New()
+
+	signed, issued, err := signer.Issue(userID, "budi@example.com", "user", 3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.Verify(signed)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != userID.String() || claims.Email != "budi@example.com" || claims.Role != "user" ||
+		claims.SessionVersion != 3 || claims.ID != issued.ID {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	expired, _, err := signer.Issue(userID, "budi@example.com", "user", 3, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(expired); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify of an expired token: got %v, want ErrInvalid", err)
+	}
+
+	// token dari key lain
+	other, _ := newKeyPair(t)
+	foreign, _, err := other.Issue(userID, "budi@example.com", "user", 3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(foreign); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify of a token signed by another key: got %v, want ErrInvalid", err)
+	}
+
+	// algoritma lain ditolak walaupun tanda tangannya benar
+	hs, err := jwt.NewWithClaims(jwt.SigningMethodHS256, issued).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(hs); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify of an HS256 token: got %v, want ErrInvalid", err)
+	}
+	if _, err := verifier.Verify("not-a-token"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify of garbage: got %v, want ErrInvalid", err)
+	}
+}
+
+func newKeyPair(t *testing.T) (*Signer, *Verifier) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private.pem")
+	publicPath := filepath.Join(dir, "public.pem")
+	writePEM(t, privatePath, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	writePEM(t, publicPath, "PUBLIC KEY", pub)
+
+	signer, err := NewSignerFromFile(privatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewVerifierFromFile(publicPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, verifier
+}
+
+func writePEM(t *testing.T, path, kind string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"user-service/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "user-service"
+
+type options struct {
+	exporter sdktrace.SpanExporter
+}
+
+type Option func(*options)
+
+// WithExporter mengganti exporter dari config, misalnya tracetest.NewInMemoryExporter() di test.
+// Span dikirim secara sinkron sehingga langsung terlihat tanpa menunggu batch.
+func WithExporter(exp sdktrace.SpanExporter) Option {
+	return func(o *options) {
+		o.exporter = exp
+	}
+}
+
+// Init memasang TracerProvider global dan propagator W3C traceparent.
+// Fungsi shutdown yang dikembalikan harus dipanggil sebelum aplikasi berhenti agar span ter-flush.
+func Init(ctx context.Context, cfg *config.AppConfig, opts ...Option) (func(context.Context) error, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var processor sdktrace.SpanProcessor
+	if o.exporter != nil {
+		processor = sdktrace.NewSimpleSpanProcessor(o.exporter)
+	} else {
+		exp, err := newExporter(ctx, cfg.Tracing)
+		if err != nil {
+			return nil, err
+		}
+		if exp == nil {
+			return func(context.Context) error { return nil }, nil
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exp)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.Tracing.ServiceName),
+		attribute.String("deployment.environment", cfg.AppEnv),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		if strings.Contains(cfg.Endpoint, "://") {
+			return otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		return otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(cfg.Endpoint), otlptracehttp.WithInsecure())
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case "none", "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// Tracer mengembalikan tracer milik service dari TracerProvider global.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start membuka span baru sebagai child dari span di ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// RecordError menandai span sebagai error.
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End menutup span dan menandainya error jika err != nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		RecordError(span, err)
+	}
+	span.End()
+}
@@ -0,0 +1,137 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-service/config"
+	"user-service/constants"
+	"user-service/db/memstore"
+	db "user-service/db/sqlc"
+	appmiddleware "user-service/middleware"
+	"user-service/pkg/tracing"
+	"user-service/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedStore menjalankan GetUserByID lewat query tracer pgx, seperti pool Postgres dari db.NewPoolConfig.
+type tracedStore struct {
+	*memstore.Store
+}
+
+func (s tracedStore) GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error) {
+	tracer := db.NewQueryTracer()
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+		SQL: "-- name: GetUserByID :one\nSELECT * FROM users WHERE id = $1 AND deleted_at IS NULL",
+	})
+	user, err := s.Store.GetUserByID(ctx, id)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: err})
+	return user, err
+}
+
+// TestRequestSpans memastikan satu request menghasilkan span HTTP bernama route chi dengan span service
+// dan span query di bawahnya, dan traceparent dari client dipakai sebagai parent.
+func TestRequestSpans(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	shutdown, err := tracing.Init(t.Context(), &config.AppConfig{
+		AppEnv:  "test",
+		Tracing: config.TracingConfig{ServiceName: "user-service", SampleRatio: 1},
+	}, tracing.WithExporter(exp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { shutdown(context.Background()) })
+
+	store := memstore.New()
+	user, err := store.CreateUser(t.Context(), db.CreateUserParams{Email: "budi@example.com", Role: constants.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := service.NewUserService(tracedStore{store}, service.UserOptions{})
+
+	r := chi.NewRouter()
+	r.Use(appmiddleware.Tracing)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := users.GetUserByID(r.Context(), uuid.MustParse(chi.URLParam(r, "id"))); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	r.Get("/metrics", func(http.ResponseWriter, *http.Request) {})
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/users/"+user.ID.String(), nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	// /metrics tidak di-trace
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exp.GetSpans() {
+		spans[s.Name] = s
+	}
+	if len(spans) != 3 {
+		names := make([]string, 0, len(spans))
+		for name := range spans {
+			names = append(names, name)
+		}
+		t.Fatalf("got spans %q, want GET /users/{id}, UserService.GetUserByID and db.GetUserByID", names)
+	}
+
+	server, ok := spans["GET /users/{id}"]
+	if !ok {
+		t.Fatal("no span named after the route")
+	}
+	if server.SpanKind != trace.SpanKindServer || !hasAttribute(server.Attributes, attribute.String("http.route", "/users/{id}")) {
+		t.Errorf("server span: kind %s, attributes %v", server.SpanKind, server.Attributes)
+	}
+	if got := server.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("server span trace id = %s, want %s from traceparent", got, traceID)
+	}
+	if !server.Parent.IsRemote() || server.Parent.SpanID().String() != spanID {
+		t.Errorf("server span parent = %s (remote %t), want %s from traceparent", server.Parent.SpanID(), server.Parent.IsRemote(), spanID)
+	}
+
+	svc, ok := spans["UserService.GetUserByID"]
+	if !ok {
+		t.Fatal("no service span")
+	}
+	if svc.Parent.SpanID() != server.SpanContext.SpanID() || svc.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Errorf("service span parent = %s, want the server span %s", svc.Parent.SpanID(), server.SpanContext.SpanID())
+	}
+
+	query, ok := spans["db.GetUserByID"]
+	if !ok {
+		t.Fatal("no db span")
+	}
+	if query.Parent.SpanID() != svc.SpanContext.SpanID() || query.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Errorf("db span parent = %s, want the service span %s", query.Parent.SpanID(), svc.SpanContext.SpanID())
+	}
+	if query.SpanKind != trace.SpanKindClient || !hasAttribute(query.Attributes, attribute.String("db.operation.name", "GetUserByID")) {
+		t.Errorf("db span: kind %s, attributes %v", query.SpanKind, query.Attributes)
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
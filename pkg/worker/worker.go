@@ -0,0 +1,76 @@
+// Package worker menjalankan pekerjaan background di luar request dengan jumlah goroutine terbatas,
+// dan bisa ditunggu sampai selesai saat server berhenti.
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Pool menjalankan paling banyak size pekerjaan sekaligus. Pekerjaan yang datang saat pool penuh atau sudah
+// di-Shutdown dibuang dan dicatat di log, supaya lonjakan request tidak membuat goroutine tanpa batas.
+type Pool struct {
+	sem chan struct{}
+	log logrus.FieldLogger
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func New(size int, log logrus.FieldLogger) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	return &Pool{sem: make(chan struct{}, size), log: log}
+}
+
+// Go menjalankan fn di goroutine baru jika masih ada slot, tanpa menunggu. Panic di fn dicatat, bukan
+// menghentikan proses.
+func (p *Pool) Go(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		p.log.Warn("worker pool is shut down, dropping job")
+		return
+	}
+	select {
+	case p.sem <- struct{}{}:
+	default:
+		p.log.Warn("worker pool is full, dropping job")
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.sem }()
+		defer func() {
+			if r := recover(); r != nil {
+				p.log.Errorf("background job panicked: %v", r)
+			}
+		}()
+		fn()
+	}()
+}
+
+// Shutdown menolak pekerjaan baru lalu menunggu pekerjaan yang sedang berjalan selesai, atau ctx habis.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestPoolLimitsConcurrency(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	p := New(2, log)
+
+	release := make(chan struct{})
+	var ran atomic.Int32
+	for range 3 {
+		p.Go(func() {
+			<-release
+			ran.Add(1)
+		})
+	}
+	if entry := hook.LastEntry(); entry == nil || entry.Level != logrus.WarnLevel || entry.Message != "worker pool is full, dropping job" {
+		t.Errorf("log entry for the third job = %+v", entry)
+	}
+
+	close(release)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if got := ran.Load(); got != 2 {
+		t.Errorf("%d jobs ran, want 2", got)
+	}
+
+	// slot yang sudah selesai tidak dipakai lagi setelah Shutdown
+	p.Go(func() { ran.Add(1) })
+	if got := ran.Load(); got != 2 || hook.LastEntry().Message != "worker pool is shut down, dropping job" {
+		t.Errorf("job after Shutdown: ran %d, last log %q", got, hook.LastEntry().Message)
+	}
+}
+
+func TestShutdownWaitsForRunningJobs(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	p := New(4, log)
+
+	var done atomic.Bool
+	p.Go(func() {
+		time.Sleep(50 * time.Millisecond)
+		done.Store(true)
+	})
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if !done.Load() {
+		t.Error("Shutdown returned before the running job finished")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	p := New(1, log)
+
+	release := make(chan struct{})
+	defer close(release)
+	p.Go(func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown with a stuck job: got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestPanicIsRecovered(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	p := New(1, log)
+
+	p.Go(func() { panic("boom") })
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if entry := hook.LastEntry(); entry == nil || entry.Level != logrus.ErrorLevel || entry.Message != "background job panicked: boom" {
+		t.Errorf("log entry = %+v", entry)
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "user-service/db/sqlc"
+	"user-service/dto"
+	"user-service/pkg/helper"
+	"user-service/pkg/mail"
+	"user-service/pkg/token"
+	"user-service/pkg/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrPasswordTooLong dikembalikan ResetPassword untuk password lebih dari 72 byte, batas bcrypt.
+	ErrPasswordTooLong = errors.New("password must be at most 72 bytes")
+	// ErrSessionRevoked dikembalikan CheckSession untuk token yang dibuat sebelum password terakhir di-reset,
+	// atau milik user yang sudah dihapus.
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
+// forgotPasswordTimeout membatasi pencarian user dan pengiriman email reset yang berjalan di background.
+const forgotPasswordTimeout = 30 * time.Second
+
+type AuthService interface {
+	// ForgotPassword mengirim link reset password ke email jika terdaftar. Pencarian user dan pengiriman email
+	// berjalan di background dan hasilnya hanya masuk log, jadi pemanggil tidak bisa membedakan email yang
+	// terdaftar dari yang tidak, termasuk dari lamanya panggilan.
+	ForgotPassword(ctx context.Context, email string)
+	// ResetPassword memakai token dari link reset, mengganti password dan mencabut semua access token user.
+	// Error ErrInvalidToken jika token tidak bisa dipakai, ErrPasswordTooLong.
+	ResetPassword(ctx context.Context, resetToken, password string) (dto.UserResponse, error)
+	// SessionVersion mengembalikan versi sesi user untuk access token baru, pgx.ErrNoRows jika user tidak ada.
+	SessionVersion(ctx context.Context, id uuid.UUID) (int32, error)
+	// CheckSession mengembalikan ErrSessionRevoked jika access token dengan claims sudah dicabut.
+	// Tanda tangan dan masa berlaku token harus sudah dicek token.Verifier.
+	CheckSession(ctx context.Context, claims token.Claims) error
+	// Authenticate memeriksa access token dengan AuthOptions.Verifier lalu CheckSession dan mengembalikan claims-nya.
+	// Error token.ErrInvalid atau ErrSessionRevoked.
+	Authenticate(ctx context.Context, accessToken string) (token.Claims, error)
+}
+
+type AuthOptions struct {
+	// ResetURL: halaman yang menerima ?token=, dipakai untuk link di email
+	ResetURL      string
+	ResetTokenTTL time.Duration
+	// HashCost: cost bcrypt, 0 berarti bcrypt.DefaultCost
+	HashCost int
+	Mailer   mail.Mailer
+	// EmailProviderRules diteruskan ke helper.NormalizeEmail, harus sama dengan UserOptions
+	EmailProviderRules bool
+	// Background menjalankan pekerjaan ForgotPassword di luar request, misalnya worker.Pool.Go yang ditunggu
+	// saat shutdown; nil berarti goroutine baru tanpa batas
+	Background func(fn func())
+	// Verifier memeriksa access token untuk Authenticate; nil berarti semua access token ditolak
+	Verifier *token.Verifier
+}
+
+type authService struct {
+	store db.Store
+	opts  AuthOptions
+}
+
+func NewAuthService(store db.Store, opts AuthOptions) AuthService {
+	if opts.ResetTokenTTL <= 0 {
+		opts.ResetTokenTTL = time.Hour
+	}
+	if opts.HashCost == 0 {
+		opts.HashCost = bcrypt.DefaultCost
+	}
+	if opts.Background == nil {
+		opts.Background = func(fn func()) { go fn() }
+	}
+	return &authService{store: store, opts: opts}
+}
+
+func (as *authService) ForgotPassword(ctx context.Context, email string) {
+	// context request dibatalkan begitu response terkirim, tetapi field log (request_id) tetap ikut
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), forgotPasswordTimeout)
+	as.opts.Background(func() {
+		defer cancel()
+		if err := as.sendResetLink(ctx, email); err != nil {
+			log.FromContext(ctx).Errorf("failed to send password reset link: %v", err)
+		}
+	})
+}
+
+func (as *authService) sendResetLink(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.ForgotPassword")
+	defer span.End()
+
+	user, err := as.store.GetUserByEmail(ctx, helper.NormalizeEmail(email, as.opts.EmailProviderRules))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.FromContext(ctx).Info("password reset requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	// hanya link terakhir yang berlaku
+	if _, err := as.store.DeleteUserTokens(ctx, db.DeleteUserTokensParams{UserID: user.ID, Purpose: db.TokenPurposeResetPassword}); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	resetToken, hash, err := newToken()
+	if err != nil {
+		return err
+	}
+	_, err = as.store.CreateUserToken(ctx, db.CreateUserTokenParams{
+		UserID:    user.ID,
+		Purpose:   db.TokenPurposeResetPassword,
+		TokenHash: hash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(as.opts.ResetTokenTTL), Valid: true},
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return fmt.Errorf("create reset token: %w", err)
+	}
+
+	link, err := tokenLink(as.opts.ResetURL, resetToken)
+	if err != nil {
+		return err
+	}
+	err = as.opts.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your account. To choose a new password, open this link:\n\n%s\n\n"+
+			"The link expires in %s and can only be used once. Resetting your password signs you out everywhere. "+
+			"If you did not ask for this, you can ignore this email; your password stays the same.\n",
+			displayName(user), link, humanDuration(as.opts.ResetTokenTTL)),
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (as *authService) ResetPassword(ctx context.Context, resetToken, password string) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer span.End()
+
+	// bcrypt hanya membaca 72 byte pertama, sisanya ditolak daripada diam-diam diabaikan
+	if len(password) > 72 {
+		return dto.UserResponse{}, ErrPasswordTooLong
+	}
+
+	// bcrypt mahal, jadi token dicek dulu supaya token palsu tidak bisa dipakai untuk membebani CPU.
+	// Yang menentukan tetap ResetPassword, yang memakai token dalam transaksi.
+	tokenHash := hashToken(resetToken)
+	_, err := as.store.GetUserToken(ctx, db.GetUserTokenParams{TokenHash: tokenHash, Purpose: db.TokenPurposeResetPassword})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserResponse{}, ErrInvalidToken
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.UserResponse{}, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), as.opts.HashCost)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.UserResponse{}, err
+	}
+
+	user, err := as.store.ResetPassword(ctx, tokenHash, string(hash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserResponse{}, ErrInvalidToken
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to reset password: %v", err)
+		return dto.UserResponse{}, err
+	}
+
+	// pemberitahuan hanya informasi, password sudah diganti
+	err = as.opts.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"The password of your account was just changed and every session was signed out.\n\n"+
+			"If you did not do this, request a new password reset link right away.\n",
+			displayName(user)),
+	})
+	if err != nil {
+		log.FromContext(ctx).Errorf("failed to send password change notice: %v", err)
+	}
+	return toUserResponse(user), nil
+}
+
+func (as *authService) SessionVersion(ctx context.Context, id uuid.UUID) (int32, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.SessionVersion")
+	defer span.End()
+
+	user, err := as.store.GetUserByID(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+	return user.SessionVersion, nil
+}
+
+func (as *authService) CheckSession(ctx context.Context, claims token.Claims) error {
+	ctx, span := tracing.Start(ctx, "AuthService.CheckSession")
+	defer span.End()
+
+	id, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return ErrSessionRevoked
+	}
+	version, err := as.SessionVersion(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if claims.SessionVersion != version {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+func (as *authService) Authenticate(ctx context.Context, accessToken string) (token.Claims, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Authenticate")
+	defer span.End()
+
+	if as.opts.Verifier == nil {
+		return token.Claims{}, fmt.Errorf("%w: no public key configured", token.ErrInvalid)
+	}
+	claims, err := as.opts.Verifier.Verify(accessToken)
+	if err != nil {
+		return token.Claims{}, err
+	}
+	if err := as.CheckSession(ctx, claims); err != nil {
+		if !errors.Is(err, ErrSessionRevoked) {
+			tracing.RecordError(span, err)
+		}
+		return token.Claims{}, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	db "user-service/db/sqlc"
+	"user-service/dto"
+	"user-service/pkg/avatar"
+	"user-service/pkg/blob"
+	"user-service/pkg/fetch"
+	"user-service/pkg/helper"
+	"user-service/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+type AvatarService interface {
+	// UploadAvatar memproses gambar menjadi thumbnail, menyimpannya ke blob store dan mengganti avatar_url.
+	// Error avatar.ErrUnsupportedType, avatar.ErrInvalidImage atau avatar.ErrTooLarge untuk gambar yang
+	// ditolak, pgx.ErrNoRows jika user tidak ada.
+	UploadAvatar(ctx context.Context, id uuid.UUID, data []byte) (dto.AvatarResponse, error)
+	// MaxBytes adalah ukuran file maksimal yang diterima, dipakai handler untuk membatasi body.
+	MaxBytes() int64
+
+	// ImportsURLs bernilai true jika avatar_url dari request harus diunduh lewat FetchAvatar
+	// dan disimpan dengan SaveAvatar, bukan disimpan apa adanya.
+	ImportsURLs() bool
+	// FetchAvatar mengunduh dan memproses gambar dari URL publik. Error berisi fetch.ErrBlocked,
+	// fetch.ErrTooLarge atau error dari avatar.Process.
+	FetchAvatar(ctx context.Context, rawURL string) (*avatar.Result, error)
+	// SaveAvatar menyimpan thumbnail hasil avatar.Process dan mengganti avatar_url user.
+	SaveAvatar(ctx context.Context, id uuid.UUID, result *avatar.Result) (dto.AvatarResponse, error)
+}
+
+type AvatarOptions struct {
+	Sizes    []int
+	MaxBytes int64
+	// Fetcher untuk mengunduh avatar_url, nil berarti avatar_url disimpan apa adanya
+	Fetcher *fetch.Fetcher
+}
+
+type avatarService struct {
+	store db.Store
+	blobs blob.Store
+	opts  AvatarOptions
+}
+
+func NewAvatarService(store db.Store, blobs blob.Store, opts AvatarOptions) AvatarService {
+	if len(opts.Sizes) == 0 {
+		opts.Sizes = avatar.DefaultSizes
+	}
+	opts.Sizes = slices.Sorted(slices.Values(opts.Sizes))
+	return &avatarService{store: store, blobs: blobs, opts: opts}
+}
+
+func (as *avatarService) MaxBytes() int64 {
+	return as.opts.MaxBytes
+}
+
+func (as *avatarService) ImportsURLs() bool {
+	return as.opts.Fetcher != nil
+}
+
+// avatarExts adalah semua ekstensi yang bisa dihasilkan avatar.Process.
+var avatarExts = []string{"jpg", "png"}
+
+func avatarKey(id uuid.UUID, size int, ext string) string {
+	return fmt.Sprintf("avatars/%s/%d.%s", id, size, ext)
+}
+
+func (as *avatarService) UploadAvatar(ctx context.Context, id uuid.UUID, data []byte) (dto.AvatarResponse, error) {
+	ctx, span := tracing.Start(ctx, "AvatarService.UploadAvatar")
+	defer span.End()
+
+	// cek user dulu supaya gambar tidak diproses untuk user yang tidak ada
+	if _, err := as.store.GetUserByID(ctx, id); err != nil {
+		tracing.RecordError(span, err)
+		return dto.AvatarResponse{}, err
+	}
+
+	result, err := avatar.Process(data, as.opts.Sizes)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.AvatarResponse{}, err
+	}
+	return as.SaveAvatar(ctx, id, result)
+}
+
+func (as *avatarService) FetchAvatar(ctx context.Context, rawURL string) (*avatar.Result, error) {
+	ctx, span := tracing.Start(ctx, "AvatarService.FetchAvatar")
+	defer span.End()
+
+	if as.opts.Fetcher == nil {
+		return nil, errors.New("avatar import is disabled")
+	}
+	data, err := as.opts.Fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Warnf("failed to fetch avatar: %v", err)
+		return nil, err
+	}
+	result, err := avatar.Process(data, as.opts.Sizes)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return result, nil
+}
+
+func (as *avatarService) SaveAvatar(ctx context.Context, id uuid.UUID, result *avatar.Result) (dto.AvatarResponse, error) {
+	ctx, span := tracing.Start(ctx, "AvatarService.SaveAvatar")
+	defer span.End()
+
+	var resp dto.AvatarResponse
+	for _, th := range result.Thumbnails {
+		key := avatarKey(id, th.Size, result.Ext)
+		if err := as.blobs.Put(ctx, key, th.Data, result.ContentType); err != nil {
+			tracing.RecordError(span, err)
+			log.FromContext(ctx).Errorf("failed to store avatar %s: %v", key, err)
+			return dto.AvatarResponse{}, err
+		}
+		// key tetap per ukuran, version di query string membuat cache lama tidak terpakai
+		resp.Thumbnails = append(resp.Thumbnails, dto.AvatarThumbnail{
+			Size: th.Size,
+			URL:  as.blobs.URL(key) + "?v=" + result.Version,
+		})
+
+		// avatar sebelumnya mungkin disimpan dengan format lain
+		for _, ext := range avatarExts {
+			if ext == result.Ext {
+				continue
+			}
+			if err := as.blobs.Delete(ctx, avatarKey(id, th.Size, ext)); err != nil {
+				log.FromContext(ctx).Warnf("failed to delete old avatar: %v", err)
+			}
+		}
+	}
+
+	largest := resp.Thumbnails[len(resp.Thumbnails)-1].URL
+	user, err := as.store.UpdateUserAvatar(ctx, db.UpdateUserAvatarParams{ID: id, AvatarUrl: helper.StringToPGText(largest)})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to update avatar_url: %v", err)
+		return dto.AvatarResponse{}, err
+	}
+	resp.User = toUserResponse(user)
+
+	return resp, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "user-service/db/sqlc"
+	"user-service/dto"
+	"user-service/pkg/helper"
+	"user-service/pkg/mail"
+	"user-service/pkg/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrSameEmail dikembalikan RequestEmailChange jika alamat baru sama dengan alamat sekarang.
+var ErrSameEmail = errors.New("new email address is the same as the current one")
+
+type EmailChangeService interface {
+	// RequestEmailChange menyimpan permintaan ganti email (permintaan lama yang belum dikonfirmasi dibatalkan),
+	// mengirim link konfirmasi ke alamat baru dan pemberitahuan ke alamat lama. users.email belum berubah.
+	// Error pgx.ErrNoRows jika user tidak ada, ErrSameEmail atau ErrEmailTaken.
+	RequestEmailChange(ctx context.Context, id uuid.UUID, newEmail string) (dto.EmailChangeResponse, error)
+	// ConfirmEmailChange memakai token dari email ke alamat baru, mengganti users.email, lalu mengirim
+	// link pembatalan ke alamat lama. Error ErrInvalidToken atau ErrEmailTaken.
+	ConfirmEmailChange(ctx context.Context, token string) (dto.UserResponse, error)
+	// RevertEmailChange memakai link pembatalan dan mengembalikan users.email ke alamat lama.
+	// Error ErrInvalidToken atau ErrEmailTaken.
+	RevertEmailChange(ctx context.Context, token string) (dto.UserResponse, error)
+}
+
+type EmailChangeOptions struct {
+	// ConfirmURL dan RevertURL: halaman yang menerima ?token=, dipakai untuk link di email
+	ConfirmURL string
+	RevertURL  string
+	TokenTTL   time.Duration
+	// RevertTTL: masa berlaku link pembatalan, dihitung dari saat perubahan dikonfirmasi
+	RevertTTL time.Duration
+	Mailer    mail.Mailer
+	// EmailProviderRules diteruskan ke helper.NormalizeEmail, harus sama dengan UserOptions
+	EmailProviderRules bool
+}
+
+type emailChangeService struct {
+	store db.Store
+	opts  EmailChangeOptions
+}
+
+func NewEmailChangeService(store db.Store, opts EmailChangeOptions) EmailChangeService {
+	if opts.TokenTTL <= 0 {
+		opts.TokenTTL = 24 * time.Hour
+	}
+	if opts.RevertTTL <= 0 {
+		opts.RevertTTL = 7 * 24 * time.Hour
+	}
+	return &emailChangeService{store: store, opts: opts}
+}
+
+func (es *emailChangeService) RequestEmailChange(ctx context.Context, id uuid.UUID, newEmail string) (dto.EmailChangeResponse, error) {
+	ctx, span := tracing.Start(ctx, "EmailChangeService.RequestEmailChange")
+	defer span.End()
+
+	user, err := es.store.GetUserByID(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.EmailChangeResponse{}, err
+	}
+	newEmail = helper.NormalizeEmail(newEmail, es.opts.EmailProviderRules)
+	if newEmail == user.Email {
+		return dto.EmailChangeResponse{}, ErrSameEmail
+	}
+	// hanya cek awal; yang menentukan tetap unique index saat perubahan dikonfirmasi.
+	// Alamat yang email kanoniknya sama dengan alamat sendiri boleh dipakai.
+	if taken, err := es.store.GetUserByEmail(ctx, newEmail); err == nil && taken.ID != id {
+		return dto.EmailChangeResponse{}, ErrEmailTaken
+	} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		tracing.RecordError(span, err)
+		return dto.EmailChangeResponse{}, err
+	}
+
+	if _, err := es.store.DeletePendingEmailChanges(ctx, id); err != nil {
+		tracing.RecordError(span, err)
+		return dto.EmailChangeResponse{}, err
+	}
+	token, hash, err := newToken()
+	if err != nil {
+		return dto.EmailChangeResponse{}, err
+	}
+	change, err := es.store.CreateEmailChange(ctx, db.CreateEmailChangeParams{
+		UserID:           id,
+		OldEmail:         user.Email,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: hash,
+		ExpiresAt:        pgtype.Timestamptz{Time: time.Now().Add(es.opts.TokenTTL), Valid: true},
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to create email change: %v", err)
+		return dto.EmailChangeResponse{}, err
+	}
+
+	link, err := tokenLink(es.opts.ConfirmURL, token)
+	if err != nil {
+		return dto.EmailChangeResponse{}, err
+	}
+	err = es.opts.Mailer.Send(ctx, mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm that you want to use %s as the email address of your account by opening this link:\n\n%s\n\n"+
+			"The link expires in %s and can only be used once. Until then your account keeps using %s. "+
+			"If you did not request this change, you can ignore this email.\n",
+			displayName(user), newEmail, link, humanDuration(es.opts.TokenTTL), user.Email),
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to send email change confirmation: %v", err)
+		return dto.EmailChangeResponse{}, err
+	}
+
+	// pemberitahuan ke alamat lama hanya informasi, kegagalannya tidak membatalkan permintaan
+	err = es.opts.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your email address is about to change",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to change the email address of your account to %s. "+
+			"Nothing changes until the new address is confirmed; if it is, we will send you a link to undo the change.\n\n"+
+			"If you did not request this, no action is needed now, but keep an eye on this mailbox.\n",
+			displayName(user), newEmail),
+	})
+	if err != nil {
+		log.FromContext(ctx).Errorf("failed to send email change notice: %v", err)
+	}
+
+	return dto.EmailChangeResponse{
+		NewEmail:  change.NewEmail,
+		ExpiresAt: change.ExpiresAt.Time,
+	}, nil
+}
+
+func (es *emailChangeService) ConfirmEmailChange(ctx context.Context, token string) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "EmailChangeService.ConfirmEmailChange")
+	defer span.End()
+
+	revertToken, revertHash, err := newToken()
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+	result, err := es.store.ApplyEmailChange(ctx, db.ConfirmEmailChangeParams{
+		ConfirmTokenHash: hashToken(token),
+		RevertTokenHash:  revertHash,
+		RevertExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(es.opts.RevertTTL), Valid: true},
+	})
+	if err != nil {
+		return dto.UserResponse{}, es.txError(ctx, span, "confirm email change", err)
+	}
+
+	link, err := tokenLink(es.opts.RevertURL, revertToken)
+	if err == nil {
+		err = es.opts.Mailer.Send(ctx, mail.Message{
+			To:      result.Change.OldEmail,
+			Subject: "Your email address was changed",
+			Text: fmt.Sprintf("Hi %s,\n\n"+
+				"The email address of your account was changed from %s to %s.\n\n"+
+				"If you did not make this change, open this link to switch back to %s:\n\n%s\n\n"+
+				"The link expires in %s and can only be used once.\n",
+				displayName(result.User), result.Change.OldEmail, result.Change.NewEmail, result.Change.OldEmail,
+				link, humanDuration(es.opts.RevertTTL)),
+		})
+	}
+	if err != nil {
+		// email sudah diganti dan tidak dibatalkan; yang hilang hanya link pembatalan
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to send email change revert link: %v", err)
+	}
+	return toUserResponse(result.User), nil
+}
+
+func (es *emailChangeService) RevertEmailChange(ctx context.Context, token string) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "EmailChangeService.RevertEmailChange")
+	defer span.End()
+
+	result, err := es.store.UndoEmailChange(ctx, hashToken(token))
+	if err != nil {
+		return dto.UserResponse{}, es.txError(ctx, span, "revert email change", err)
+	}
+	return toUserResponse(result.User), nil
+}
+
+// txError menerjemahkan error ApplyEmailChange dan UndoEmailChange ke error service.
+func (es *emailChangeService) txError(ctx context.Context, span trace.Span, action string, err error) error {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrInvalidToken
+	case db.IsUniqueViolation(err):
+		return ErrEmailTaken
+	}
+	tracing.RecordError(span, err)
+	log.FromContext(ctx).Errorf("failed to %s: %v", action, err)
+	return err
+}
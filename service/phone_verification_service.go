@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	db "user-service/db/sqlc"
+	"user-service/dto"
+	"user-service/pkg/phone"
+	"user-service/pkg/sms"
+	"user-service/pkg/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrPhoneVerificationDisabled dikembalikan jika tidak ada SMS sender (SMS_PROVIDER=none).
+	ErrPhoneVerificationDisabled = errors.New("phone verification is disabled")
+	// ErrNoPhone dikembalikan SendOTP untuk user tanpa nomor telepon.
+	ErrNoPhone = errors.New("user has no phone number")
+	// ErrPhoneAlreadyVerified dikembalikan SendOTP jika nomor telepon user sudah diverifikasi.
+	ErrPhoneAlreadyVerified = errors.New("phone number is already verified")
+	// ErrOTPTooSoon dikembalikan SendOTP jika kode sebelumnya dikirim kurang dari ResendInterval yang lalu.
+	ErrOTPTooSoon = errors.New("a code was sent recently, wait before requesting a new one")
+	// ErrOTPLimit dikembalikan SendOTP jika nomor telepon sudah dikirimi MaxSends kode dalam SendWindow terakhir.
+	ErrOTPLimit = errors.New("too many codes were sent to this phone number, try again later")
+	// ErrInvalidOTP dikembalikan untuk kode yang salah, kedaluwarsa, sudah dipakai atau sudah terlalu sering ditebak.
+	ErrInvalidOTP = errors.New("code is invalid or has expired")
+)
+
+type PhoneVerificationService interface {
+	// SendOTP membuat kode baru (kode lama yang belum dipakai dibatalkan) dan mengirimnya lewat SMS ke nomor user.
+	// Error pgx.ErrNoRows jika user tidak ada, ErrNoPhone, ErrInvalidPhone, ErrPhoneAlreadyVerified,
+	// atau ErrOTPTooSoon dan ErrOTPLimit bersama response berisi ResendAt.
+	SendOTP(ctx context.Context, id uuid.UUID) (dto.PhoneOTPResponse, error)
+	// VerifyOTP mencocokkan code dengan kode terakhir user dan menandai nomor telepon terverifikasi.
+	// Setiap panggilan menghabiskan satu percobaan; error ErrInvalidOTP jika kode tidak bisa dipakai.
+	VerifyOTP(ctx context.Context, id uuid.UUID, code string) (dto.UserResponse, error)
+}
+
+type PhoneVerificationOptions struct {
+	// Sender nil berarti verifikasi nomor telepon dimatikan
+	Sender sms.Sender
+	// Region: region untuk nomor tanpa kode negara, harus sama dengan UserOptions
+	Region string
+	TTL    time.Duration
+	// MaxAttempts: jumlah tebakan per kode
+	MaxAttempts    int
+	ResendInterval time.Duration
+	// MaxSends: jumlah kode yang boleh dikirim ke satu nomor telepon, dari user mana pun, dalam SendWindow
+	MaxSends   int
+	SendWindow time.Duration
+}
+
+type phoneVerificationService struct {
+	store db.Store
+	opts  PhoneVerificationOptions
+}
+
+func NewPhoneVerificationService(store db.Store, opts PhoneVerificationOptions) PhoneVerificationService {
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.MaxSends <= 0 {
+		opts.MaxSends = 5
+	}
+	if opts.SendWindow <= 0 {
+		opts.SendWindow = 24 * time.Hour
+	}
+	return &phoneVerificationService{store: store, opts: opts}
+}
+
+func (ps *phoneVerificationService) SendOTP(ctx context.Context, id uuid.UUID) (dto.PhoneOTPResponse, error) {
+	ctx, span := tracing.Start(ctx, "PhoneVerificationService.SendOTP")
+	defer span.End()
+
+	if ps.opts.Sender == nil {
+		return dto.PhoneOTPResponse{}, ErrPhoneVerificationDisabled
+	}
+	user, err := ps.store.GetUserByID(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.PhoneOTPResponse{}, err
+	}
+	if !user.PhoneNumber.Valid || user.PhoneNumber.String == "" {
+		return dto.PhoneOTPResponse{}, ErrNoPhone
+	}
+	if user.PhoneVerifiedAt.Valid {
+		return dto.PhoneOTPResponse{}, ErrPhoneAlreadyVerified
+	}
+	// nomor yang disimpan sebelum ada validasi belum tentu E.164
+	to, err := phone.Parse(user.PhoneNumber.String, ps.opts.Region)
+	if err != nil {
+		return dto.PhoneOTPResponse{}, fmt.Errorf("%w: %v", ErrInvalidPhone, err)
+	}
+
+	now := time.Now()
+	pending, err := ps.store.GetPendingPhoneOTP(ctx, id)
+	switch {
+	case err == nil:
+		// created_at diisi jam database, jadi jeda dihitung dari expires_at yang diisi service
+		resendAt := pending.ExpiresAt.Time.Add(ps.opts.ResendInterval - ps.opts.TTL)
+		if now.Before(resendAt) {
+			return dto.PhoneOTPResponse{PhoneNumber: to, ExpiresAt: pending.ExpiresAt.Time, ResendAt: resendAt}, ErrOTPTooSoon
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		tracing.RecordError(span, err)
+		return dto.PhoneOTPResponse{}, err
+	}
+
+	// batas dihitung per nomor E.164, jadi membuat user lain dengan nomor yang sama tidak menambah jatah SMS
+	sent, err := ps.store.ListPhoneOTPSends(ctx, db.ListPhoneOTPSendsParams{
+		PhoneNumber: to,
+		Since:       pgtype.Timestamptz{Time: now.Add(-ps.opts.SendWindow), Valid: true},
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.PhoneOTPResponse{}, err
+	}
+	if len(sent) >= ps.opts.MaxSends {
+		resendAt := sent[len(sent)-ps.opts.MaxSends].Time.Add(ps.opts.SendWindow)
+		return dto.PhoneOTPResponse{PhoneNumber: to, ResendAt: resendAt}, ErrOTPLimit
+	}
+
+	if _, err := ps.store.DeletePhoneOTPs(ctx, id); err != nil {
+		tracing.RecordError(span, err)
+		return dto.PhoneOTPResponse{}, err
+	}
+	code, err := newOTP()
+	if err != nil {
+		return dto.PhoneOTPResponse{}, err
+	}
+	otp, err := ps.store.CreatePhoneOTP(ctx, db.CreatePhoneOTPParams{
+		UserID:      id,
+		PhoneNumber: user.PhoneNumber.String,
+		CodeHash:    hashOTP(id, user.PhoneNumber.String, code),
+		ExpiresAt:   pgtype.Timestamptz{Time: now.Add(ps.opts.TTL), Valid: true},
+	})
+	// request lain untuk user yang sama membuat kode lebih dulu
+	if db.IsUniqueViolation(err) {
+		return dto.PhoneOTPResponse{PhoneNumber: to, ExpiresAt: now.Add(ps.opts.TTL), ResendAt: now.Add(ps.opts.ResendInterval)}, ErrOTPTooSoon
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to create phone otp: %v", err)
+		return dto.PhoneOTPResponse{}, err
+	}
+
+	// dicatat sebelum SMS dikirim, jadi kiriman yang gagal tetap dihitung dan batasnya tidak bisa dilewati
+	err = ps.store.CreatePhoneOTPSend(ctx, db.CreatePhoneOTPSendParams{
+		UserID:      id,
+		PhoneNumber: to,
+		SentAt:      pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to record phone otp send: %v", err)
+		if _, err := ps.store.DeletePhoneOTPs(ctx, id); err != nil {
+			log.FromContext(ctx).Warnf("failed to delete unsent phone otp: %v", err)
+		}
+		return dto.PhoneOTPResponse{}, err
+	}
+
+	err = ps.opts.Sender.Send(ctx, sms.Message{
+		To:   to,
+		Text: fmt.Sprintf("%s is your verification code. It expires in %s. Do not share it with anyone.", code, humanDuration(ps.opts.TTL)),
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to send phone otp: %v", err)
+		// kode yang tidak terkirim tidak boleh menahan permintaan berikutnya
+		if _, err := ps.store.DeletePhoneOTPs(ctx, id); err != nil {
+			log.FromContext(ctx).Warnf("failed to delete unsent phone otp: %v", err)
+		}
+		return dto.PhoneOTPResponse{}, err
+	}
+
+	return dto.PhoneOTPResponse{
+		PhoneNumber: to,
+		ExpiresAt:   otp.ExpiresAt.Time,
+		ResendAt:    now.Add(ps.opts.ResendInterval),
+	}, nil
+}
+
+func (ps *phoneVerificationService) VerifyOTP(ctx context.Context, id uuid.UUID, code string) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "PhoneVerificationService.VerifyOTP")
+	defer span.End()
+
+	if ps.opts.Sender == nil {
+		return dto.UserResponse{}, ErrPhoneVerificationDisabled
+	}
+	// percobaan dihitung sebelum kode dicocokkan, jadi tebakan paralel tetap terbatas MaxAttempts
+	otp, err := ps.store.ClaimPhoneOTPAttempt(ctx, db.ClaimPhoneOTPAttemptParams{
+		UserID:      id,
+		MaxAttempts: int32(ps.opts.MaxAttempts),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserResponse{}, ErrInvalidOTP
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.UserResponse{}, err
+	}
+	if subtle.ConstantTimeCompare(otp.CodeHash, hashOTP(id, otp.PhoneNumber, code)) != 1 {
+		return dto.UserResponse{}, ErrInvalidOTP
+	}
+
+	user, err := ps.store.VerifyPhone(ctx, otp.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserResponse{}, ErrInvalidOTP
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to verify phone: %v", err)
+		return dto.UserResponse{}, err
+	}
+	return toUserResponse(user), nil
+}
+
+// newOTP membuat kode 6 digit dari crypto/rand.
+func newOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashOTP mengikat kode ke user dan nomor telepon, jadi hash di phone_otps tidak bisa dipakai untuk nomor lain.
+// Ruang kode kecil, yang membatasi tebakan adalah MaxAttempts, bukan hash ini.
+func hashOTP(userID uuid.UUID, phoneNumber, code string) []byte {
+	sum := sha256.Sum256([]byte(userID.String() + "\x00" + phoneNumber + "\x00" + code))
+	return sum[:]
+}
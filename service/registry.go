@@ -1,21 +1,63 @@
 package service
 
-import db "user-service/db/sqlc"
+import (
+	db "user-service/db/sqlc"
+	"user-service/pkg/blob"
+)
 
 type ServiceRegistry interface {
 	UserService() UserService
+	AvatarService() AvatarService
+	VerificationService() VerificationService
+	EmailChangeService() EmailChangeService
+	PhoneVerificationService() PhoneVerificationService
+	AuthService() AuthService
 }
 
 type serviceRegistry struct {
-	store db.Store
+	store  db.Store
+	blobs  blob.Store
+	users  UserOptions
+	avatar AvatarOptions
+	verify VerificationOptions
+	change EmailChangeOptions
+	phone  PhoneVerificationOptions
+	auth   AuthOptions
 }
 
-func NewServiceRegistry(store db.Store) ServiceRegistry {
+func NewServiceRegistry(store db.Store, blobs blob.Store, users UserOptions, avatar AvatarOptions, verify VerificationOptions, change EmailChangeOptions, phone PhoneVerificationOptions, auth AuthOptions) ServiceRegistry {
 	return &serviceRegistry{
-		store: store,
+		store:  store,
+		blobs:  blobs,
+		users:  users,
+		avatar: avatar,
+		verify: verify,
+		change: change,
+		phone:  phone,
+		auth:   auth,
 	}
 }
 
 func (sr *serviceRegistry) UserService() UserService {
-	return NewUserService(sr.store)
+	return NewUserService(sr.store, sr.users)
+}
+
+func (sr *serviceRegistry) AvatarService() AvatarService {
+	return NewAvatarService(sr.store, sr.blobs, sr.avatar)
+}
+
+func (sr *serviceRegistry) VerificationService() VerificationService {
+	return NewVerificationService(sr.store, sr.verify)
+}
+
+func (sr *serviceRegistry) EmailChangeService() EmailChangeService {
+	return NewEmailChangeService(sr.store, sr.change)
+}
+
+func (sr *serviceRegistry) PhoneVerificationService() PhoneVerificationService {
+	return NewPhoneVerificationService(sr.store, sr.phone)
+}
+
+func (sr *serviceRegistry) AuthService() AuthService {
+	return NewAuthService(sr.store, sr.auth)
 }
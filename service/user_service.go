@@ -2,39 +2,83 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"user-service/constants"
 	db "user-service/db/sqlc"
 	"user-service/dto"
 	logger "user-service/pkg"
 	"user-service/pkg/helper"
+	"user-service/pkg/metrics"
+	"user-service/pkg/phone"
+	"user-service/pkg/tracing"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var log = logger.Named("service")
+
+var (
+	// ErrEmailTaken dikembalikan jika alamat email sudah dipakai user lain, dibandingkan lewat helper.CanonicalEmail.
+	ErrEmailTaken = errors.New("email address is already in use")
+	// ErrInvalidPhone dikembalikan jika nomor telepon tidak bisa dinormalkan ke E.164.
+	ErrInvalidPhone = errors.New("phone number is not valid")
 )
 
 type UserService interface {
 	CreateUser(ctx context.Context, req dto.CreateUserRequest) (dto.UserResponse, error)
 	ListUsers(ctx context.Context, arg db.ListUsersParams) ([]dto.UserResponse, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (dto.UserResponse, error)
+	GetUserByEmail(ctx context.Context, email string) (dto.UserResponse, error)
 	GetUserWithMetadata(ctx context.Context, id uuid.UUID) (db.GetUserWithMetadataRow, error)
+	UpdateUserRole(ctx context.Context, id uuid.UUID, role string) (dto.UserResponse, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+}
+
+type UserOptions struct {
+	// EmailProviderRules diteruskan ke helper.NormalizeEmail
+	EmailProviderRules bool
+	// PhoneRegion: region untuk nomor telepon tanpa kode negara, diteruskan ke phone.Parse
+	PhoneRegion string
 }
 
 type userService struct {
 	store db.Store
+	opts  UserOptions
 }
 
-func NewUserService(store db.Store) UserService {
+func NewUserService(store db.Store, opts UserOptions) UserService {
 	return &userService{
 		store: store,
+		opts:  opts,
 	}
 }
 
 func (us *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	role := req.Role
+	if role == "" {
+		role = constants.RoleUser
+	}
+
+	phoneNumber := req.PhoneNumber
+	if phoneNumber != "" {
+		var err error
+		if phoneNumber, err = phone.Parse(phoneNumber, us.opts.PhoneRegion); err != nil {
+			return dto.UserResponse{}, fmt.Errorf("%w: %v", ErrInvalidPhone, err)
+		}
+	}
+
 	arg := db.CreateuserWithMetadataParams{
 		CreateUserParams: db.CreateUserParams{
-			Email:       req.Email,
+			Email:       helper.NormalizeEmail(req.Email, us.opts.EmailProviderRules),
 			FullName:    helper.StringToPGTextValid(req.FullName),
-			PhoneNumber: helper.StringToPGText(req.PhoneNumber),
-			Role:        "user",
+			PhoneNumber: helper.StringToPGText(phoneNumber),
+			Role:        role,
 			AvatarUrl:   helper.StringToPGText(req.AvatarURL),
 		},
 		UserMetadata: db.UserMetadata{
@@ -43,95 +87,124 @@ func (us *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest
 	}
 
 	result, err := us.store.CreateUserWithMetadata(ctx, arg)
+	if db.IsUniqueViolation(err) {
+		return dto.UserResponse{}, ErrEmailTaken
+	}
 	if err != nil {
-		logger.Log.Errorf("failed to create user with metadata: %v", err)
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to create user with metadata: %v", err)
 		return dto.UserResponse{}, err
 	}
+	metrics.UsersCreated.Inc()
 
-	response := dto.UserResponse{
-		ID:          result.User.ID,
-		Email:       result.User.Email,
-		FullName:    helper.PGTextToStringOrNil(result.User.FullName),
-		PhoneNumber: helper.PGTextToStringOrNil(result.User.PhoneNumber),
-		Role:        result.User.Role,
-		AvatarUrl:   helper.PGTextToStringOrNil(result.User.AvatarUrl),
-		CreatedAt:   helper.PGTimestamptzToTime(result.User.CreatedAt),
-		UpdatedAt:   helper.PGTimestamptzToTime(result.User.UpdatedAt),
-		DeletedAt:   helper.PGTimestamptzToTimePtr(result.DeletedAt),
-	}
-
-	return response, nil
+	return toUserResponse(result.User), nil
 }
 
 func (us *userService) GetUserByID(ctx context.Context, id uuid.UUID) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
 	result, err := us.store.GetUserByID(ctx, id)
 	if err != nil {
-		logger.Log.Errorf("failed to get user by id: %v", err)
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to get user by id: %v", err)
 		return dto.UserResponse{}, err
 	}
 
-	response := dto.UserResponse{
-		ID:          result.ID,
-		Email:       result.Email,
-		FullName:    helper.PGTextToStringOrNil(result.FullName),
-		PhoneNumber: helper.PGTextToStringOrNil(result.PhoneNumber),
-		Role:        result.Role,
-		AvatarUrl:   helper.PGTextToStringOrNil(result.AvatarUrl),
-		CreatedAt:   helper.PGTimestamptzToTime(result.CreatedAt),
-		UpdatedAt:   helper.PGTimestamptzToTime(result.UpdatedAt),
-		DeletedAt:   helper.PGTimestamptzToTimePtr(result.DeletedAt),
-	}
-
-	return response, nil
+	return toUserResponse(result), nil
 }
 
 func (us *userService) GetUserByEmail(ctx context.Context, email string) (dto.UserResponse, error) {
-	result, err := us.store.GetUserByEmail(ctx, email)
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByEmail")
+	defer span.End()
+
+	result, err := us.store.GetUserByEmail(ctx, helper.NormalizeEmail(email, us.opts.EmailProviderRules))
 	if err != nil {
-		logger.Log.Errorf("failed to get user by email: %v", err)
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to get user by email: %v", err)
 		return dto.UserResponse{}, err
 	}
 
-	response := dto.UserResponse{
-		ID:          result.ID,
-		Email:       result.Email,
-		FullName:    helper.PGTextToStringOrNil(result.FullName),
-		PhoneNumber: helper.PGTextToStringOrNil(result.PhoneNumber),
-		Role:        result.Role,
-		AvatarUrl:   helper.PGTextToStringOrNil(result.AvatarUrl),
-		CreatedAt:   helper.PGTimestamptzToTime(result.CreatedAt),
-		UpdatedAt:   helper.PGTimestamptzToTime(result.UpdatedAt),
-		DeletedAt:   helper.PGTimestamptzToTimePtr(result.DeletedAt),
-	}
-
-	return response, nil
+	return toUserResponse(result), nil
 }
 
 func (us *userService) GetUserWithMetadata(ctx context.Context, id uuid.UUID) (db.GetUserWithMetadataRow, error) {
-	return us.store.GetUserWithMetadata(ctx, id)
+	ctx, span := tracing.Start(ctx, "UserService.GetUserWithMetadata")
+	defer span.End()
+
+	result, err := us.store.GetUserWithMetadata(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+	}
+	return result, err
 }
 
 func (us *userService) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ListUsers")
+	defer span.End()
+
 	result, err := us.store.ListUsers(ctx, arg)
 	if err != nil {
-		logger.Log.Errorf("failed to get list users: %v", err)
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to get list users: %v", err)
 		return nil, err
 	}
 
 	var response []dto.UserResponse
 	for _, item := range result {
-		response = append(response, dto.UserResponse{
-			ID:          item.ID,
-			Email:       item.Email,
-			FullName:    helper.PGTextToStringOrNil(item.FullName),
-			PhoneNumber: helper.PGTextToStringOrNil(item.PhoneNumber),
-			Role:        item.Role,
-			AvatarUrl:   helper.PGTextToStringOrNil(item.AvatarUrl),
-			CreatedAt:   helper.PGTimestamptzToTime(item.CreatedAt),
-			UpdatedAt:   helper.PGTimestamptzToTime(item.UpdatedAt),
-			DeletedAt:   helper.PGTimestamptzToTimePtr(item.DeletedAt),
-		})
+		response = append(response, toUserResponse(item))
 	}
 
 	return response, nil
 }
+
+func (us *userService) UpdateUserRole(ctx context.Context, id uuid.UUID, role string) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserRole")
+	defer span.End()
+
+	result, err := us.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{ID: id, Role: role})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to update user role: %v", err)
+		return dto.UserResponse{}, err
+	}
+
+	return toUserResponse(result), nil
+}
+
+// DeleteUser melakukan soft delete. Mengembalikan pgx.ErrNoRows jika user tidak ada atau sudah dihapus.
+func (us *userService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	rows, err := us.store.SoftDeleteUser(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to delete user: %v", err)
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+	metrics.UsersDeleted.Inc()
+
+	return nil
+}
+
+func toUserResponse(user db.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		FullName:        helper.PGTextToStringOrNil(user.FullName),
+		PhoneNumber:     helper.PGTextToStringOrNil(user.PhoneNumber),
+		Role:            user.Role,
+		AvatarUrl:       helper.PGTextToStringOrNil(user.AvatarUrl),
+		EmailVerified:   user.EmailVerifiedAt.Valid,
+		EmailVerifiedAt: helper.PGTimestamptzToTimePtr(user.EmailVerifiedAt),
+		PhoneVerified:   user.PhoneVerifiedAt.Valid,
+		PhoneVerifiedAt: helper.PGTimestamptzToTimePtr(user.PhoneVerifiedAt),
+		CreatedAt:       helper.PGTimestamptzToTime(user.CreatedAt),
+		UpdatedAt:       helper.PGTimestamptzToTime(user.UpdatedAt),
+		DeletedAt:       helper.PGTimestamptzToTimePtr(user.DeletedAt),
+	}
+}
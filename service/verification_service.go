@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"user-service/constants"
+	db "user-service/db/sqlc"
+	"user-service/dto"
+	"user-service/pkg/mail"
+	"user-service/pkg/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrInvalidToken dikembalikan untuk token yang tidak dikenal, sudah dipakai atau kedaluwarsa.
+	ErrInvalidToken = errors.New("token is invalid or has expired")
+	// ErrEmailNotVerified dikembalikan RequireVerified jika policy required dan email user belum diverifikasi.
+	ErrEmailNotVerified = errors.New("email address is not verified")
+	// ErrAlreadyVerified dikembalikan SendVerification untuk user yang email-nya sudah diverifikasi.
+	ErrAlreadyVerified = errors.New("email address is already verified")
+)
+
+type VerificationService interface {
+	// SendVerification membuat token baru (token lama yang belum dipakai dibatalkan) dan mengirim
+	// link verifikasi ke email user. Tidak melakukan apa-apa jika policy off.
+	SendVerification(ctx context.Context, id uuid.UUID) error
+	// VerifyEmail memakai token dari link verifikasi. Error ErrInvalidToken jika token tidak bisa dipakai.
+	VerifyEmail(ctx context.Context, token string) (dto.UserResponse, error)
+	// MarkVerified menandai email user terverifikasi tanpa token, untuk admin.
+	MarkVerified(ctx context.Context, id uuid.UUID) (dto.UserResponse, error)
+	// RequireVerified mengembalikan ErrEmailNotVerified jika policy required dan user belum verifikasi,
+	// pgx.ErrNoRows jika user tidak ada.
+	RequireVerified(ctx context.Context, id uuid.UUID) error
+}
+
+type VerificationOptions struct {
+	// Policy: constants.VerificationOff, VerificationOptional atau VerificationRequired
+	Policy string
+	// URL halaman yang menerima ?token=, dipakai untuk link di email
+	URL      string
+	TokenTTL time.Duration
+	Mailer   mail.Mailer
+}
+
+type verificationService struct {
+	store db.Store
+	opts  VerificationOptions
+}
+
+func NewVerificationService(store db.Store, opts VerificationOptions) VerificationService {
+	if opts.Policy == "" {
+		opts.Policy = constants.VerificationOptional
+	}
+	if opts.TokenTTL <= 0 {
+		opts.TokenTTL = 24 * time.Hour
+	}
+	return &verificationService{store: store, opts: opts}
+}
+
+func (vs *verificationService) SendVerification(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "VerificationService.SendVerification")
+	defer span.End()
+
+	if vs.opts.Policy == constants.VerificationOff {
+		return nil
+	}
+	user, err := vs.store.GetUserByID(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if user.EmailVerifiedAt.Valid {
+		return ErrAlreadyVerified
+	}
+
+	// hanya link terakhir yang berlaku
+	if _, err := vs.store.DeleteUserTokens(ctx, db.DeleteUserTokensParams{UserID: id, Purpose: db.TokenPurposeVerifyEmail}); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	token, hash, err := newToken()
+	if err != nil {
+		return err
+	}
+	_, err = vs.store.CreateUserToken(ctx, db.CreateUserTokenParams{
+		UserID:    id,
+		Purpose:   db.TokenPurposeVerifyEmail,
+		TokenHash: hash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(vs.opts.TokenTTL), Valid: true},
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to create verification token: %v", err)
+		return err
+	}
+
+	link, err := tokenLink(vs.opts.URL, token)
+	if err != nil {
+		return err
+	}
+	err = vs.opts.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm that %s is your email address by opening this link:\n\n%s\n\n"+
+			"The link expires in %s and can only be used once. "+
+			"If you did not create an account, you can ignore this email.\n",
+			displayName(user), user.Email, link, humanDuration(vs.opts.TokenTTL)),
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to send verification email: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (vs *verificationService) VerifyEmail(ctx context.Context, token string) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "VerificationService.VerifyEmail")
+	defer span.End()
+
+	user, err := vs.store.VerifyEmail(ctx, hashToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserResponse{}, ErrInvalidToken
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.FromContext(ctx).Errorf("failed to verify email: %v", err)
+		return dto.UserResponse{}, err
+	}
+	return toUserResponse(user), nil
+}
+
+func (vs *verificationService) MarkVerified(ctx context.Context, id uuid.UUID) (dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "VerificationService.MarkVerified")
+	defer span.End()
+
+	user, err := vs.store.MarkEmailVerified(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return dto.UserResponse{}, err
+	}
+	if _, err := vs.store.DeleteUserTokens(ctx, db.DeleteUserTokensParams{UserID: id, Purpose: db.TokenPurposeVerifyEmail}); err != nil {
+		log.FromContext(ctx).Warnf("failed to delete verification tokens: %v", err)
+	}
+	return toUserResponse(user), nil
+}
+
+func (vs *verificationService) RequireVerified(ctx context.Context, id uuid.UUID) error {
+	if vs.opts.Policy != constants.VerificationRequired {
+		return nil
+	}
+	user, err := vs.store.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !user.EmailVerifiedAt.Valid {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// displayName adalah sapaan di isi email: nama lengkap user, atau email-nya jika nama kosong.
+func displayName(user db.User) string {
+	if user.FullName.Valid && user.FullName.String != "" {
+		return user.FullName.String
+	}
+	return user.Email
+}
+
+// tokenLink menambahkan ?token= ke base, query yang sudah ada tetap dipertahankan.
+func tokenLink(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}